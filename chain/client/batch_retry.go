@@ -0,0 +1,142 @@
+package client
+
+import (
+	"regexp"
+	"strconv"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/pkg/errors"
+	log "github.com/xlab/suplog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// commitBatch broadcasts the batch in one Tx. If the Tx fails because of its messages,
+// the batch is split to isolate the offending messages, and the healthy remainder is resubmitted.
+// Each isolated message is reported as poisoned and its future is resolved with the error.
+func (c *cosmosClient) commitBatch(key *signingKey, batch []*queuedMsg) {
+	res, err := c.broadcastBatch(key, batch)
+	if !isMsgFailure(res, err) {
+		resolveBatch(batch, res, err)
+		return
+	}
+
+	if err == nil {
+		err = chainErrorOf(res)
+	}
+
+	if len(batch) == 1 {
+		c.reportPoisonedMsg(batch[0], res, err)
+		return
+	}
+
+	if idx, ok := failedMsgIndex(res, err); ok && idx < len(batch) {
+		// the chain told which message has failed, no need to bisect
+		c.reportPoisonedMsg(batch[idx], res, err)
+
+		rest := make([]*queuedMsg, 0, len(batch)-1)
+		rest = append(rest, batch[:idx]...)
+		rest = append(rest, batch[idx+1:]...)
+		c.commitBatch(key, rest)
+		return
+	}
+
+	c.logger.WithFields(log.Fields{
+		"size": len(batch),
+	}).WithError(err).Warningln("msg batch failed, bisecting")
+
+	mid := len(batch) / 2
+	c.commitBatch(key, batch[:mid])
+	c.commitBatch(key, batch[mid:])
+}
+
+func (c *cosmosClient) reportPoisonedMsg(qm *queuedMsg, res *sdk.TxResponse, err error) {
+	c.logger.WithFields(log.Fields{
+		"msg_type": sdk.MsgTypeURL(qm.msg),
+	}).WithError(err).Errorln("poisoned msg isolated from batch")
+
+	if c.opts.PoisonedMsgHandler != nil {
+		c.opts.PoisonedMsgHandler(qm.msg, err)
+	}
+
+	resolveBatch([]*queuedMsg{qm}, res, err)
+}
+
+// txLevelErrors are the ABCI errors that are caused by the Tx as a whole,
+// so splitting its messages won't help.
+var txLevelErrors = []*sdkerrors.Error{
+	sdkerrors.ErrUnauthorized,
+	sdkerrors.ErrOutOfGas,
+	sdkerrors.ErrInsufficientFee,
+	sdkerrors.ErrTxInMempoolCache,
+	sdkerrors.ErrMempoolIsFull,
+	sdkerrors.ErrTxTimeoutHeight,
+	sdkerrors.ErrWrongSequence,
+	sdkerrors.ErrInvalidSequence,
+	sdkerrors.ErrInvalidChainID,
+}
+
+// isMsgFailure checks whether the broadcast outcome indicates a failure that
+// is caused by one or more messages of the Tx, rather than by the Tx itself or transport.
+func isMsgFailure(res *sdk.TxResponse, err error) bool {
+	if err == nil {
+		if res == nil || res.Code == 0 {
+			return false
+		}
+
+		return !chainErrorOf(res).matches(txLevelErrors)
+	}
+
+	if IsTxNotIncluded(err) {
+		return false
+	}
+
+	var chainErr *ChainError
+	if errors.As(err, &chainErr) {
+		// rejected by CheckTx or simulation
+		return !chainErr.matches(txLevelErrors)
+	}
+
+	if res != nil && res.Code != 0 {
+		return isMsgFailure(res, nil)
+	}
+
+	st, ok := status.FromError(errors.Cause(err))
+	if !ok {
+		return false
+	}
+
+	switch st.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled, codes.ResourceExhausted:
+		return false
+	}
+
+	// simulation has failed
+	return true
+}
+
+var msgIndexRx = regexp.MustCompile(`message index: (\d+)`)
+
+// failedMsgIndex extracts the index of the failed message from the ABCI log or error,
+// as reported by the baseapp when a message handler fails.
+func failedMsgIndex(res *sdk.TxResponse, err error) (int, bool) {
+	var errLog string
+	if res != nil && len(res.RawLog) > 0 {
+		errLog = res.RawLog
+	} else if err != nil {
+		errLog = err.Error()
+	}
+
+	m := msgIndexRx.FindStringSubmatch(errLog)
+	if len(m) != 2 {
+		return 0, false
+	}
+
+	idx, convErr := strconv.Atoi(m[1])
+	if convErr != nil {
+		return 0, false
+	}
+
+	return idx, true
+}
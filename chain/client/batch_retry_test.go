@@ -0,0 +1,50 @@
+package client
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsMsgFailure(t *testing.T) {
+	simErr := errors.Wrap(status.Error(codes.Unknown, "failed to execute message; message index: 3: invalid price"), "failed to CalculateGas")
+	if !isMsgFailure(nil, simErr) {
+		t.Fatal("simulation error must be a msg failure")
+	}
+
+	connErr := errors.Wrap(status.Error(codes.Unavailable, "connection refused"), "failed to CalculateGas")
+	if isMsgFailure(nil, connErr) {
+		t.Fatal("transport error must not be a msg failure")
+	}
+
+	outOfGas := &sdk.TxResponse{
+		Code:      sdkerrors.ErrOutOfGas.ABCICode(),
+		Codespace: sdkerrors.ErrOutOfGas.Codespace(),
+	}
+	if isMsgFailure(outOfGas, nil) {
+		t.Fatal("out of gas must not be a msg failure")
+	}
+
+	deliverErr := &sdk.TxResponse{
+		Code:      sdkerrors.ErrInsufficientFunds.ABCICode(),
+		Codespace: sdkerrors.ErrInsufficientFunds.Codespace(),
+		RawLog:    "failed to execute message; message index: 7: insufficient funds",
+	}
+	if !isMsgFailure(deliverErr, nil) {
+		t.Fatal("failed message must be a msg failure")
+	}
+
+	idx, ok := failedMsgIndex(deliverErr, nil)
+	if !ok || idx != 7 {
+		t.Fatalf("expected failed msg index 7, got %d (%v)", idx, ok)
+	}
+
+	idx, ok = failedMsgIndex(nil, simErr)
+	if !ok || idx != 3 {
+		t.Fatalf("expected failed msg index 3, got %d (%v)", idx, ok)
+	}
+}
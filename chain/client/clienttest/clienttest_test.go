@@ -0,0 +1,175 @@
+package clienttest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	chainclient "github.com/InjectiveLabs/sdk-go/chain/client"
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+)
+
+func TestCosmosClient(t *testing.T) {
+	sender := sdk.AccAddress("test_sender_________")
+	c := NewCosmosClient(nil, sender)
+
+	c.PushResponse(&sdk.TxResponse{
+		Codespace: "sdk",
+		Code:      5,
+		RawLog:    "insufficient funds",
+	}, nil)
+
+	msg := &banktypes.MsgSend{
+		FromAddress: sender.String(),
+		ToAddress:   sender.String(),
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("inj", 1)),
+	}
+
+	futures, err := c.QueueBroadcastMsgWithResult(msg, msg)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(futures) != 2 {
+		t.Fatalf("expected 2 futures, got %d", len(futures))
+	}
+
+	if _, err := futures[1].Wait(context.Background()); !errors.Is(err, chainclient.ErrInsufficientFunds) {
+		t.Fatalf("expected insufficient funds, got %v", err)
+	}
+
+	events := sdk.StringEvents{{
+		Type:       "message",
+		Attributes: []sdk.Attribute{sdk.NewAttribute("action", "send")},
+	}}
+	c.PushResponse(&sdk.TxResponse{
+		Height: 1,
+		Logs:   sdk.ABCIMessageLogs{{MsgIndex: 1, Events: events}},
+	}, nil)
+
+	futures, err = c.QueueBroadcastMsgWithResult(msg, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result, err := futures[0].Wait(context.Background()); err != nil || len(result.Events) != 0 {
+		t.Fatalf("expected no events of the first msg, got %v, %v", result, err)
+	} else if result, err := futures[1].Wait(context.Background()); err != nil || len(result.Events) != 1 || result.Events[0].Type != "message" {
+		t.Fatalf("expected events of the second msg from its log, got %v, %v", result, err)
+	}
+
+	res, err := c.SyncBroadcastMsg(msg)
+	if err != nil {
+		t.Fatal(err)
+	} else if res.Code != 0 || res.Height != 1 {
+		t.Fatalf("expected the Tx included at height 1, got code %d at height %d", res.Code, res.Height)
+	}
+
+	if msgs := c.BroadcastedMsgs(); len(msgs) != 5 {
+		t.Fatalf("expected 5 broadcasted msgs, got %d", len(msgs))
+	} else if broadcasts := c.Broadcasts(); broadcasts[0].Mode != BroadcastModeQueue || broadcasts[2].Mode != BroadcastModeSync {
+		t.Fatalf("unexpected broadcast modes %s, %s", broadcasts[0].Mode, broadcasts[2].Mode)
+	}
+
+	// the handler may use the client
+	c.SetBroadcastHandler(func(b *Broadcast) (*sdk.TxResponse, error) {
+		return &sdk.TxResponse{Height: int64(len(c.Broadcasts()))}, nil
+	})
+	if res, err := c.AsyncBroadcastMsg(msg); err != nil || res.Height != 4 {
+		t.Fatalf("expected the handler to see 4 broadcasts, got %v, %v", res, err)
+	}
+
+	c.Close()
+	if err := c.QueueBroadcastMsg(msg); err != chainclient.ErrQueueClosed {
+		t.Fatalf("expected ErrQueueClosed, got %v", err)
+	}
+
+	if _, err := NewCosmosClient(nil).SyncBroadcastMsg(msg); err != chainclient.ErrReadOnly {
+		t.Fatalf("expected ErrReadOnly, got %v", err)
+	}
+}
+
+func TestQueryServer(t *testing.T) {
+	owner := sdk.AccAddress("test_owner__________")
+
+	var balances sdk.Coins
+	for i := 0; i < 150; i++ {
+		balances = append(balances, sdk.NewInt64Coin(fmt.Sprintf("denom%03d", i), int64(i+1)))
+	}
+
+	s, err := NewQueryServer(&Fixtures{
+		SpotMarkets: []*exchangetypes.SpotMarket{{
+			Ticker:   "INJ/USDT",
+			MarketId: "0x01",
+			Status:   exchangetypes.MarketStatus_Active,
+		}, {
+			Ticker:   "ATOM/USDT",
+			MarketId: "0x02",
+			Status:   exchangetypes.MarketStatus_Paused,
+		}},
+		SpotOrderbooks: map[string]*Orderbook{
+			"0x01": {
+				Buys: []*exchangetypes.PriceLevel{
+					{Price: sdk.NewDec(10), Quantity: sdk.NewDec(1)},
+					{Price: sdk.NewDec(9), Quantity: sdk.NewDec(2)},
+				},
+				Sells: []*exchangetypes.PriceLevel{
+					{Price: sdk.NewDec(11), Quantity: sdk.NewDec(3)},
+				},
+			},
+		},
+		Deposits: map[string]map[string]*exchangetypes.Deposit{
+			"0x02": {"usdt": exchangetypes.NewDeposit()},
+			"0x01": {"usdt": exchangetypes.NewDeposit(), "inj": exchangetypes.NewDeposit()},
+		},
+		Balances: map[string]sdk.Coins{
+			owner.String(): balances,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	queries := NewCosmosClient(s).ChainQueries()
+	ctx := context.Background()
+
+	markets, err := queries.SpotMarkets(ctx, exchangetypes.MarketStatus_Active)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(markets) != 1 || markets[0].MarketId != "0x01" {
+		t.Fatalf("expected the active market only, got %v", markets)
+	}
+
+	allBalances, err := queries.AllBalances(ctx, owner)
+	if err != nil {
+		t.Fatal(err)
+	} else if !allBalances.IsEqual(balances) {
+		t.Fatalf("expected %d balances from all pages, got %d", len(balances), len(allBalances))
+	}
+
+	exchangeQueries := exchangetypes.NewQueryClient(s.Conn())
+
+	orderbook, err := exchangeQueries.SpotOrderbook(ctx, &exchangetypes.QuerySpotOrderbookRequest{MarketId: "0x01", Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(orderbook.BuysPriceLevel) != 1 || !orderbook.BuysPriceLevel[0].Price.Equal(sdk.NewDec(10)) || len(orderbook.SellsPriceLevel) != 1 {
+		t.Fatalf("expected the best price level of each side, got %v", orderbook)
+	}
+
+	exchangeBalances, err := exchangeQueries.ExchangeBalances(ctx, &exchangetypes.QueryExchangeBalancesRequest{})
+	if err != nil {
+		t.Fatal(err)
+	} else if b := exchangeBalances.Balances; len(b) != 3 || b[0].Denom != "inj" || b[2].SubaccountId != "0x02" {
+		t.Fatalf("expected balances sorted by subaccount and denom, got %v", b)
+	}
+
+	s.SetError("/injective.exchange.v1beta1.Query/SpotMarkets", status.Error(codes.Unavailable, "down"))
+	if _, err := queries.SpotMarkets(ctx, exchangetypes.MarketStatus_Active); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected Unavailable, got %v", err)
+	}
+}
@@ -0,0 +1,365 @@
+// Package clienttest provides test doubles of the chain client: a CosmosClient that records
+// broadcasted messages and replies with scripted responses, and a chain query server
+// that serves module queries from fixtures over an in-process gRPC connection.
+package clienttest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"google.golang.org/grpc"
+
+	chainclient "github.com/InjectiveLabs/sdk-go/chain/client"
+)
+
+var _ chainclient.CosmosClient = (*CosmosClient)(nil)
+
+// BroadcastMode tells which CosmosClient method has sent the messages.
+type BroadcastMode string
+
+const (
+	BroadcastModeSync   BroadcastMode = "sync"
+	BroadcastModeAsync  BroadcastMode = "async"
+	BroadcastModeQueue  BroadcastMode = "queue"
+	BroadcastModeSigned BroadcastMode = "signed"
+)
+
+// Broadcast is a recorded broadcast call.
+type Broadcast struct {
+	Mode BroadcastMode
+	Msgs []sdk.Msg
+	// TxBytes are set for BroadcastSignedTx calls.
+	TxBytes []byte
+}
+
+// BroadcastHandler produces the response to a broadcast, when no scripted response is pending.
+type BroadcastHandler func(b *Broadcast) (*sdk.TxResponse, error)
+
+// SimulateHandler produces the result of SimulateMsg.
+type SimulateHandler func(msgs []sdk.Msg) (*chainclient.SimulationResult, error)
+
+type scriptedResponse struct {
+	res *sdk.TxResponse
+	err error
+}
+
+// CosmosClient is an in-memory CosmosClient. Broadcasts are recorded and answered with
+// scripted responses first, then by the broadcast handler, which by default reports
+// a successful Tx included in the next block.
+type CosmosClient struct {
+	// height is the block height of the default broadcast handler, accessed atomically
+	// and kept first for 64-bit alignment
+	height int64
+
+	mux       *sync.Mutex
+	clientCtx client.Context
+	addresses []sdk.AccAddress
+	queries   *QueryServer
+
+	broadcasts []*Broadcast
+	scripted   []scriptedResponse
+	broadcast  BroadcastHandler
+	simulate   SimulateHandler
+
+	gasEstimates map[string]uint64
+	closed       bool
+}
+
+// NewCosmosClient creates a client signing as the from addresses, read-only if there are none.
+// queries may be nil, then QueryClient and ChainQueries return nil.
+func NewCosmosClient(queries *QueryServer, from ...sdk.AccAddress) *CosmosClient {
+	clientCtx, _ := chainclient.NewClientContext("injective-888", "", nil)
+	if len(from) > 0 {
+		clientCtx = clientCtx.WithFromAddress(from[0])
+	}
+
+	c := &CosmosClient{
+		mux:          new(sync.Mutex),
+		clientCtx:    clientCtx,
+		addresses:    from,
+		queries:      queries,
+		gasEstimates: make(map[string]uint64),
+	}
+
+	c.broadcast = c.includeInNextBlock
+	c.simulate = func([]sdk.Msg) (*chainclient.SimulationResult, error) {
+		return &chainclient.SimulationResult{}, nil
+	}
+
+	return c
+}
+
+// includeInNextBlock is the default broadcast handler.
+func (c *CosmosClient) includeInNextBlock(*Broadcast) (*sdk.TxResponse, error) {
+	height := atomic.AddInt64(&c.height, 1)
+
+	return &sdk.TxResponse{
+		TxHash: fmt.Sprintf("%064X", height),
+		Height: height,
+	}, nil
+}
+
+// PushResponse scripts the response of the next broadcast. Responses are used in the order pushed.
+func (c *CosmosClient) PushResponse(res *sdk.TxResponse, err error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.scripted = append(c.scripted, scriptedResponse{
+		res: res,
+		err: err,
+	})
+}
+
+// SetBroadcastHandler sets the handler of broadcasts that have no scripted response.
+func (c *CosmosClient) SetBroadcastHandler(handler BroadcastHandler) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.broadcast = handler
+}
+
+// SetSimulateHandler sets the handler of SimulateMsg calls.
+func (c *CosmosClient) SetSimulateHandler(handler SimulateHandler) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.simulate = handler
+}
+
+// SetMsgGasEstimates sets the estimates returned by MsgGasEstimates.
+func (c *CosmosClient) SetMsgGasEstimates(estimates map[string]uint64) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.gasEstimates = estimates
+}
+
+// Broadcasts returns all recorded broadcasts.
+func (c *CosmosClient) Broadcasts() []*Broadcast {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return append([]*Broadcast(nil), c.broadcasts...)
+}
+
+// BroadcastedMsgs returns messages of all recorded broadcasts, in order.
+func (c *CosmosClient) BroadcastedMsgs() []sdk.Msg {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	var msgs []sdk.Msg
+	for _, b := range c.broadcasts {
+		msgs = append(msgs, b.Msgs...)
+	}
+
+	return msgs
+}
+
+// Reset forgets recorded broadcasts and pending scripted responses.
+func (c *CosmosClient) Reset() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.broadcasts = nil
+	c.scripted = nil
+}
+
+// record answers the broadcast with the next scripted response, or the broadcast handler. The handler
+// is called without holding the lock, so it may use the client.
+func (c *CosmosClient) record(b *Broadcast) (*sdk.TxResponse, error) {
+	c.mux.Lock()
+	c.broadcasts = append(c.broadcasts, b)
+
+	if len(c.scripted) > 0 {
+		next := c.scripted[0]
+		c.scripted = c.scripted[1:]
+		c.mux.Unlock()
+
+		return next.res, next.err
+	}
+
+	broadcast := c.broadcast
+	c.mux.Unlock()
+
+	return broadcast(b)
+}
+
+func (c *CosmosClient) broadcastMsgs(ctx context.Context, mode BroadcastMode, msgs []sdk.Msg) (*sdk.TxResponse, error) {
+	if !c.CanSignTransactions() {
+		return nil, chainclient.ErrReadOnly
+	} else if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return c.record(&Broadcast{
+		Mode: mode,
+		Msgs: msgs,
+	})
+}
+
+func (c *CosmosClient) CanSignTransactions() bool {
+	return len(c.addresses) > 0
+}
+
+func (c *CosmosClient) FromAddress() sdk.AccAddress {
+	if len(c.addresses) == 0 {
+		return nil
+	}
+
+	return c.addresses[0]
+}
+
+func (c *CosmosClient) FromAddresses() []sdk.AccAddress {
+	return c.addresses
+}
+
+func (c *CosmosClient) QueryClient() *grpc.ClientConn {
+	if c.queries == nil {
+		return nil
+	}
+
+	return c.queries.Conn()
+}
+
+func (c *CosmosClient) ChainQueries() *chainclient.ChainQueryClient {
+	if c.queries == nil {
+		return nil
+	}
+
+	return chainclient.NewChainQueryClient(c.queries.Conn(), c.clientCtx.InterfaceRegistry)
+}
+
+func (c *CosmosClient) SyncBroadcastMsg(msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+	return c.SyncBroadcastMsgWithContext(context.Background(), msgs...)
+}
+
+func (c *CosmosClient) SyncBroadcastMsgWithContext(ctx context.Context, msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+	return c.broadcastMsgs(ctx, BroadcastModeSync, msgs)
+}
+
+func (c *CosmosClient) AsyncBroadcastMsg(msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+	return c.AsyncBroadcastMsgWithContext(context.Background(), msgs...)
+}
+
+func (c *CosmosClient) AsyncBroadcastMsgWithContext(ctx context.Context, msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+	return c.broadcastMsgs(ctx, BroadcastModeAsync, msgs)
+}
+
+func (c *CosmosClient) BroadcastSignedTx(ctx context.Context, txBytes []byte, _ bool) (*sdk.TxResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return c.record(&Broadcast{
+		Mode:    BroadcastModeSigned,
+		TxBytes: txBytes,
+	})
+}
+
+func (c *CosmosClient) SimulateMsg(msgs ...sdk.Msg) (*chainclient.SimulationResult, error) {
+	return c.SimulateMsgWithContext(context.Background(), msgs...)
+}
+
+func (c *CosmosClient) SimulateMsgWithContext(ctx context.Context, msgs ...sdk.Msg) (*chainclient.SimulationResult, error) {
+	if !c.CanSignTransactions() {
+		return nil, chainclient.ErrReadOnly
+	} else if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c.mux.Lock()
+	simulate := c.simulate
+	c.mux.Unlock()
+
+	return simulate(msgs)
+}
+
+// QueueBroadcastMsg broadcasts msgs right away, as one batch.
+func (c *CosmosClient) QueueBroadcastMsg(msgs ...sdk.Msg) error {
+	_, err := c.QueueBroadcastMsgWithResult(msgs...)
+	return err
+}
+
+// QueueBroadcastMsgWithResult broadcasts msgs right away, as one batch. The futures
+// are resolved with the broadcast response, events of every message are taken from its log.
+func (c *CosmosClient) QueueBroadcastMsgWithResult(msgs ...sdk.Msg) ([]*chainclient.MsgResultFuture, error) {
+	if !c.CanSignTransactions() {
+		return nil, chainclient.ErrReadOnly
+	}
+
+	c.mux.Lock()
+	closed := c.closed
+	c.mux.Unlock()
+
+	if closed {
+		return nil, chainclient.ErrQueueClosed
+	}
+
+	res, err := c.broadcastMsgs(context.Background(), BroadcastModeQueue, msgs)
+	if err == nil && res != nil && res.Code != 0 {
+		err = chainclient.NewChainError(res.Codespace, res.Code, res.RawLog)
+	}
+
+	futures := make([]*chainclient.MsgResultFuture, 0, len(msgs))
+	for idx := range msgs {
+		result := &chainclient.MsgResult{
+			MsgIndex: idx,
+			Err:      err,
+		}
+
+		if res != nil {
+			result.TxHash = res.TxHash
+			result.Height = res.Height
+			result.Code = res.Code
+			result.Codespace = res.Codespace
+			result.RawLog = res.RawLog
+
+			for _, msgLog := range res.Logs {
+				if int(msgLog.MsgIndex) == idx {
+					result.Events = msgLog.Events
+					break
+				}
+			}
+		}
+
+		futures = append(futures, chainclient.NewResolvedMsgResultFuture(result))
+	}
+
+	return futures, nil
+}
+
+func (c *CosmosClient) MsgGasEstimates() map[string]uint64 {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	estimates := make(map[string]uint64, len(c.gasEstimates))
+	for msgType, gas := range c.gasEstimates {
+		estimates[msgType] = gas
+	}
+
+	return estimates
+}
+
+func (c *CosmosClient) NodeStatuses() []chainclient.NodeStatus {
+	return []chainclient.NodeStatus{{
+		Height:  atomic.LoadInt64(&c.height),
+		Healthy: true,
+		Current: true,
+	}}
+}
+
+func (c *CosmosClient) ClientContext() client.Context {
+	return c.clientCtx
+}
+
+// Close makes further queueing fail with ErrQueueClosed. The query server is not closed.
+func (c *CosmosClient) Close() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.closed = true
+}
@@ -0,0 +1,672 @@
+package clienttest
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+	oracletypes "github.com/InjectiveLabs/sdk-go/chain/oracle/types"
+)
+
+// Fixtures is the chain state served by QueryServer.
+type Fixtures struct {
+	// Height is the block height reported in response headers, 1 if not set.
+	Height int64
+
+	ExchangeParams    exchangetypes.Params
+	SpotMarkets       []*exchangetypes.SpotMarket
+	DerivativeMarkets []*exchangetypes.FullDerivativeMarket
+	// Deposits are keyed by subaccount ID, then by denom.
+	Deposits  map[string]map[string]*exchangetypes.Deposit
+	Positions []exchangetypes.DerivativePosition
+	// SpotOrders and DerivativeOrders are keyed by market and subaccount of the trader.
+	SpotOrders       map[TraderMarket][]*exchangetypes.TrimmedSpotLimitOrder
+	DerivativeOrders map[TraderMarket][]*exchangetypes.TrimmedDerivativeLimitOrder
+	// TradeNonces are keyed by subaccount ID.
+	TradeNonces map[string]uint32
+	// SpotOrderbooks and DerivativeOrderbooks are keyed by market ID.
+	SpotOrderbooks       map[string]*Orderbook
+	DerivativeOrderbooks map[string]*Orderbook
+
+	OracleParams         oracletypes.Params
+	BandRelayers         []string
+	BandPriceStates      []*oracletypes.BandPriceState
+	PriceFeedPriceStates []*oracletypes.PriceFeedState
+	CoinbasePriceStates  []*oracletypes.CoinbasePriceState
+
+	BankParams banktypes.Params
+	// Balances are keyed by bech32 account address.
+	Balances       map[string]sdk.Coins
+	Supply         sdk.Coins
+	DenomsMetadata []banktypes.Metadata
+}
+
+// TraderMarket identifies orders of a subaccount in a market.
+type TraderMarket struct {
+	MarketID     string
+	SubaccountID string
+}
+
+// Orderbook are aggregated price levels of a market, best first: buys by descending price,
+// sells by ascending price.
+type Orderbook struct {
+	Buys  []*exchangetypes.PriceLevel
+	Sells []*exchangetypes.PriceLevel
+}
+
+// levels returns up to limit price levels of both sides, all if limit is zero.
+func (o *Orderbook) levels(limit uint64) (buys, sells []*exchangetypes.PriceLevel) {
+	if o == nil {
+		return nil, nil
+	}
+
+	buys, sells = o.Buys, o.Sells
+	if limit > 0 && uint64(len(buys)) > limit {
+		buys = buys[:limit]
+	}
+
+	if limit > 0 && uint64(len(sells)) > limit {
+		sells = sells[:limit]
+	}
+
+	return buys, sells
+}
+
+// QueryServer serves exchange, oracle and bank module queries from fixtures,
+// over an in-process gRPC connection.
+type QueryServer struct {
+	mux      *sync.RWMutex
+	fixtures *Fixtures
+	errs     map[string]error
+
+	listener *bufconn.Listener
+	server   *grpc.Server
+	conn     *grpc.ClientConn
+}
+
+const bufconnSize = 1024 * 1024
+
+// NewQueryServer starts serving the fixtures. Use Conn to query, and Update to change
+// the fixtures while the test runs.
+func NewQueryServer(fixtures *Fixtures) (*QueryServer, error) {
+	if fixtures == nil {
+		fixtures = &Fixtures{}
+	}
+
+	s := &QueryServer{
+		mux:      new(sync.RWMutex),
+		fixtures: fixtures,
+		errs:     make(map[string]error),
+		listener: bufconn.Listen(bufconnSize),
+	}
+	s.initFixtures()
+
+	s.server = grpc.NewServer(grpc.UnaryInterceptor(s.errorInterceptor))
+	exchangetypes.RegisterQueryServer(s.server, &exchangeQueryServer{s: s})
+	oracletypes.RegisterQueryServer(s.server, &oracleQueryServer{s: s})
+	banktypes.RegisterQueryServer(s.server, &bankQueryServer{s: s})
+
+	go func() {
+		_ = s.server.Serve(s.listener)
+	}()
+
+	conn, err := grpc.Dial(
+		"bufnet",
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return s.listener.Dial()
+		}),
+	)
+	if err != nil {
+		s.server.Stop()
+		err = errors.Wrap(err, "failed to dial bufconn")
+		return nil, err
+	}
+
+	s.conn = conn
+
+	return s, nil
+}
+
+func (s *QueryServer) initFixtures() {
+	if s.fixtures.Height == 0 {
+		s.fixtures.Height = 1
+	}
+
+	if s.fixtures.Deposits == nil {
+		s.fixtures.Deposits = make(map[string]map[string]*exchangetypes.Deposit)
+	}
+
+	if s.fixtures.SpotOrders == nil {
+		s.fixtures.SpotOrders = make(map[TraderMarket][]*exchangetypes.TrimmedSpotLimitOrder)
+	}
+
+	if s.fixtures.DerivativeOrders == nil {
+		s.fixtures.DerivativeOrders = make(map[TraderMarket][]*exchangetypes.TrimmedDerivativeLimitOrder)
+	}
+
+	if s.fixtures.TradeNonces == nil {
+		s.fixtures.TradeNonces = make(map[string]uint32)
+	}
+
+	if s.fixtures.SpotOrderbooks == nil {
+		s.fixtures.SpotOrderbooks = make(map[string]*Orderbook)
+	}
+
+	if s.fixtures.DerivativeOrderbooks == nil {
+		s.fixtures.DerivativeOrderbooks = make(map[string]*Orderbook)
+	}
+
+	if s.fixtures.Balances == nil {
+		s.fixtures.Balances = make(map[string]sdk.Coins)
+	}
+}
+
+// Conn returns the client connection to the server.
+func (s *QueryServer) Conn() *grpc.ClientConn {
+	return s.conn
+}
+
+// Update changes the fixtures, queries see either the old or the new state.
+func (s *QueryServer) Update(fn func(f *Fixtures)) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	fn(s.fixtures)
+	s.initFixtures()
+}
+
+// SetError makes calls of the gRPC method, e.g. "/injective.exchange.v1beta1.Query/SpotMarkets",
+// fail with err until it is set to nil.
+func (s *QueryServer) SetError(method string, err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if err == nil {
+		delete(s.errs, method)
+		return
+	}
+
+	s.errs[method] = err
+}
+
+func (s *QueryServer) Close() {
+	s.conn.Close()
+	s.server.Stop()
+}
+
+func (s *QueryServer) errorInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	s.mux.RLock()
+	err := s.errs[info.FullMethod]
+	height := s.fixtures.Height
+	s.mux.RUnlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	header := metadata.Pairs(grpctypes.GRPCBlockHeightHeader, strconv.FormatInt(height, 10))
+	if err := grpc.SetHeader(ctx, header); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// view runs fn with the fixtures locked for reading.
+func (s *QueryServer) view(fn func(f *Fixtures)) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	fn(s.fixtures)
+}
+
+type exchangeQueryServer struct {
+	exchangetypes.UnimplementedQueryServer
+
+	s *QueryServer
+}
+
+func (q *exchangeQueryServer) QueryExchangeParams(context.Context, *exchangetypes.QueryExchangeParamsRequest) (res *exchangetypes.QueryExchangeParamsResponse, err error) {
+	q.s.view(func(f *Fixtures) {
+		res = &exchangetypes.QueryExchangeParamsResponse{
+			Params: f.ExchangeParams,
+		}
+	})
+
+	return res, nil
+}
+
+func (q *exchangeQueryServer) SubaccountDeposits(_ context.Context, req *exchangetypes.QuerySubaccountDepositsRequest) (*exchangetypes.QuerySubaccountDepositsResponse, error) {
+	subaccountID := req.SubaccountId
+	if req.Subaccount != nil {
+		id, err := req.Subaccount.GetSubaccountID()
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		subaccountID = id.Hex()
+	}
+
+	res := &exchangetypes.QuerySubaccountDepositsResponse{
+		Deposits: make(map[string]*exchangetypes.Deposit),
+	}
+
+	q.s.view(func(f *Fixtures) {
+		for denom, deposit := range f.Deposits[subaccountID] {
+			res.Deposits[denom] = deposit
+		}
+	})
+
+	return res, nil
+}
+
+func (q *exchangeQueryServer) SubaccountDeposit(_ context.Context, req *exchangetypes.QuerySubaccountDepositRequest) (res *exchangetypes.QuerySubaccountDepositResponse, err error) {
+	q.s.view(func(f *Fixtures) {
+		res = &exchangetypes.QuerySubaccountDepositResponse{
+			Deposits: f.Deposits[req.SubaccountId][req.Denom],
+		}
+	})
+
+	return res, nil
+}
+
+func (q *exchangeQueryServer) SpotMarkets(_ context.Context, req *exchangetypes.QuerySpotMarketsRequest) (*exchangetypes.QuerySpotMarketsResponse, error) {
+	res := &exchangetypes.QuerySpotMarketsResponse{}
+
+	q.s.view(func(f *Fixtures) {
+		for _, market := range f.SpotMarkets {
+			if req.Status == exchangetypes.MarketStatus_Unspecified || market.Status == req.Status {
+				res.Markets = append(res.Markets, market)
+			}
+		}
+	})
+
+	return res, nil
+}
+
+func (q *exchangeQueryServer) SpotMarket(_ context.Context, req *exchangetypes.QuerySpotMarketRequest) (*exchangetypes.QuerySpotMarketResponse, error) {
+	res := &exchangetypes.QuerySpotMarketResponse{}
+
+	q.s.view(func(f *Fixtures) {
+		for _, market := range f.SpotMarkets {
+			if market.MarketId == req.MarketId {
+				res.Market = market
+				break
+			}
+		}
+	})
+
+	return res, nil
+}
+
+func (q *exchangeQueryServer) DerivativeMarkets(_ context.Context, req *exchangetypes.QueryDerivativeMarketsRequest) (*exchangetypes.QueryDerivativeMarketsResponse, error) {
+	res := &exchangetypes.QueryDerivativeMarketsResponse{}
+
+	q.s.view(func(f *Fixtures) {
+		for _, market := range f.DerivativeMarkets {
+			if req.Status == exchangetypes.MarketStatus_Unspecified || market.Market.Status == req.Status {
+				res.Markets = append(res.Markets, market)
+			}
+		}
+	})
+
+	return res, nil
+}
+
+func (q *exchangeQueryServer) DerivativeMarket(_ context.Context, req *exchangetypes.QueryDerivativeMarketRequest) (*exchangetypes.QueryDerivativeMarketResponse, error) {
+	res := &exchangetypes.QueryDerivativeMarketResponse{}
+
+	q.s.view(func(f *Fixtures) {
+		for _, market := range f.DerivativeMarkets {
+			if market.Market.MarketId == req.MarketId {
+				res.Market = market
+				break
+			}
+		}
+	})
+
+	return res, nil
+}
+
+func (q *exchangeQueryServer) TraderSpotOrders(_ context.Context, req *exchangetypes.QueryTraderSpotOrdersRequest) (res *exchangetypes.QueryTraderSpotOrdersResponse, err error) {
+	q.s.view(func(f *Fixtures) {
+		res = &exchangetypes.QueryTraderSpotOrdersResponse{
+			Orders: f.SpotOrders[TraderMarket{MarketID: req.MarketId, SubaccountID: req.SubaccountId}],
+		}
+	})
+
+	return res, nil
+}
+
+func (q *exchangeQueryServer) TraderDerivativeOrders(_ context.Context, req *exchangetypes.QueryTraderDerivativeOrdersRequest) (res *exchangetypes.QueryTraderDerivativeOrdersResponse, err error) {
+	q.s.view(func(f *Fixtures) {
+		res = &exchangetypes.QueryTraderDerivativeOrdersResponse{
+			Orders: f.DerivativeOrders[TraderMarket{MarketID: req.MarketId, SubaccountID: req.SubaccountId}],
+		}
+	})
+
+	return res, nil
+}
+
+func (q *exchangeQueryServer) SubaccountTradeNonce(_ context.Context, req *exchangetypes.QuerySubaccountTradeNonceRequest) (res *exchangetypes.QuerySubaccountTradeNonceResponse, err error) {
+	q.s.view(func(f *Fixtures) {
+		res = &exchangetypes.QuerySubaccountTradeNonceResponse{
+			Nonce: f.TradeNonces[req.SubaccountId],
+		}
+	})
+
+	return res, nil
+}
+
+func (q *exchangeQueryServer) Positions(context.Context, *exchangetypes.QueryPositionsRequest) (res *exchangetypes.QueryPositionsResponse, err error) {
+	q.s.view(func(f *Fixtures) {
+		res = &exchangetypes.QueryPositionsResponse{
+			State: f.Positions,
+		}
+	})
+
+	return res, nil
+}
+
+func (q *exchangeQueryServer) SpotOrderbook(_ context.Context, req *exchangetypes.QuerySpotOrderbookRequest) (res *exchangetypes.QuerySpotOrderbookResponse, err error) {
+	q.s.view(func(f *Fixtures) {
+		res = &exchangetypes.QuerySpotOrderbookResponse{}
+		res.BuysPriceLevel, res.SellsPriceLevel = f.SpotOrderbooks[req.MarketId].levels(req.Limit)
+	})
+
+	return res, nil
+}
+
+func (q *exchangeQueryServer) DerivativeOrderbook(_ context.Context, req *exchangetypes.QueryDerivativeOrderbookRequest) (res *exchangetypes.QueryDerivativeOrderbookResponse, err error) {
+	q.s.view(func(f *Fixtures) {
+		res = &exchangetypes.QueryDerivativeOrderbookResponse{}
+		res.BuysPriceLevel, res.SellsPriceLevel = f.DerivativeOrderbooks[req.MarketId].levels(req.Limit)
+	})
+
+	return res, nil
+}
+
+func (q *exchangeQueryServer) ExchangeBalances(context.Context, *exchangetypes.QueryExchangeBalancesRequest) (res *exchangetypes.QueryExchangeBalancesResponse, err error) {
+	q.s.view(func(f *Fixtures) {
+		res = &exchangetypes.QueryExchangeBalancesResponse{
+			Balances: balances(f),
+		}
+	})
+
+	return res, nil
+}
+
+// ExchangeModuleState returns params, markets, balances, positions and trade nonces of the fixtures.
+// Orderbooks, market infos and funding states are left empty.
+func (q *exchangeQueryServer) ExchangeModuleState(context.Context, *exchangetypes.QueryModuleStateRequest) (res *exchangetypes.QueryModuleStateResponse, err error) {
+	q.s.view(func(f *Fixtures) {
+		state := &exchangetypes.GenesisState{
+			Params:                       f.ExchangeParams,
+			SpotMarkets:                  f.SpotMarkets,
+			Balances:                     balances(f),
+			Positions:                    f.Positions,
+			IsSpotExchangeEnabled:        true,
+			IsDerivativesExchangeEnabled: true,
+		}
+
+		for _, market := range f.DerivativeMarkets {
+			state.DerivativeMarkets = append(state.DerivativeMarkets, market.Market)
+		}
+
+		for subaccountID, nonce := range f.TradeNonces {
+			state.SubaccountTradeNonces = append(state.SubaccountTradeNonces, exchangetypes.SubaccountNonce{
+				SubaccountId:         subaccountID,
+				SubaccountTradeNonce: exchangetypes.SubaccountTradeNonce{Nonce: nonce},
+			})
+		}
+
+		sort.Slice(state.SubaccountTradeNonces, func(i, j int) bool {
+			return state.SubaccountTradeNonces[i].SubaccountId < state.SubaccountTradeNonces[j].SubaccountId
+		})
+
+		res = &exchangetypes.QueryModuleStateResponse{
+			State: state,
+		}
+	})
+
+	return res, nil
+}
+
+// balances lists the deposits of the fixtures, sorted by subaccount ID and denom.
+func balances(f *Fixtures) []exchangetypes.Balance {
+	var balances []exchangetypes.Balance
+	for subaccountID, deposits := range f.Deposits {
+		for denom, deposit := range deposits {
+			balances = append(balances, exchangetypes.Balance{
+				SubaccountId: subaccountID,
+				Denom:        denom,
+				Deposits:     deposit,
+			})
+		}
+	}
+
+	sort.Slice(balances, func(i, j int) bool {
+		if balances[i].SubaccountId != balances[j].SubaccountId {
+			return balances[i].SubaccountId < balances[j].SubaccountId
+		}
+
+		return balances[i].Denom < balances[j].Denom
+	})
+
+	return balances
+}
+
+type oracleQueryServer struct {
+	oracletypes.UnimplementedQueryServer
+
+	s *QueryServer
+}
+
+func (q *oracleQueryServer) Params(context.Context, *oracletypes.QueryParamsRequest) (res *oracletypes.QueryParamsResponse, err error) {
+	q.s.view(func(f *Fixtures) {
+		res = &oracletypes.QueryParamsResponse{
+			Params: f.OracleParams,
+		}
+	})
+
+	return res, nil
+}
+
+func (q *oracleQueryServer) BandRelayers(context.Context, *oracletypes.QueryBandRelayersRequest) (res *oracletypes.QueryBandRelayersResponse, err error) {
+	q.s.view(func(f *Fixtures) {
+		res = &oracletypes.QueryBandRelayersResponse{
+			Relayers: f.BandRelayers,
+		}
+	})
+
+	return res, nil
+}
+
+func (q *oracleQueryServer) BandPriceStates(context.Context, *oracletypes.QueryBandPriceStatesRequest) (res *oracletypes.QueryBandPriceStatesResponse, err error) {
+	q.s.view(func(f *Fixtures) {
+		res = &oracletypes.QueryBandPriceStatesResponse{
+			PriceStates: f.BandPriceStates,
+		}
+	})
+
+	return res, nil
+}
+
+func (q *oracleQueryServer) PriceFeedPriceStates(context.Context, *oracletypes.QueryPriceFeedPriceStatesRequest) (res *oracletypes.QueryPriceFeedPriceStatesResponse, err error) {
+	q.s.view(func(f *Fixtures) {
+		res = &oracletypes.QueryPriceFeedPriceStatesResponse{
+			PriceStates: f.PriceFeedPriceStates,
+		}
+	})
+
+	return res, nil
+}
+
+func (q *oracleQueryServer) CoinbasePriceStates(context.Context, *oracletypes.QueryCoinbasePriceStatesRequest) (res *oracletypes.QueryCoinbasePriceStatesResponse, err error) {
+	q.s.view(func(f *Fixtures) {
+		res = &oracletypes.QueryCoinbasePriceStatesResponse{
+			PriceStates: f.CoinbasePriceStates,
+		}
+	})
+
+	return res, nil
+}
+
+type bankQueryServer struct {
+	banktypes.UnimplementedQueryServer
+
+	s *QueryServer
+}
+
+func (q *bankQueryServer) Balance(_ context.Context, req *banktypes.QueryBalanceRequest) (res *banktypes.QueryBalanceResponse, err error) {
+	q.s.view(func(f *Fixtures) {
+		balance := sdk.NewCoin(req.Denom, f.Balances[req.Address].AmountOf(req.Denom))
+		res = &banktypes.QueryBalanceResponse{
+			Balance: &balance,
+		}
+	})
+
+	return res, nil
+}
+
+func (q *bankQueryServer) AllBalances(_ context.Context, req *banktypes.QueryAllBalancesRequest) (res *banktypes.QueryAllBalancesResponse, err error) {
+	q.s.view(func(f *Fixtures) {
+		balances := f.Balances[req.Address]
+
+		var start, end int
+		res = &banktypes.QueryAllBalancesResponse{}
+		start, end, res.Pagination, err = paginate(len(balances), req.Pagination)
+		if err == nil {
+			res.Balances = balances[start:end]
+		}
+	})
+
+	return res, err
+}
+
+func (q *bankQueryServer) TotalSupply(_ context.Context, req *banktypes.QueryTotalSupplyRequest) (res *banktypes.QueryTotalSupplyResponse, err error) {
+	q.s.view(func(f *Fixtures) {
+		var start, end int
+		res = &banktypes.QueryTotalSupplyResponse{}
+		start, end, res.Pagination, err = paginate(len(f.Supply), req.Pagination)
+		if err == nil {
+			res.Supply = f.Supply[start:end]
+		}
+	})
+
+	return res, err
+}
+
+func (q *bankQueryServer) SupplyOf(_ context.Context, req *banktypes.QuerySupplyOfRequest) (res *banktypes.QuerySupplyOfResponse, err error) {
+	q.s.view(func(f *Fixtures) {
+		res = &banktypes.QuerySupplyOfResponse{
+			Amount: sdk.NewCoin(req.Denom, f.Supply.AmountOf(req.Denom)),
+		}
+	})
+
+	return res, nil
+}
+
+func (q *bankQueryServer) Params(context.Context, *banktypes.QueryParamsRequest) (res *banktypes.QueryParamsResponse, err error) {
+	q.s.view(func(f *Fixtures) {
+		res = &banktypes.QueryParamsResponse{
+			Params: f.BankParams,
+		}
+	})
+
+	return res, nil
+}
+
+func (q *bankQueryServer) DenomMetadata(_ context.Context, req *banktypes.QueryDenomMetadataRequest) (res *banktypes.QueryDenomMetadataResponse, err error) {
+	q.s.view(func(f *Fixtures) {
+		for _, metadata := range f.DenomsMetadata {
+			if metadata.Base == req.Denom {
+				res = &banktypes.QueryDenomMetadataResponse{
+					Metadata: metadata,
+				}
+				return
+			}
+		}
+	})
+
+	if res == nil {
+		return nil, status.Errorf(codes.NotFound, "client metadata for denom %s", req.Denom)
+	}
+
+	return res, nil
+}
+
+func (q *bankQueryServer) DenomsMetadata(_ context.Context, req *banktypes.QueryDenomsMetadataRequest) (res *banktypes.QueryDenomsMetadataResponse, err error) {
+	q.s.view(func(f *Fixtures) {
+		var start, end int
+		res = &banktypes.QueryDenomsMetadataResponse{}
+		start, end, res.Pagination, err = paginate(len(f.DenomsMetadata), req.Pagination)
+		if err == nil {
+			res.Metadatas = f.DenomsMetadata[start:end]
+		}
+	})
+
+	return res, err
+}
+
+const defaultPageLimit = 100
+
+// paginate returns the range of items of the page. Page keys are the stringified item offsets.
+func paginate(total int, pageReq *query.PageRequest) (start, end int, pageRes *query.PageResponse, err error) {
+	if pageReq == nil {
+		pageReq = &query.PageRequest{}
+	}
+
+	start = int(pageReq.Offset)
+	if len(pageReq.Key) > 0 {
+		if start, err = strconv.Atoi(string(pageReq.Key)); err != nil {
+			err = status.Error(codes.InvalidArgument, "invalid page key")
+			return 0, 0, nil, err
+		}
+	}
+
+	if start > total {
+		start = total
+	}
+
+	limit := int(pageReq.Limit)
+	if limit == 0 {
+		limit = defaultPageLimit
+	}
+
+	end = start + limit
+	if end > total {
+		end = total
+	}
+
+	pageRes = &query.PageResponse{}
+	if end < total {
+		pageRes.NextKey = []byte(strconv.Itoa(end))
+	}
+
+	if pageReq.CountTotal {
+		pageRes.Total = uint64(total)
+	}
+
+	return start, end, pageRes, nil
+}
@@ -110,26 +110,17 @@ func NewClientContext(
 		Marshaler:         marshaler,
 		TxConfig: NewTxConfig([]signingtypes.SignMode{
 			signingtypes.SignMode_SIGN_MODE_DIRECT,
+			signingtypes.SignMode_SIGN_MODE_LEGACY_AMINO_JSON,
 		}),
 	}
 
 	var keyInfo keyring.Info
 
 	if kb != nil {
-		addr, err := cosmostypes.AccAddressFromBech32(fromSpec)
-		if err == nil {
-			keyInfo, err = kb.KeyByAddress(addr)
-			if err != nil {
-				err = errors.Wrapf(err, "failed to load key info by address %s", addr.String())
-				return clientCtx, err
-			}
-		} else {
-			// failed to parse Bech32, is it a name?
-			keyInfo, err = kb.Key(fromSpec)
-			if err != nil {
-				err = errors.Wrapf(err, "no key in keyring for name: %s", fromSpec)
-				return clientCtx, err
-			}
+		var err error
+		keyInfo, err = keyInfoFromSpec(kb, fromSpec)
+		if err != nil {
+			return clientCtx, err
 		}
 	}
 
@@ -143,6 +134,30 @@ func NewClientContext(
 	return clientCtx, nil
 }
 
+// keyInfoFromSpec loads key info from keyring, where fromSpec is either
+// name of the key, or bech32-address of the Cosmos account.
+func keyInfoFromSpec(kb keyring.Keyring, fromSpec string) (keyring.Info, error) {
+	addr, err := cosmostypes.AccAddressFromBech32(fromSpec)
+	if err == nil {
+		keyInfo, err := kb.KeyByAddress(addr)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to load key info by address %s", addr.String())
+			return nil, err
+		}
+
+		return keyInfo, nil
+	}
+
+	// failed to parse Bech32, is it a name?
+	keyInfo, err := kb.Key(fromSpec)
+	if err != nil {
+		err = errors.Wrapf(err, "no key in keyring for name: %s", fromSpec)
+		return nil, err
+	}
+
+	return keyInfo, nil
+}
+
 type EncodingConfig struct {
 	InterfaceRegistry types.InterfaceRegistry
 	Marshaler         codec.Codec
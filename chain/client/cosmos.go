@@ -4,8 +4,7 @@ import (
 	"context"
 	"encoding/hex"
 	"encoding/json"
-	"strings"
-	"sync"
+	"fmt"
 	"sync/atomic"
 	"time"
 
@@ -13,6 +12,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/client/tx"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/pkg/errors"
+	tmtypes "github.com/tendermint/tendermint/types"
 	log "github.com/xlab/suplog"
 	"google.golang.org/grpc"
 
@@ -31,27 +31,33 @@ func init() {
 type CosmosClient interface {
 	CanSignTransactions() bool
 	FromAddress() sdk.AccAddress
+	FromAddresses() []sdk.AccAddress
 	QueryClient() *grpc.ClientConn
+	ChainQueries() *ChainQueryClient
 	SyncBroadcastMsg(msgs ...sdk.Msg) (*sdk.TxResponse, error)
+	SyncBroadcastMsgWithContext(ctx context.Context, msgs ...sdk.Msg) (*sdk.TxResponse, error)
 	AsyncBroadcastMsg(msgs ...sdk.Msg) (*sdk.TxResponse, error)
+	AsyncBroadcastMsgWithContext(ctx context.Context, msgs ...sdk.Msg) (*sdk.TxResponse, error)
+	BroadcastSignedTx(ctx context.Context, txBytes []byte, await bool) (*sdk.TxResponse, error)
+	SimulateMsg(msgs ...sdk.Msg) (*SimulationResult, error)
+	SimulateMsgWithContext(ctx context.Context, msgs ...sdk.Msg) (*SimulationResult, error)
 	QueueBroadcastMsg(msgs ...sdk.Msg) error
+	QueueBroadcastMsgWithResult(msgs ...sdk.Msg) ([]*MsgResultFuture, error)
+	MsgGasEstimates() map[string]uint64
+	NodeStatuses() []NodeStatus
 	ClientContext() client.Context
 	Close()
 }
 
 // NewCosmosClient creates a new gRPC client that communicates with gRPC server at protoAddr.
 // protoAddr must be in form "tcp://127.0.0.1:8080" or "unix:///tmp/test.sock", protocol is required.
+// Together with Tendermint RPC client from ctx it makes the primary node, other nodes to fail over to
+// can be added using OptionNodes.
 func NewCosmosClient(
 	ctx client.Context,
 	protoAddr string,
 	options ...cosmosClientOption,
 ) (CosmosClient, error) {
-	conn, err := grpc.Dial(protoAddr, grpc.WithInsecure(), grpc.WithContextDialer(dialerFunc))
-	if err != nil {
-		err := errors.Wrapf(err, "failed to connect to the gRPC: %s", protoAddr)
-		return nil, err
-	}
-
 	opts := defaultCosmosClientOptions()
 	for _, opt := range options {
 		if err := opt(opts); err != nil {
@@ -60,6 +66,24 @@ func NewCosmosClient(
 		}
 	}
 
+	logger := log.WithFields(log.Fields{
+		"module": "sdk-go",
+		"svc":    "cosmosClient",
+	})
+
+	nodes, err := dialNodes(ctx, protoAddr, opts.Nodes, opts.DialOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := newNodePool(nodes, opts.NodeHealthCheckInterval, opts.NodeMaxHeightLag, logger)
+	if len(nodes) > 1 {
+		// pick the healthiest node before fetching account state
+		checkCtx, cancelFn := context.WithTimeout(context.Background(), opts.NodeHealthCheckInterval)
+		pool.CheckHealth(checkCtx)
+		cancelFn()
+	}
+
 	txFactory := NewTxFactory(ctx)
 	if len(opts.GasPrices) > 0 {
 		txFactory = txFactory.WithGasPrices(opts.GasPrices)
@@ -69,40 +93,150 @@ func NewCosmosClient(
 		ctx:  ctx,
 		opts: opts,
 
-		logger: log.WithFields(log.Fields{
-			"module": "sdk-go",
-			"svc":    "cosmosClient",
-		}),
+		logger: logger,
 
-		conn:      conn,
-		txFactory: txFactory,
-		canSign:   ctx.Keyring != nil,
-		syncMux:   new(sync.Mutex),
-		msgC:      make(chan sdk.Msg, msgCommitBatchSizeLimit),
-		doneC:     make(chan bool, 1),
+		nodes:      pool,
+		txFactory:  txFactory,
+		canSign:    ctx.Keyring != nil || len(opts.TxSigners) > 0,
+		dispatcher: opts.KeyDispatcher,
+
+		gasEstimator: newMsgGasEstimator(opts.DefaultMsgGas, opts.MsgGasEstimates),
+		feePayers:    newFeePayerNonces(),
+		chainQueries: NewChainQueryClient(&currentNodeConn{nodes: pool}, ctx.InterfaceRegistry),
 	}
 
 	if cc.canSign {
-		var err error
+		var signers []TxSigner
+		if ctx.Keyring != nil {
+			fromSpecs := append([]string{ctx.GetFromName()}, opts.SigningKeys...)
+			for _, fromSpec := range fromSpecs {
+				keyInfo, err := keyInfoFromSpec(ctx.Keyring, fromSpec)
+				if err != nil {
+					closeNodes(nodes)
+					err = errors.Wrap(err, "failed to load signing key")
+					return nil, err
+				}
 
-		cc.accNum, cc.accSeq, err = cc.txFactory.AccountRetriever().GetAccountNumberSequence(ctx, ctx.GetFromAddress())
-		if err != nil {
-			err = errors.Wrap(err, "failed to get initial account num and seq")
-			return nil, err
+				signer, err := NewKeyringSigner(ctx.Keyring, keyInfo.GetName())
+				if err != nil {
+					closeNodes(nodes)
+					return nil, err
+				}
+
+				signers = append(signers, signer)
+			}
 		}
 
-		go cc.runBatchBroadcast()
+		signers = append(signers, opts.TxSigners...)
+
+		for _, signer := range signers {
+			keyCtx := ctx.WithFromAddress(signer.Address())
+			if info, ok := signer.(*keyringSigner); ok {
+				keyCtx = keyCtx.WithFromName(info.keyInfo.GetName()).WithFrom(info.keyInfo.GetName())
+			} else {
+				keyCtx = keyCtx.WithFromName("").WithFrom(signer.Address().String())
+			}
+
+			key := newSigningKey(keyCtx, txFactory, signer, opts.BatchSizeLimit)
+
+			accNum, accSeq, err := txFactory.AccountRetriever().GetAccountNumberSequence(cc.nodeCtx(keyCtx), key.address())
+			if err != nil {
+				closeNodes(nodes)
+				err = errors.Wrapf(err, "failed to get initial account num and seq of %s", key.address())
+				return nil, err
+			}
+
+			state, err := opts.NonceStore.Load(key.address())
+			if err != nil {
+				closeNodes(nodes)
+				err = errors.Wrapf(err, "failed to load nonces of %s", key.address())
+				return nil, err
+			} else if state != nil && state.AccountNumber == accNum {
+				// Txns sent before restart may still be in the mempool, the persisted sequence
+				// is the fallback for those that can't be checked
+				key.inFlight = state.InFlight
+				key.accSeq = state.NextSequence
+			}
+
+			key.accNum = accNum
+			cc.reconcileNonce(key, accSeq)
+
+			cc.keys = append(cc.keys, key)
+			cc.keyAddrs = append(cc.keyAddrs, key.address())
+		}
+
+		// the primary key defines the client context
+		cc.ctx = cc.keys[0].ctx
+
+		for _, key := range cc.keys {
+			go cc.runBatchBroadcast(key)
+		}
 	}
 
+	pool.Start()
+
 	return cc, nil
 }
 
 type cosmosClientOptions struct {
 	GasPrices string
+
+	// TxDefaults are Tx options applied to all Txns, calls may override them using ContextWithTxOptions.
+	TxDefaults txOptions
+
+	// PoisonedMsgHandler is called for every queued message that has been isolated
+	// from a failed batch as the cause of the failure.
+	PoisonedMsgHandler func(msg sdk.Msg, err error)
+
+	BroadcastTimeout    time.Duration
+	BroadcastStatusPoll time.Duration
+
+	BatchSizeLimit  int
+	BatchTimeLimit  time.Duration
+	BatchGasLimit   uint64
+	DefaultMsgGas   uint64
+	MsgGasEstimates map[string]uint64
+
+	// SigningKeys are additional keys to sign Txns with, besides the key from client context.
+	SigningKeys   []string
+	TxSigners     []TxSigner
+	KeyDispatcher KeyDispatcher
+
+	// EIP712 enables EIP-712 sign mode, when set.
+	EIP712 *EIP712Options
+
+	// Nodes are additional nodes to fail over to, besides the primary one.
+	Nodes                   []NodeEndpoint
+	NodeHealthCheckInterval time.Duration
+	NodeMaxHeightLag        int64
+
+	// DialOptions configure TLS and auth of node connections.
+	DialOptions []DialOption
+
+	// NonceStore keeps account sequences and in-flight Txns of signing keys.
+	NonceStore NonceStore
+
+	Observer Observer
 }
 
 func defaultCosmosClientOptions() *cosmosClientOptions {
-	return &cosmosClientOptions{}
+	return &cosmosClientOptions{
+		BroadcastTimeout:    defaultBroadcastTimeout,
+		BroadcastStatusPoll: defaultBroadcastStatusPoll,
+
+		BatchSizeLimit: msgCommitBatchSizeLimit,
+		BatchTimeLimit: msgCommitBatchTimeLimit,
+		BatchGasLimit:  msgCommitBatchGasLimit,
+		DefaultMsgGas:  defaultMsgGasEstimate,
+
+		KeyDispatcher: SignerAffinityDispatcher(RoundRobinDispatcher()),
+
+		NodeHealthCheckInterval: defaultNodeHealthCheckInterval,
+		NodeMaxHeightLag:        defaultNodeMaxHeightLag,
+
+		NonceStore: NewMemoryNonceStore(),
+		Observer:   NopObserver{},
+	}
 }
 
 type cosmosClientOption func(opts *cosmosClientOptions) error
@@ -120,46 +254,283 @@ func OptionGasPrices(gasPrices string) cosmosClientOption {
 	}
 }
 
-func (c *cosmosClient) syncNonce() {
-	num, seq, err := c.txFactory.AccountRetriever().GetAccountNumberSequence(c.ctx, c.ctx.GetFromAddress())
+// OptionBroadcastTimeout sets the default time to wait for Tx inclusion in a block,
+// applied when the context passed to a broadcast call has no deadline of its own.
+func OptionBroadcastTimeout(timeout time.Duration) cosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		if timeout <= 0 {
+			err := errors.Errorf("broadcast timeout must be positive, got %s", timeout)
+			return err
+		}
+
+		opts.BroadcastTimeout = timeout
+		return nil
+	}
+}
+
+// OptionBroadcastStatusPoll sets the interval between Tx inclusion checks.
+func OptionBroadcastStatusPoll(interval time.Duration) cosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		if interval <= 0 {
+			err := errors.Errorf("broadcast status poll interval must be positive, got %s", interval)
+			return err
+		}
+
+		opts.BroadcastStatusPoll = interval
+		return nil
+	}
+}
+
+// OptionPoisonedMsgHandler sets a callback for queued messages that have been isolated
+// as the cause of a batch failure. Such messages are not retried.
+func OptionPoisonedMsgHandler(handler func(msg sdk.Msg, err error)) cosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		opts.PoisonedMsgHandler = handler
+		return nil
+	}
+}
+
+// OptionBatchSizeLimit sets the max amount of queued messages packed into one Tx.
+func OptionBatchSizeLimit(limit int) cosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		if limit <= 0 {
+			err := errors.Errorf("batch size limit must be positive, got %d", limit)
+			return err
+		}
+
+		opts.BatchSizeLimit = limit
+		return nil
+	}
+}
+
+// OptionBatchTimeLimit sets the max time queued messages are accumulated before being sent.
+func OptionBatchTimeLimit(limit time.Duration) cosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		if limit <= 0 {
+			err := errors.Errorf("batch time limit must be positive, got %s", limit)
+			return err
+		}
+
+		opts.BatchTimeLimit = limit
+		return nil
+	}
+}
+
+// OptionBatchGasLimit sets the gas ceiling targeted for one Tx of queued messages.
+// The batch gas is predicted from per message type estimates, learned from Tx simulations.
+// Zero value disables gas-based batching.
+func OptionBatchGasLimit(limit uint64) cosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		opts.BatchGasLimit = limit
+		return nil
+	}
+}
+
+// OptionMsgGasEstimates sets the initial gas estimates, keyed by message type URL
+// (e.g. "/injective.exchange.v1beta1.MsgCreateSpotLimitOrder"), and the estimate used
+// for message types without one. Learned estimates can be read with MsgGasEstimates
+// and fed back using this option after restart.
+func OptionMsgGasEstimates(defaultGas uint64, estimates map[string]uint64) cosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		if defaultGas == 0 {
+			err := errors.New("default msg gas estimate must be positive")
+			return err
+		}
+
+		opts.DefaultMsgGas = defaultGas
+		opts.MsgGasEstimates = estimates
+		return nil
+	}
+}
+
+// OptionSigningKeys enables multi-key mode, where Txns are signed by a pool of keys, each key
+// having its own account sequence and broadcast queue. Keys are loaded from the keyring of
+// client context, by name or bech32-address, and are used in addition to the key from context.
+func OptionSigningKeys(fromSpecs ...string) cosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		opts.SigningKeys = fromSpecs
+		return nil
+	}
+}
+
+// OptionTxSigners adds signing keys backed by custom signers, e.g. keys kept in a signing
+// service. The client context doesn't need a keyring then. If it has one, the signers are
+// used in addition to the keyring keys.
+func OptionTxSigners(signers ...TxSigner) cosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		for _, signer := range signers {
+			if signer == nil {
+				err := errors.New("tx signer must not be nil")
+				return err
+			}
+		}
+
+		opts.TxSigners = signers
+		return nil
+	}
+}
+
+// OptionEIP712 makes the client sign Txns with EIP-712 typed data and Web3Tx extension,
+// so the keys used by Web3 wallets can sign the same Txns. The feePayer is optional, when set
+// it pays the Tx fees. Only Ethereum keys are supported. In this mode, queued messages
+// are batched by type, since all messages of an EIP-712 Tx must be of the same type.
+func OptionEIP712(typedDataChainID uint64, feePayer TxSigner) cosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		opts.EIP712 = &EIP712Options{
+			TypedDataChainID: typedDataChainID,
+			FeePayer:         feePayer,
+		}
+
+		return nil
+	}
+}
+
+// OptionKeyDispatcher sets the way messages are dispatched across signing keys. By default,
+// messages go to the key that matches their signer, otherwise keys are picked round-robin.
+func OptionKeyDispatcher(dispatcher KeyDispatcher) cosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		if dispatcher == nil {
+			err := errors.New("key dispatcher must not be nil")
+			return err
+		}
+
+		opts.KeyDispatcher = dispatcher
+		return nil
+	}
+}
+
+// OptionNodes adds nodes to fail over to. Queries and broadcasts are routed to the healthiest node,
+// that is the one with the highest block among nodes not lagging behind by more than allowed.
+func OptionNodes(nodes ...NodeEndpoint) cosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		for _, node := range nodes {
+			if len(node.GRPCAddr) == 0 || len(node.TendermintRPCAddr) == 0 {
+				err := errors.New("node must have both gRPC and Tendermint RPC addresses")
+				return err
+			}
+		}
+
+		opts.Nodes = nodes
+		return nil
+	}
+}
+
+// OptionNodeHealthCheck sets how often nodes are checked and how many blocks a node
+// may lag behind the highest node and still be considered healthy.
+func OptionNodeHealthCheck(interval time.Duration, maxHeightLag int64) cosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		if interval <= 0 {
+			err := errors.New("health check interval must be positive")
+			return err
+		} else if maxHeightLag < 0 {
+			err := errors.New("max height lag must not be negative")
+			return err
+		}
+
+		opts.NodeHealthCheckInterval = interval
+		opts.NodeMaxHeightLag = maxHeightLag
+		return nil
+	}
+}
+
+// OptionDialOptions sets TLS and auth options for connections to all nodes. Tendermint RPC client
+// of the primary node comes from client context, see NewTendermintRPCClient to set it up the same way.
+func OptionDialOptions(options ...DialOption) cosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		opts.DialOptions = append(opts.DialOptions, options...)
+		return nil
+	}
+}
+
+// OptionNonceStore sets the store of account sequences and in-flight Txns. With a persistent
+// store, e.g. NewFileNonceStore, a restarted client skips sequences of its Txns still pending
+// in the mempool, instead of reusing them. The default store keeps nonces in memory.
+func OptionNonceStore(store NonceStore) cosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		if store == nil {
+			return errors.New("nonce store is nil")
+		}
+
+		opts.NonceStore = store
+		return nil
+	}
+}
+
+// OptionObserver sets the observer of broadcasts, message queues and gRPC calls to nodes.
+func OptionObserver(observer Observer) cosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		if observer == nil {
+			return errors.New("observer is nil")
+		}
+
+		opts.Observer = observer
+		opts.DialOptions = append(opts.DialOptions, DialOptionObserver(observer))
+		return nil
+	}
+}
+
+func (c *cosmosClient) syncNonce(key *signingKey) {
+	num, seq, err := key.txFactory.AccountRetriever().GetAccountNumberSequence(c.nodeCtx(key.ctx), key.address())
 	if err != nil {
 		c.logger.WithError(err).Errorln("failed to get account seq")
 		return
-	} else if num != c.accNum {
+	}
+
+	c.opts.Observer.ObserveSequenceResync(key.address())
+
+	if num != key.accNum {
 		c.logger.WithFields(log.Fields{
-			"expected": c.accNum,
+			"expected": key.accNum,
 			"actual":   num,
 		}).Panic("account number changed during nonce sync")
 	}
 
-	c.accSeq = seq
+	c.reconcileNonce(key, seq)
 }
 
 type cosmosClient struct {
 	ctx       client.Context
 	opts      *cosmosClientOptions
 	logger    log.Logger
-	conn      *grpc.ClientConn
+	nodes     *nodePool
 	txFactory tx.Factory
 
-	fromAddress sdk.AccAddress
-	doneC       chan bool
-	msgC        chan sdk.Msg
-	syncMux     *sync.Mutex
+	keys       []*signingKey
+	keyAddrs   []sdk.AccAddress
+	dispatcher KeyDispatcher
 
-	accNum uint64
-	accSeq uint64
+	gasEstimator *msgGasEstimator
+	feePayers    *feePayerNonces
+	chainQueries *ChainQueryClient
 
 	closed  int64
 	canSign bool
 }
 
+// QueryClient returns the gRPC connection of the current node. Get it for every
+// batch of queries instead of keeping it around, so queries follow node failover.
 func (c *cosmosClient) QueryClient() *grpc.ClientConn {
-	return c.conn
+	return c.nodes.Current().conn
 }
 
+// ChainQueries returns typed queries of chain modules. Queries follow node failover.
+func (c *cosmosClient) ChainQueries() *ChainQueryClient {
+	return c.chainQueries
+}
+
+// ClientContext returns client context bound to the current node.
 func (c *cosmosClient) ClientContext() client.Context {
-	return c.ctx
+	return c.nodeCtx(c.ctx)
+}
+
+// NodeStatuses returns the last known health of all nodes.
+func (c *cosmosClient) NodeStatuses() []NodeStatus {
+	return c.nodes.Statuses()
+}
+
+// MsgGasEstimates returns per message type gas estimates learned so far.
+func (c *cosmosClient) MsgGasEstimates() map[string]uint64 {
+	return c.gasEstimator.Estimates()
 }
 
 func (c *cosmosClient) CanSignTransactions() bool {
@@ -171,7 +542,31 @@ func (c *cosmosClient) FromAddress() sdk.AccAddress {
 		return sdk.AccAddress{}
 	}
 
-	return c.ctx.FromAddress
+	return c.keys[0].address()
+}
+
+// FromAddresses returns addresses of all signing keys, the first one is FromAddress.
+func (c *cosmosClient) FromAddresses() []sdk.AccAddress {
+	if !c.canSign {
+		return nil
+	}
+
+	return c.keyAddrs
+}
+
+// pickKey returns the signing key for msgs, as decided by the dispatcher.
+func (c *cosmosClient) pickKey(msgs ...sdk.Msg) *signingKey {
+	if len(c.keys) == 1 {
+		return c.keys[0]
+	}
+
+	idx := c.dispatcher(c.keyAddrs, msgs...)
+	if idx < 0 || idx >= len(c.keys) {
+		// misbehaving dispatcher, fallback to the default key
+		idx = 0
+	}
+
+	return c.keys[idx]
 }
 
 var (
@@ -182,28 +577,27 @@ var (
 
 // SyncBroadcastMsg sends Tx to chain and waits until Tx is included in block.
 func (c *cosmosClient) SyncBroadcastMsg(msgs ...sdk.Msg) (*sdk.TxResponse, error) {
-	c.syncMux.Lock()
-	defer c.syncMux.Unlock()
+	return c.SyncBroadcastMsgWithContext(context.Background(), msgs...)
+}
 
-	c.txFactory = c.txFactory.WithSequence(c.accSeq)
-	c.txFactory = c.txFactory.WithAccountNumber(c.accNum)
-	res, err := c.broadcastTx(c.ctx, c.txFactory, true, msgs...)
-	if err != nil {
-		if strings.Contains(err.Error(), "account sequence mismatch") {
-			c.syncNonce()
-			c.txFactory = c.txFactory.WithSequence(c.accSeq)
-			c.txFactory = c.txFactory.WithAccountNumber(c.accNum)
-			log.Debugln("retrying broadcastTx with nonce", c.accSeq)
-			res, err = c.broadcastTx(c.ctx, c.txFactory, true, msgs...)
-		}
-		if err != nil {
-			resJSON, _ := json.MarshalIndent(res, "", "\t")
-			c.logger.WithField("size", len(msgs)).WithError(err).Errorln("failed to commit msg batch:", string(resJSON))
-			return nil, err
-		}
+// SyncBroadcastMsgWithContext sends Tx to chain and waits until Tx is included in block,
+// or until ctx is done. If ctx has no deadline, the client's broadcast timeout is applied.
+// When the Tx has been accepted by the node but not yet seen in a block, the CheckTx response
+// is returned along with a *TxNotIncludedError that carries the Tx hash. Tx options carried by ctx,
+// see ContextWithTxOptions, are applied to the Tx.
+func (c *cosmosClient) SyncBroadcastMsgWithContext(ctx context.Context, msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+	if !c.canSign {
+		return nil, ErrReadOnly
 	}
 
-	c.accSeq++
+	res, err := c.broadcastWithKey(ctx, c.pickKey(msgs...), true, msgs...)
+	if IsTxNotIncluded(err) {
+		return res, err
+	} else if err != nil {
+		resJSON, _ := json.MarshalIndent(res, "", "\t")
+		c.logger.WithField("size", len(msgs)).WithError(err).Errorln("failed to commit msg batch:", string(resJSON))
+		return nil, err
+	}
 
 	return res, nil
 }
@@ -212,58 +606,142 @@ func (c *cosmosClient) SyncBroadcastMsg(msgs ...sdk.Msg) (*sdk.TxResponse, error
 // cannot be used for rapid Tx sending, it is expected that you wait for transaction status with
 // external tools. If you want sdk to wait for it, use SyncBroadcastMsg.
 func (c *cosmosClient) AsyncBroadcastMsg(msgs ...sdk.Msg) (*sdk.TxResponse, error) {
-	c.syncMux.Lock()
-	defer c.syncMux.Unlock()
+	return c.AsyncBroadcastMsgWithContext(context.Background(), msgs...)
+}
 
-	c.txFactory = c.txFactory.WithSequence(c.accSeq)
-	c.txFactory = c.txFactory.WithAccountNumber(c.accNum)
-	res, err := c.broadcastTx(c.ctx, c.txFactory, false, msgs...)
-	if err != nil {
-		if strings.Contains(err.Error(), "account sequence mismatch") {
-			c.syncNonce()
-			c.txFactory = c.txFactory.WithSequence(c.accSeq)
-			c.txFactory = c.txFactory.WithAccountNumber(c.accNum)
-			log.Debugln("retrying broadcastTx with nonce", c.accSeq)
-			res, err = c.broadcastTx(c.ctx, c.txFactory, false, msgs...)
-		}
-		if err != nil {
-			resJSON, _ := json.MarshalIndent(res, "", "\t")
-			c.logger.WithField("size", len(msgs)).WithError(err).Errorln("failed to commit msg batch:", string(resJSON))
-			return nil, err
-		}
+// AsyncBroadcastMsgWithContext is the same as AsyncBroadcastMsg, but aborts before
+// the Tx is sent to the node if ctx is already done. Tx options carried by ctx are applied to the Tx.
+func (c *cosmosClient) AsyncBroadcastMsgWithContext(ctx context.Context, msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+	if !c.canSign {
+		return nil, ErrReadOnly
 	}
 
-	c.accSeq++
+	res, err := c.broadcastWithKey(ctx, c.pickKey(msgs...), false, msgs...)
+	if err != nil {
+		resJSON, _ := json.MarshalIndent(res, "", "\t")
+		c.logger.WithField("size", len(msgs)).WithError(err).Errorln("failed to commit msg batch:", string(resJSON))
+		return nil, err
+	}
 
 	return res, nil
 }
 
+// broadcastWithKey signs msgs by the key using its current account sequence, and broadcasts the Tx.
+// The sequence is resynced and broadcast retried once, if the chain reports sequence mismatch.
+// If the node is unreachable, the Tx is retried once on the next healthy node with the same sequence,
+// since it has not been consumed. The sequence is incremented when the Tx has been accepted by the node.
+func (c *cosmosClient) broadcastWithKey(
+	ctx context.Context,
+	key *signingKey,
+	await bool,
+	msgs ...sdk.Msg,
+) (*sdk.TxResponse, error) {
+	key.syncMux.Lock()
+	defer key.syncMux.Unlock()
+
+	key.txFactory = key.txFactory.WithSequence(key.accSeq)
+	key.txFactory = key.txFactory.WithAccountNumber(key.accNum)
+	log.Debugln("broadcastTx with nonce", key.accSeq)
+	node := c.nodes.Current()
+	res, err := c.broadcastTx(ctx, c.nodeCtx(key.ctx), key, await, msgs...)
+	if err != nil && isTransportError(err) && c.nodes.Failover(node, err) {
+		log.WithError(err).Warningln("node is unreachable, retrying broadcastTx on another node")
+		res, err = c.broadcastTx(ctx, c.nodeCtx(key.ctx), key, await, msgs...)
+	}
+
+	if isSequenceMismatch(err) {
+		c.syncNonce(key)
+		key.txFactory = key.txFactory.WithSequence(key.accSeq)
+		key.txFactory = key.txFactory.WithAccountNumber(key.accNum)
+		log.Debugln("retrying broadcastTx with nonce", key.accSeq)
+		res, err = c.broadcastTx(ctx, c.nodeCtx(key.ctx), key, await, msgs...)
+	}
+
+	if err != nil && !IsTxNotIncluded(err) {
+		return res, err
+	}
+
+	// Tx is at least in the mempool, so its sequence is consumed
+	key.accSeq++
+	log.Debugln("nonce incremented to", key.accSeq)
+	c.saveNonces(key)
+
+	return res, err
+}
+
 const (
 	defaultBroadcastStatusPoll = 100 * time.Millisecond
 	defaultBroadcastTimeout    = 40 * time.Second
 )
 
+// broadcastTx signs msgs using the factory of the key and broadcasts the Tx. The Tx is recorded
+// as in-flight before it is sent, and forgotten once it is known to be included or rejected.
 func (c *cosmosClient) broadcastTx(
+	ctx context.Context,
 	clientCtx client.Context,
-	txf tx.Factory,
+	key *signingKey,
 	await bool,
 	msgs ...sdk.Msg,
-) (*sdk.TxResponse, error) {
+) (res *sdk.TxResponse, err error) {
+	if err := ctx.Err(); err != nil {
+		err = errors.Wrap(err, "broadcast aborted")
+		return nil, err
+	}
+
+	txOpts, err := c.txOptionsOf(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	txf, err := c.prepareFactory(clientCtx, txf)
+	signer := key.signer
+	txf, err := c.prepareFactory(clientCtx, key.txFactory)
 	if err != nil {
 		err = errors.Wrap(err, "failed to prepareFactory")
 		return nil, err
 	}
 
-	if txf.SimulateAndExecute() || clientCtx.Simulate {
-		_, adjusted, err := tx.CalculateGas(clientCtx, txf, msgs...)
+	txf, err = applyTxOptions(ctx, clientCtx, txf, txOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	eip712Opts := c.opts.EIP712
+	if eip712Opts != nil && txOpts.FeePayer != nil {
+		eip712Opts = &EIP712Options{
+			TypedDataChainID: eip712Opts.TypedDataChainID,
+			FeePayer:         txOpts.FeePayer,
+		}
+	}
+
+	// in EIP712 mode the fee payer signs the typed data, not as a Tx signer
+	var payer *feePayerAccount
+	if txOpts.FeePayer != nil && eip712Opts == nil {
+		if payer, err = c.feePayers.acquire(clientCtx, txf, txOpts.FeePayer); err != nil {
+			return nil, err
+		}
+
+		defer func() {
+			c.feePayers.release(payer, res, err)
+		}()
+	}
+
+	if txOpts.GasLimit > 0 {
+		txf = txf.WithGas(txOpts.GasLimit)
+	} else if txf.SimulateAndExecute() || clientCtx.Simulate {
+		simTxBytes, err := buildSimTx(clientCtx, txf, txOpts, payer, msgs...)
+		if err != nil {
+			err = errors.Wrap(err, "failed to BuildSimTx")
+			return nil, err
+		}
+
+		simRes, err := c.simulateTx(ctx, clientCtx, simTxBytes)
 		if err != nil {
-			err = errors.Wrap(err, "failed to CalculateGas")
+			err = errors.Wrap(err, "failed to simulate Tx")
 			return nil, err
 		}
 
-		txf = txf.WithGas(adjusted)
+		c.gasEstimator.Observe(msgs, simRes.GasInfo.GasUsed)
+		txf = txf.WithGas(uint64(txf.GasAdjustment() * float64(simRes.GasInfo.GasUsed)))
 	}
 
 	txn, err := tx.BuildUnsignedTx(txf, msgs...)
@@ -272,8 +750,18 @@ func (c *cosmosClient) broadcastTx(
 		return nil, err
 	}
 
-	txn.SetFeeGranter(clientCtx.GetFeeGranterAddress())
-	err = tx.Sign(txf, clientCtx.GetFromName(), txn, true)
+	if err := setFeeDelegation(clientCtx, txn, txOpts, payer); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case eip712Opts != nil:
+		err = signTxEIP712(clientCtx, txf, signer, txn, eip712Opts)
+	case payer != nil:
+		err = signTxWithFeePayer(clientCtx.TxConfig, txf, signer, payer, txn)
+	default:
+		err = signTx(clientCtx.TxConfig, txf, signer, txn, true)
+	}
 	if err != nil {
 		err = errors.Wrap(err, "failed to Sign Tx")
 		return nil, err
@@ -285,48 +773,136 @@ func (c *cosmosClient) broadcastTx(
 		return nil, err
 	}
 
+	txHash := fmt.Sprintf("%X", tmtypes.Tx(txBytes).Hash())
+	// persisted along with the consumed sequence, once the broadcast is done
+	key.trackInFlight(txf.Sequence(), txHash)
+
+	res, err = c.broadcastTxBytes(ctx, clientCtx, txBytes, await)
+	if res != nil && (res.Code != 0 || res.Height > 0) {
+		key.untrackInFlight(txHash)
+	}
+
+	return res, err
+}
+
+// BroadcastSignedTx broadcasts a Tx that has been built and signed elsewhere, e.g. offline.
+// If await is set, it waits for the Tx inclusion the same way SyncBroadcastMsgWithContext does.
+// The account sequences tracked by the client are not affected.
+func (c *cosmosClient) BroadcastSignedTx(ctx context.Context, txBytes []byte, await bool) (*sdk.TxResponse, error) {
+	if err := ctx.Err(); err != nil {
+		err = errors.Wrap(err, "broadcast aborted")
+		return nil, err
+	}
+
+	return c.broadcastTxBytes(ctx, c.nodeCtx(c.ctx), txBytes, await)
+}
+
+func (c *cosmosClient) broadcastTxBytes(
+	ctx context.Context,
+	clientCtx client.Context,
+	txBytes []byte,
+	await bool,
+) (*sdk.TxResponse, error) {
+	start := time.Now()
 	res, err := clientCtx.BroadcastTxSync(txBytes)
+	if err == nil && res.Code != 0 {
+		c.opts.Observer.ObserveBroadcast(time.Since(start), chainErrorOf(res))
+	} else {
+		c.opts.Observer.ObserveBroadcast(time.Since(start), err)
+	}
+
 	if !await || err != nil {
 		return res, err
 	}
 
-	awaitCtx, cancelFn := context.WithTimeout(context.Background(), defaultBroadcastTimeout)
-	defer cancelFn()
+	if res.Code != 0 {
+		// rejected by CheckTx, will never be included
+		err = errors.Wrap(chainErrorOf(res), "tx rejected")
+		return res, err
+	}
+
+	start = time.Now()
+
+	awaitCtx := ctx
+	if _, ok := ctx.Deadline(); !ok {
+		var cancelFn context.CancelFunc
+		awaitCtx, cancelFn = context.WithTimeout(ctx, c.opts.BroadcastTimeout)
+		defer cancelFn()
+	}
 
 	txHash, _ := hex.DecodeString(res.TxHash)
-	t := time.NewTimer(defaultBroadcastStatusPoll)
+	t := time.NewTimer(c.opts.BroadcastStatusPoll)
 
 	for {
 		select {
 		case <-awaitCtx.Done():
-			err := errors.Wrapf(ErrTimedOut, "%s", res.TxHash)
+			err := &TxNotIncludedError{
+				TxHash: res.TxHash,
+				Err:    awaitCtx.Err(),
+			}
 			t.Stop()
-			return nil, err
+			c.opts.Observer.ObserveInclusion(time.Since(start), err)
+			return res, err
 		case <-t.C:
 			resultTx, err := clientCtx.Client.Tx(awaitCtx, txHash, false)
 			if err != nil {
 				if errRes := client.CheckTendermintError(err, txBytes); errRes != nil {
+					c.opts.Observer.ObserveInclusion(time.Since(start), err)
 					return errRes, err
 				}
 
 				// log.WithError(err).Warningln("Tx Error for Hash:", res.TxHash)
 
-				t.Reset(defaultBroadcastStatusPoll)
+				t.Reset(c.opts.BroadcastStatusPoll)
 				continue
 
 			} else if resultTx.Height > 0 {
 				res = sdk.NewResponseResultTx(resultTx, res.Tx, res.Timestamp)
 				t.Stop()
+				if res.Code != 0 {
+					c.opts.Observer.ObserveInclusion(time.Since(start), chainErrorOf(res))
+				} else {
+					c.opts.Observer.ObserveInclusion(time.Since(start), nil)
+				}
+
 				return res, err
 			}
 
-			t.Reset(defaultBroadcastStatusPoll)
+			t.Reset(c.opts.BroadcastStatusPoll)
 		}
 	}
 }
 
 var ErrTimedOut = errors.New("tx timed out")
 
+// TxNotIncludedError is returned by the sync broadcast methods when the Tx has been
+// accepted by the node, but its inclusion in a block hasn't been observed before
+// the context was done. The Tx may still land in a later block.
+type TxNotIncludedError struct {
+	TxHash string
+	Err    error
+}
+
+func (e *TxNotIncludedError) Error() string {
+	return fmt.Sprintf("tx %s not yet included: %v", e.TxHash, e.Err)
+}
+
+func (e *TxNotIncludedError) Unwrap() error {
+	return e.Err
+}
+
+// Is allows errors.Is(err, ErrTimedOut) when the wait stopped due to a deadline.
+func (e *TxNotIncludedError) Is(target error) bool {
+	return target == ErrTimedOut && errors.Is(e.Err, context.DeadlineExceeded)
+}
+
+// IsTxNotIncluded checks whether the error indicates a Tx that has been broadcasted,
+// but not yet included in a block.
+func IsTxNotIncluded(err error) bool {
+	var notIncluded *TxNotIncludedError
+	return errors.As(err, &notIncluded)
+}
+
 // prepareFactory ensures the account defined by ctx.GetFromAddress() exists and
 // if the account number and/or the account sequence number are zero (not set),
 // they will be queried for and set on the provided Factory. A new Factory with
@@ -360,116 +936,168 @@ func (c *cosmosClient) prepareFactory(clientCtx client.Context, txf tx.Factory)
 // QueueBroadcastMsg enqueues a list of messages. Messages will added to the queue
 // and grouped into Txns in chunks. Use this method to mass broadcast Txns with efficiency.
 func (c *cosmosClient) QueueBroadcastMsg(msgs ...sdk.Msg) error {
+	_, err := c.queueMsgs(false, msgs...)
+	return err
+}
+
+// QueueBroadcastMsgWithResult enqueues a list of messages the same way as QueueBroadcastMsg does,
+// but returns a future per message that resolves to the delivery result of the Tx the message
+// ended up in. Messages that couldn't be enqueued have their futures resolved with the error.
+func (c *cosmosClient) QueueBroadcastMsgWithResult(msgs ...sdk.Msg) ([]*MsgResultFuture, error) {
+	return c.queueMsgs(true, msgs...)
+}
+
+func (c *cosmosClient) queueMsgs(withResult bool, msgs ...sdk.Msg) ([]*MsgResultFuture, error) {
 	if !c.canSign {
-		return ErrReadOnly
+		return nil, ErrReadOnly
 	} else if atomic.LoadInt64(&c.closed) == 1 {
-		return ErrQueueClosed
+		return nil, ErrQueueClosed
+	}
+
+	var futures []*MsgResultFuture
+	if withResult {
+		futures = make([]*MsgResultFuture, 0, len(msgs))
+		for range msgs {
+			futures = append(futures, newMsgResultFuture())
+		}
 	}
 
 	t := time.NewTimer(10 * time.Second)
-	for _, msg := range msgs {
+	for idx, msg := range msgs {
+		qm := &queuedMsg{
+			msg: msg,
+		}
+
+		if withResult {
+			qm.future = futures[idx]
+		}
+
+		key := c.pickKey(msg)
+
 		select {
 		case <-t.C:
-			return ErrEnqueueTimeout
-		case c.msgC <- msg:
+			if withResult {
+				for _, future := range futures[idx:] {
+					future.resolve(&MsgResult{
+						Err: ErrEnqueueTimeout,
+					})
+				}
+			}
+
+			return futures, ErrEnqueueTimeout
+		case key.msgC <- qm:
+			c.opts.Observer.ObserveQueueDepth(key.address(), len(key.msgC))
 		}
 	}
 	t.Stop()
 
-	return nil
+	return futures, nil
 }
 
 func (c *cosmosClient) Close() {
-	if !c.canSign {
+	if !atomic.CompareAndSwapInt64(&c.closed, 0, 1) {
 		return
 	}
 
-	if atomic.CompareAndSwapInt64(&c.closed, 0, 1) {
-		close(c.msgC)
+	for _, key := range c.keys {
+		close(key.msgC)
 	}
 
-	<-c.doneC
-
-	if c.conn != nil {
-		c.conn.Close()
+	for _, key := range c.keys {
+		<-key.doneC
 	}
+
+	c.nodes.Close()
 }
 
 const (
 	msgCommitBatchSizeLimit = 1024
 	msgCommitBatchTimeLimit = 500 * time.Millisecond
+	msgCommitBatchGasLimit  = 10000000
 )
 
-func (c *cosmosClient) runBatchBroadcast() {
-	expirationTimer := time.NewTimer(msgCommitBatchTimeLimit)
-	msgBatch := make([]sdk.Msg, 0, msgCommitBatchSizeLimit)
-
-	submitBatch := func(toSubmit []sdk.Msg) {
-		c.syncMux.Lock()
-		defer c.syncMux.Unlock()
-
-		c.txFactory = c.txFactory.WithSequence(c.accSeq)
-		c.txFactory = c.txFactory.WithAccountNumber(c.accNum)
-		log.Debugln("broadcastTx with nonce", c.accSeq)
-		res, err := c.broadcastTx(c.ctx, c.txFactory, true, toSubmit...)
-		if err != nil {
-			if strings.Contains(err.Error(), "account sequence mismatch") {
-				c.syncNonce()
-				c.txFactory = c.txFactory.WithSequence(c.accSeq)
-				c.txFactory = c.txFactory.WithAccountNumber(c.accNum)
-				log.Debugln("retrying broadcastTx with nonce", c.accSeq)
-				res, err = c.broadcastTx(c.ctx, c.txFactory, true, toSubmit...)
-			}
-			if err != nil {
-				resJSON, _ := json.MarshalIndent(res, "", "\t")
-				c.logger.WithField("size", len(toSubmit)).WithError(err).Errorln("failed to commit msg batch:", string(resJSON))
-				return
-			}
-		}
+func (c *cosmosClient) runBatchBroadcast(key *signingKey) {
+	expirationTimer := time.NewTimer(c.opts.BatchTimeLimit)
+	msgBatch := make([]*queuedMsg, 0, c.opts.BatchSizeLimit)
+	var batchGas uint64
 
-		if res.Code != 0 {
-			err = errors.Errorf("error %d (%s): %s", res.Code, res.Codespace, res.RawLog)
-			log.WithField("txHash", res.TxHash).WithError(err).Errorln("failed to commit msg batch")
-		} else {
-			log.WithField("txHash", res.TxHash).Debugln("msg batch committed successfully")
-		}
+	flushBatch := func() {
+		toSubmit := msgBatch
+		msgBatch = make([]*queuedMsg, 0, c.opts.BatchSizeLimit)
+		batchGas = 0
+		expirationTimer.Reset(c.opts.BatchTimeLimit)
 
-		c.accSeq++
-		log.Debugln("nonce incremented to", c.accSeq)
+		c.commitBatch(key, toSubmit)
 	}
 
 	for {
 		select {
-		case msg, ok := <-c.msgC:
+		case qm, ok := <-key.msgC:
 			if !ok {
 				// exit required
 				if len(msgBatch) > 0 {
-					submitBatch(msgBatch)
+					c.commitBatch(key, msgBatch)
 				}
 
-				close(c.doneC)
+				close(key.doneC)
 				return
 			}
 
-			msgBatch = append(msgBatch, msg)
+			c.opts.Observer.ObserveQueueDepth(key.address(), len(key.msgC))
+
+			if c.opts.EIP712 != nil && len(msgBatch) > 0 && sdk.MsgTypeURL(msgBatch[0].msg) != sdk.MsgTypeURL(qm.msg) {
+				// EIP712 Tx cannot mix message types
+				flushBatch()
+			}
+
+			msgGas := c.gasEstimator.Estimate(qm.msg)
+			if c.opts.BatchGasLimit > 0 && len(msgBatch) > 0 && batchGas+msgGas > c.opts.BatchGasLimit {
+				// the message won't fit into the current Tx
+				flushBatch()
+			}
 
-			if len(msgBatch) >= msgCommitBatchSizeLimit {
-				toSubmit := msgBatch
-				msgBatch = msgBatch[:0]
-				expirationTimer.Reset(msgCommitBatchTimeLimit)
+			msgBatch = append(msgBatch, qm)
+			batchGas += msgGas
 
-				submitBatch(toSubmit)
+			if len(msgBatch) >= c.opts.BatchSizeLimit {
+				flushBatch()
 			}
 		case <-expirationTimer.C:
 			if len(msgBatch) > 0 {
-				toSubmit := msgBatch
-				msgBatch = msgBatch[:0]
-				expirationTimer.Reset(msgCommitBatchTimeLimit)
-
-				submitBatch(toSubmit)
+				flushBatch()
 			} else {
-				expirationTimer.Reset(msgCommitBatchTimeLimit)
+				expirationTimer.Reset(c.opts.BatchTimeLimit)
 			}
 		}
 	}
 }
+
+// broadcastBatch sends the messages of the batch in one Tx signed by the key,
+// and waits for its inclusion.
+func (c *cosmosClient) broadcastBatch(key *signingKey, toSubmit []*queuedMsg) (*sdk.TxResponse, error) {
+	msgs := make([]sdk.Msg, 0, len(toSubmit))
+	for _, qm := range toSubmit {
+		msgs = append(msgs, qm.msg)
+	}
+
+	c.opts.Observer.ObserveBatchSize(len(msgs))
+
+	res, err := c.broadcastWithKey(context.Background(), key, true, msgs...)
+	if IsTxNotIncluded(err) {
+		c.logger.WithField("size", len(toSubmit)).WithError(err).Warningln("msg batch inclusion not confirmed")
+		return res, err
+	} else if err != nil {
+		resJSON, _ := json.MarshalIndent(res, "", "\t")
+		c.logger.WithField("size", len(toSubmit)).WithError(err).Errorln("failed to commit msg batch:", string(resJSON))
+		return res, err
+	}
+
+	if res.Code != 0 {
+		err = chainErrorOf(res)
+		log.WithField("txHash", res.TxHash).WithError(err).Errorln("failed to commit msg batch")
+	} else {
+		log.WithField("txHash", res.TxHash).Debugln("msg batch committed successfully")
+	}
+
+	return res, nil
+}
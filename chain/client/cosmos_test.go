@@ -0,0 +1,114 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/pkg/errors"
+	"github.com/tendermint/tendermint/libs/bytes"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	log "github.com/xlab/suplog"
+
+	"github.com/InjectiveLabs/sdk-go/chain/crypto/ethsecp256k1"
+)
+
+// mempoolOnlyClient accepts every Tx into the mempool, but never includes it in a block.
+type mempoolOnlyClient struct {
+	rpcclient.Client
+
+	// onTx is called on every Tx inclusion check
+	onTx func()
+}
+
+func (c *mempoolOnlyClient) BroadcastTxSync(_ context.Context, txBytes tmtypes.Tx) (*ctypes.ResultBroadcastTx, error) {
+	return &ctypes.ResultBroadcastTx{Hash: bytes.HexBytes(txBytes.Hash())}, nil
+}
+
+func (c *mempoolOnlyClient) Tx(_ context.Context, hash []byte, _ bool) (*ctypes.ResultTx, error) {
+	if c.onTx != nil {
+		c.onTx()
+	}
+
+	return nil, errors.Errorf("tx (%X) not found", hash)
+}
+
+func newTestBroadcastClient(t *testing.T, tmClient rpcclient.Client) (*cosmosClient, *signingKey) {
+	privKey, err := ethsecp256k1.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientCtx, err := NewClientContext("injective-888", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer := NewPrivKeySigner(privKey)
+	clientCtx = clientCtx.WithFromAddress(signer.Address())
+
+	opts := defaultCosmosClientOptions()
+	opts.BroadcastTimeout = 50 * time.Millisecond
+	opts.BroadcastStatusPoll = time.Millisecond
+
+	txf := NewTxFactory(clientCtx).WithAccountRetriever(&sequenceRetriever{seq: 1})
+	key := newSigningKey(clientCtx, txf, signer, 1)
+	key.accNum = 3
+	key.accSeq = 1
+
+	c := &cosmosClient{
+		opts:      opts,
+		logger:    log.DefaultLogger,
+		nodes:     newNodePool([]*chainNode{{tmClient: tmClient}}, time.Second, 3, log.DefaultLogger),
+		keys:      []*signingKey{key},
+		keyAddrs:  []sdk.AccAddress{key.address()},
+		feePayers: newFeePayerNonces(),
+		canSign:   true,
+	}
+
+	return c, key
+}
+
+func TestSyncBroadcastNotIncluded(t *testing.T) {
+	tmClient := &mempoolOnlyClient{}
+	c, key := newTestBroadcastClient(t, tmClient)
+
+	from := key.address()
+	msg := banktypes.NewMsgSend(from, from, sdk.NewCoins(sdk.NewInt64Coin("inj", 1)))
+	txCtx := ContextWithTxOptions(context.Background(), TxOptionGasLimit(200000))
+
+	// the client's broadcast timeout applies to a ctx without deadline
+	res, err := c.SyncBroadcastMsgWithContext(txCtx, msg)
+	var notIncluded *TxNotIncludedError
+	if !errors.As(err, &notIncluded) || !IsTxNotIncluded(err) {
+		t.Fatalf("expected Tx not included error, got %v", err)
+	} else if !errors.Is(err, ErrTimedOut) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the wait to time out, got %v", err)
+	} else if res == nil || len(res.TxHash) == 0 || notIncluded.TxHash != res.TxHash {
+		t.Fatalf("expected the CheckTx response along with the Tx hash, got %v", res)
+	} else if key.accSeq != 2 {
+		t.Fatalf("expected the sequence of the Tx in the mempool to be consumed, got next %d", key.accSeq)
+	}
+
+	// cancellation stops waiting as well, but is not a timeout
+	ctx, cancelFn := context.WithCancel(txCtx)
+	defer cancelFn()
+
+	tmClient.onTx = cancelFn
+	res, err = c.SyncBroadcastMsgWithContext(ctx, msg)
+	if !IsTxNotIncluded(err) || !errors.Is(err, context.Canceled) || errors.Is(err, ErrTimedOut) {
+		t.Fatalf("expected Tx not included error due to cancellation, got %v", err)
+	} else if res == nil || key.accSeq != 3 {
+		t.Fatalf("expected the CheckTx response and next sequence 3, got %v and %d", res, key.accSeq)
+	}
+
+	if IsTxNotIncluded(errors.New("tx rejected")) || IsTxNotIncluded(nil) {
+		t.Fatal("expected other errors not to be reported as Tx not included")
+	} else if !IsTxNotIncluded(errors.Wrap(err, "failed to broadcast")) {
+		t.Fatal("expected wrapped Tx not included error to be recognized")
+	}
+}
@@ -0,0 +1,231 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+	abci "github.com/tendermint/tendermint/abci/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+
+	"github.com/InjectiveLabs/sdk-go/chain/client/tmclient"
+)
+
+// DecodedBlock is a block with all its Txns decoded.
+type DecodedBlock struct {
+	Height int64
+	Hash   string
+	Time   time.Time
+	Txs    []*DecodedTx
+}
+
+// DecodedTx is a Tx decoded along with its execution result.
+type DecodedTx struct {
+	Hash   string
+	Height int64
+	Index  int
+
+	Msgs          []sdk.Msg
+	Signers       []sdk.AccAddress
+	Fee           sdk.Coins
+	GasLimit      uint64
+	FeePayer      sdk.AccAddress
+	FeeGranter    sdk.AccAddress
+	Memo          string
+	TimeoutHeight uint64
+
+	Code      uint32
+	Codespace string
+	Log       string
+	GasWanted int64
+	GasUsed   int64
+	Events    sdk.StringEvents
+	// TypedEvents are events decoded into their proto types, e.g. exchange module's EventBatchSpotExecution.
+	TypedEvents []proto.Message
+
+	// DecodeErr is set if the Tx bytes could not be decoded, e.g. the Tx is not a Cosmos Tx.
+	// The execution result is still filled in.
+	DecodeErr error
+}
+
+// TxDecoder decodes Txns of blocks into messages, using the interface registry of client context.
+type TxDecoder struct {
+	clientCtx client.Context
+	tmClient  tmclient.TendermintClient
+}
+
+// NewTxDecoder creates a decoder. The client context must have TxConfig and JSONCodec set,
+// as done by NewClientContext.
+func NewTxDecoder(clientCtx client.Context, tmClient tmclient.TendermintClient) *TxDecoder {
+	return &TxDecoder{
+		clientCtx: clientCtx,
+		tmClient:  tmClient,
+	}
+}
+
+// DecodeBlock fetches the block at height along with its results and decodes all Txns.
+func (d *TxDecoder) DecodeBlock(ctx context.Context, height int64) (*DecodedBlock, error) {
+	block, err := d.tmClient.GetBlock(ctx, height)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to get block %d", height)
+		return nil, err
+	}
+
+	results, err := d.tmClient.GetBlockResults(ctx, height)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to get block results %d", height)
+		return nil, err
+	}
+
+	decodedBlock := &DecodedBlock{
+		Height: block.Block.Height,
+		Hash:   block.BlockID.Hash.String(),
+		Time:   block.Block.Time,
+		Txs:    make([]*DecodedTx, 0, len(block.Block.Txs)),
+	}
+
+	for idx, txBytes := range block.Block.Txs {
+		var result *abci.ResponseDeliverTx
+		if idx < len(results.TxsResults) {
+			result = results.TxsResults[idx]
+		}
+
+		decodedTx := d.DecodeTx(txBytes, result)
+		decodedTx.Height = block.Block.Height
+		decodedTx.Index = idx
+
+		decodedBlock.Txs = append(decodedBlock.Txs, decodedTx)
+	}
+
+	return decodedBlock, nil
+}
+
+// DecodeTx decodes Tx bytes and its result, which may be nil if not known.
+// Height and index are left for the caller to fill in.
+func (d *TxDecoder) DecodeTx(txBytes []byte, result *abci.ResponseDeliverTx) *DecodedTx {
+	decodedTx := &DecodedTx{
+		Hash: fmt.Sprintf("%X", tmtypes.Tx(txBytes).Hash()),
+	}
+
+	if result != nil {
+		decodedTx.Code = result.Code
+		decodedTx.Codespace = result.Codespace
+		decodedTx.Log = result.Log
+		decodedTx.GasWanted = result.GasWanted
+		decodedTx.GasUsed = result.GasUsed
+		decodedTx.Events = sdk.StringifyEvents(result.Events)
+		decodedTx.TypedEvents = parseTypedEvents(result.Events)
+	}
+
+	txn, err := d.clientCtx.TxConfig.TxDecoder()(txBytes)
+	if err != nil {
+		decodedTx.DecodeErr = errors.Wrap(err, "failed to decode Tx")
+		return decodedTx
+	}
+
+	decodedTx.Msgs = txn.GetMsgs()
+
+	if sigTx, ok := txn.(authsigning.Tx); ok {
+		decodedTx.Signers = sigTx.GetSigners()
+		decodedTx.Fee = sigTx.GetFee()
+		decodedTx.GasLimit = sigTx.GetGas()
+		decodedTx.FeePayer = sigTx.FeePayer()
+		decodedTx.FeeGranter = sigTx.FeeGranter()
+		decodedTx.Memo = sigTx.GetMemo()
+		decodedTx.TimeoutHeight = sigTx.GetTimeoutHeight()
+	}
+
+	return decodedTx
+}
+
+// MarshalBlockJSON renders the decoded block as JSON, for debugging.
+func (d *TxDecoder) MarshalBlockJSON(block *DecodedBlock) ([]byte, error) {
+	txs := make([]json.RawMessage, 0, len(block.Txs))
+	for _, decodedTx := range block.Txs {
+		txJSON, err := d.MarshalTxJSON(decodedTx)
+		if err != nil {
+			return nil, err
+		}
+
+		txs = append(txs, txJSON)
+	}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"height": block.Height,
+		"hash":   block.Hash,
+		"time":   block.Time,
+		"txs":    txs,
+	}, "", "  ")
+}
+
+// MarshalTxJSON renders the decoded Tx as JSON, for debugging. Messages and typed events
+// are rendered with their proto type URLs.
+func (d *TxDecoder) MarshalTxJSON(decodedTx *DecodedTx) ([]byte, error) {
+	msgs := make([]json.RawMessage, 0, len(decodedTx.Msgs))
+	for _, msg := range decodedTx.Msgs {
+		msgJSON, err := d.clientCtx.JSONCodec.MarshalInterfaceJSON(msg)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to marshal %s", sdk.MsgTypeURL(msg))
+			return nil, err
+		}
+
+		msgs = append(msgs, msgJSON)
+	}
+
+	typedEvents := make([]map[string]json.RawMessage, 0, len(decodedTx.TypedEvents))
+	for _, ev := range decodedTx.TypedEvents {
+		evJSON, err := d.clientCtx.JSONCodec.MarshalJSON(ev)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to marshal %s", proto.MessageName(ev))
+			return nil, err
+		}
+
+		typedEvents = append(typedEvents, map[string]json.RawMessage{
+			proto.MessageName(ev): evJSON,
+		})
+	}
+
+	signers := make([]string, 0, len(decodedTx.Signers))
+	for _, signer := range decodedTx.Signers {
+		signers = append(signers, signer.String())
+	}
+
+	txJSON := map[string]interface{}{
+		"hash":           decodedTx.Hash,
+		"height":         decodedTx.Height,
+		"index":          decodedTx.Index,
+		"msgs":           msgs,
+		"signers":        signers,
+		"fee":            decodedTx.Fee.String(),
+		"gas_limit":      decodedTx.GasLimit,
+		"memo":           decodedTx.Memo,
+		"timeout_height": decodedTx.TimeoutHeight,
+		"code":           decodedTx.Code,
+		"codespace":      decodedTx.Codespace,
+		"log":            decodedTx.Log,
+		"gas_wanted":     decodedTx.GasWanted,
+		"gas_used":       decodedTx.GasUsed,
+		"events":         decodedTx.Events,
+		"typed_events":   typedEvents,
+	}
+
+	if !decodedTx.FeePayer.Empty() {
+		txJSON["fee_payer"] = decodedTx.FeePayer.String()
+	}
+
+	if !decodedTx.FeeGranter.Empty() {
+		txJSON["fee_granter"] = decodedTx.FeeGranter.String()
+	}
+
+	if decodedTx.DecodeErr != nil {
+		txJSON["decode_error"] = decodedTx.DecodeErr.Error()
+	}
+
+	return json.MarshalIndent(txJSON, "", "  ")
+}
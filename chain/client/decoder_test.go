@@ -0,0 +1,77 @@
+package client
+
+import (
+	"bytes"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	abci "github.com/tendermint/tendermint/abci/types"
+
+	exchange "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+)
+
+func TestDecodeTx(t *testing.T) {
+	signer := NewPrivKeySigner(mustGenerateKey(t))
+
+	clientCtx, err := NewClientContext("injective-888", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from := signer.Address()
+	msg := &exchange.MsgDeposit{
+		Sender:       from.String(),
+		SubaccountId: "0x01",
+		Amount:       sdk.NewInt64Coin("inj", 1),
+	}
+
+	params := OfflineTxParams{
+		AccountNumber: 1,
+		Sequence:      2,
+		GasLimit:      200000,
+		Fees:          "100000000000000inj",
+		Memo:          "decode me",
+	}
+
+	txBuilder, err := BuildOfflineTx(clientCtx, params, msg)
+	if err != nil {
+		t.Fatal(err)
+	} else if err := SignOfflineTxWithSigner(clientCtx, params, txBuilder, signer, true); err != nil {
+		t.Fatal(err)
+	}
+
+	txBytes, err := ExportTxBytes(clientCtx, txBuilder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoder := NewTxDecoder(clientCtx, nil)
+	decodedTx := decoder.DecodeTx(txBytes, &abci.ResponseDeliverTx{
+		Code:      5,
+		Codespace: "sdk",
+		Log:       "insufficient funds",
+	})
+
+	if decodedTx.DecodeErr != nil {
+		t.Fatal(decodedTx.DecodeErr)
+	} else if len(decodedTx.Msgs) != 1 {
+		t.Fatalf("expected 1 msg, got %d", len(decodedTx.Msgs))
+	} else if _, ok := decodedTx.Msgs[0].(*exchange.MsgDeposit); !ok {
+		t.Fatalf("unexpected msg type %T", decodedTx.Msgs[0])
+	} else if len(decodedTx.Signers) != 1 || !decodedTx.Signers[0].Equals(from) {
+		t.Fatalf("unexpected signers %v", decodedTx.Signers)
+	} else if decodedTx.Memo != params.Memo || decodedTx.Fee.String() != params.Fees || decodedTx.Code != 5 {
+		t.Fatal("decoded Tx doesn't match")
+	}
+
+	txJSON, err := decoder.MarshalTxJSON(decodedTx)
+	if err != nil {
+		t.Fatal(err)
+	} else if !bytes.Contains(txJSON, []byte(sdk.MsgTypeURL(msg))) {
+		t.Fatalf("expected msg type URL in JSON: %s", txJSON)
+	}
+
+	if garbage := decoder.DecodeTx([]byte("garbage"), nil); garbage.DecodeErr == nil {
+		t.Fatal("expected decode error")
+	}
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/cosmos/cosmos-sdk/x/auth/legacy/legacytx"
+	authtx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+	"github.com/pkg/errors"
+
+	injsdk "github.com/InjectiveLabs/sdk-go"
+	chaintypes "github.com/InjectiveLabs/sdk-go/chain/types"
+	"github.com/InjectiveLabs/sdk-go/typeddata"
+)
+
+// EIP712Options enable signing Txns with EIP-712 typed data, the same way Web3 wallets
+// like MetaMask do. Such Txns carry the Web3Tx extension option.
+type EIP712Options struct {
+	// TypedDataChainID is the Ethereum chain ID used in EIP-712 domain,
+	// it must match the network ID of a Web3 provider.
+	TypedDataChainID uint64
+	// FeePayer is an optional signer paying fees on behalf of the Tx signer.
+	FeePayer TxSigner
+}
+
+// signTxEIP712 signs the Tx with EIP-712 typed data wrapping the Amino JSON sign doc,
+// and attaches the Web3Tx extension option. The signer must use an Ethereum key, since the
+// signature is made over the keccak hash of the typed data. All Tx messages must be of the same type.
+func signTxEIP712(
+	clientCtx client.Context,
+	txf tx.Factory,
+	signer TxSigner,
+	txBuilder client.TxBuilder,
+	opts *EIP712Options,
+) error {
+	extBuilder, ok := txBuilder.(authtx.ExtensionOptionsTxBuilder)
+	if !ok {
+		err := errors.Errorf("tx builder %T doesn't support extension options", txBuilder)
+		return err
+	}
+
+	txn := txBuilder.GetTx()
+	msgs := txn.GetMsgs()
+	if len(msgs) == 0 {
+		err := errors.New("no messages to sign")
+		return err
+	}
+
+	msgType := sdk.MsgTypeURL(msgs[0])
+	for _, msg := range msgs[1:] {
+		if sdk.MsgTypeURL(msg) != msgType {
+			err := errors.Errorf("EIP712 Tx messages must be of the same type, got %s and %s", msgType, sdk.MsgTypeURL(msg))
+			return err
+		}
+	}
+
+	signDoc := legacytx.StdSignBytes(
+		txf.ChainID(),
+		txf.AccountNumber(),
+		txf.Sequence(),
+		txn.GetTimeoutHeight(),
+		legacytx.StdFee{
+			Amount: txn.GetFee(),
+			Gas:    txn.GetGas(),
+		},
+		msgs,
+		txn.GetMemo(),
+	)
+
+	var feeDelegation *injsdk.FeeDelegationOptions
+	if opts.FeePayer != nil {
+		feeDelegation = &injsdk.FeeDelegationOptions{
+			FeePayer: opts.FeePayer.Address(),
+		}
+	}
+
+	typedData, err := injsdk.WrapTxToEIP712(clientCtx.InterfaceRegistry, opts.TypedDataChainID, msgs[0], signDoc, feeDelegation)
+	if err != nil {
+		err = errors.Wrap(err, "failed to wrap Tx into EIP712")
+		return err
+	}
+
+	// Ethereum keys sign keccak hash of the bytes, which gives the EIP712 digest
+	signBytes, err := typeddata.ComputeTypedDataSignBytes(typedData)
+	if err != nil {
+		err = errors.Wrap(err, "failed to encode EIP712 typed data")
+		return err
+	}
+
+	sig, sigPubKey, err := signer.Sign(signBytes)
+	if err != nil {
+		err = errors.Wrap(err, "failed to sign EIP712 typed data")
+		return err
+	} else if !sigPubKey.Equals(signer.PubKey()) {
+		err = errors.New("signer used an unexpected key")
+		return err
+	}
+
+	web3Ext := &chaintypes.ExtensionOptionsWeb3Tx{
+		TypedDataChainID: opts.TypedDataChainID,
+	}
+
+	if opts.FeePayer != nil {
+		feePayerSig, _, err := opts.FeePayer.Sign(signBytes)
+		if err != nil {
+			err = errors.Wrap(err, "failed to sign EIP712 typed data by fee payer")
+			return err
+		}
+
+		web3Ext.FeePayer = opts.FeePayer.Address().String()
+		web3Ext.FeePayerSig = feePayerSig
+	}
+
+	extAny, err := codectypes.NewAnyWithValue(web3Ext)
+	if err != nil {
+		err = errors.Wrap(err, "failed to pack Web3Tx extension")
+		return err
+	}
+
+	extBuilder.SetExtensionOptions(extAny)
+
+	sigV2 := signing.SignatureV2{
+		PubKey: signer.PubKey(),
+		Data: &signing.SingleSignatureData{
+			SignMode:  signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON,
+			Signature: sig,
+		},
+		Sequence: txf.Sequence(),
+	}
+
+	return txBuilder.SetSignatures(sigV2)
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/cosmos/cosmos-sdk/x/auth/legacy/legacytx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/ethereum/go-ethereum/crypto/secp256k1"
+
+	injsdk "github.com/InjectiveLabs/sdk-go"
+	"github.com/InjectiveLabs/sdk-go/typeddata"
+)
+
+func TestSignTxEIP712(t *testing.T) {
+	signer := NewPrivKeySigner(mustGenerateKey(t))
+	feePayer := NewPrivKeySigner(mustGenerateKey(t))
+
+	clientCtx, err := NewClientContext("injective-888", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from := signer.Address()
+	msg := banktypes.NewMsgSend(from, from, sdk.NewCoins(sdk.NewInt64Coin("inj", 1)))
+	params := OfflineTxParams{
+		AccountNumber: 3,
+		Sequence:      5,
+		GasLimit:      200000,
+		Fees:          "100000000000000inj",
+	}
+
+	txBuilder, err := BuildOfflineTx(clientCtx, params, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := EIP712Options{
+		TypedDataChainID: 888,
+		FeePayer:         feePayer,
+	}
+	if err := SignOfflineTxEIP712(clientCtx, params, txBuilder, signer, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	txn := txBuilder.GetTx()
+	signDoc := legacytx.StdSignBytes(
+		clientCtx.ChainID, params.AccountNumber, params.Sequence, 0,
+		legacytx.StdFee{Amount: txn.GetFee(), Gas: txn.GetGas()},
+		txn.GetMsgs(), txn.GetMemo(),
+	)
+
+	typedData, err := injsdk.WrapTxToEIP712(clientCtx.InterfaceRegistry, 888, msg, signDoc, &injsdk.FeeDelegationOptions{
+		FeePayer: feePayer.Address(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sigHash, err := typeddata.ComputeTypedDataHash(typedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sigs, err := txn.GetSignaturesV2()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(sigs) != 1 {
+		t.Fatalf("expected 1 signature, got %d", len(sigs))
+	}
+
+	sigData := sigs[0].Data.(*signing.SingleSignatureData)
+	if sigData.SignMode != signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON {
+		t.Fatalf("unexpected sign mode %s", sigData.SignMode)
+	}
+
+	if !secp256k1.VerifySignature(signer.PubKey().Bytes(), sigHash, sigData.Signature[:64]) {
+		t.Fatal("signature doesn't match EIP712 typed data hash")
+	}
+}
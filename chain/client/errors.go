@@ -0,0 +1,274 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/pkg/errors"
+
+	auctiontypes "github.com/InjectiveLabs/sdk-go/chain/auction/types"
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+	insurancetypes "github.com/InjectiveLabs/sdk-go/chain/insurance/types"
+	oracletypes "github.com/InjectiveLabs/sdk-go/chain/oracle/types"
+	peggytypes "github.com/InjectiveLabs/sdk-go/chain/peggy/types"
+)
+
+// Sentinel errors that group chain failures by their cause, regardless of the module
+// that reported them. Test errors returned by broadcast and simulation with errors.Is.
+var (
+	ErrSequenceMismatch  = errors.New("account sequence mismatch")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrInsufficientFee   = errors.New("insufficient fee")
+	ErrOutOfGas          = errors.New("out of gas")
+	ErrMempoolFull       = errors.New("mempool is full")
+	ErrUnauthorized      = errors.New("unauthorized")
+	ErrTickSizeBreach    = errors.New("tick size breach")
+	ErrMarketNotFound    = errors.New("market not found")
+	ErrOrderNotFound     = errors.New("order not found")
+)
+
+var chainErrorClasses = []struct {
+	sentinel error
+	errs     []*sdkerrors.Error
+	// logContains narrows the match down, when a registered error covers several failures.
+	logContains string
+}{
+	{
+		sentinel: ErrSequenceMismatch,
+		errs:     []*sdkerrors.Error{sdkerrors.ErrWrongSequence},
+	},
+	{
+		sentinel: ErrInsufficientFunds,
+		errs: []*sdkerrors.Error{
+			sdkerrors.ErrInsufficientFunds,
+			exchangetypes.ErrInsufficientDeposit,
+			exchangetypes.ErrInsufficientOrderMargin,
+		},
+	},
+	{
+		sentinel: ErrInsufficientFee,
+		errs:     []*sdkerrors.Error{sdkerrors.ErrInsufficientFee},
+	},
+	{
+		sentinel: ErrOutOfGas,
+		errs:     []*sdkerrors.Error{sdkerrors.ErrOutOfGas},
+	},
+	{
+		sentinel: ErrMempoolFull,
+		errs:     []*sdkerrors.Error{sdkerrors.ErrMempoolIsFull},
+	},
+	{
+		sentinel: ErrUnauthorized,
+		errs: []*sdkerrors.Error{
+			sdkerrors.ErrUnauthorized,
+			oracletypes.ErrRelayerNotAuthorized,
+		},
+	},
+	{
+		sentinel: ErrTickSizeBreach,
+		errs: []*sdkerrors.Error{
+			exchangetypes.ErrInvalidPrice,
+			exchangetypes.ErrInvalidQuantity,
+			exchangetypes.ErrInvalidMargin,
+		},
+		logContains: "tick size",
+	},
+	{
+		sentinel: ErrMarketNotFound,
+		errs: []*sdkerrors.Error{
+			exchangetypes.ErrSpotMarketNotFound,
+			exchangetypes.ErrDerivativeMarketNotFound,
+		},
+	},
+	{
+		sentinel: ErrOrderNotFound,
+		errs:     []*sdkerrors.Error{exchangetypes.ErrOrderDoesntExist},
+	},
+}
+
+// retryableErrors are the failures that may go away when the Tx is rebuilt and resubmitted later.
+var retryableErrors = []*sdkerrors.Error{
+	// the sequence is resynced before retry
+	sdkerrors.ErrWrongSequence,
+	sdkerrors.ErrMempoolIsFull,
+	sdkerrors.ErrTxTimeoutHeight,
+	// a rebuilt Tx gets a fresh gas estimate
+	sdkerrors.ErrOutOfGas,
+	// only one market order per subaccount and market is allowed in a block
+	exchangetypes.ErrDerivativeMarketOrderAlreadyExists,
+	exchangetypes.ErrDerivativeLimitOrderAlreadyExists,
+	// claims are accepted once the previous ones have been processed
+	peggytypes.ErrNonContiguousEventNonce,
+}
+
+// queryErrorCodespaces are the codespaces of the errors recovered from the log of a failed query,
+// see newQueryChainError. Registered codes of these codespaces are below maxQueryErrorCode.
+var queryErrorCodespaces = []string{
+	sdkerrors.RootCodespace,
+	exchangetypes.ModuleName,
+	oracletypes.ModuleName,
+	peggytypes.ModuleName,
+	insurancetypes.ModuleName,
+	auctiontypes.ModuleName,
+}
+
+const maxQueryErrorCode = 256
+
+// registeredErrors are the errors registered in queryErrorCodespaces.
+var registeredErrors = func() []*sdkerrors.Error {
+	var errs []*sdkerrors.Error
+	for _, codespace := range queryErrorCodespaces {
+		for code := uint32(1); code < maxQueryErrorCode; code++ {
+			// codes that are not registered get an unknown error
+			err, ok := errors.Cause(sdkerrors.ABCIError(codespace, code, "")).(*sdkerrors.Error)
+			if ok && err.Error() != "unknown" {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errs
+}()
+
+// ChainError is a failure reported by the chain for a Tx, identified by codespace and code.
+// It unwraps to the error registered by the module, so both errors.Is(err, exchangetypes.ErrSpotMarketNotFound)
+// and errors.Is(err, ErrMarketNotFound) work.
+type ChainError struct {
+	Codespace string
+	Code      uint32
+	Log       string
+	// TxHash is set when the Tx has been broadcasted.
+	TxHash string
+}
+
+// NewChainError creates an error from the codespace, code and log of a Tx result.
+func NewChainError(codespace string, code uint32, log string) *ChainError {
+	return &ChainError{
+		Codespace: codespace,
+		Code:      code,
+		Log:       log,
+	}
+}
+
+// newQueryChainError creates an error from the codespace, code and log of a failed ABCI query.
+// The node reports any error of a gRPC service queried over ABCI, such as Tx simulation, as an invalid
+// request, with the original error in the log. Codespace and code of known errors are recovered from the log,
+// other errors keep the invalid request code, their log is still matched by errors.Is.
+func newQueryChainError(codespace string, code uint32, log string) *ChainError {
+	wrappedLog, ok := queryErrorLog(codespace, code, log)
+	if !ok {
+		return NewChainError(codespace, code, log)
+	}
+
+	var found *sdkerrors.Error
+	for _, err := range registeredErrors {
+		// the longest description is the most specific one
+		if logMatches(wrappedLog, err) && (found == nil || len(err.Error()) > len(found.Error())) {
+			found = err
+		}
+	}
+
+	if found == nil {
+		return NewChainError(codespace, code, log)
+	}
+
+	return NewChainError(found.Codespace(), found.ABCICode(), wrappedLog)
+}
+
+// queryErrorLog returns the log of the error wrapped by a failed query, that is reported
+// as an invalid request with the "<log>: invalid request" log.
+func queryErrorLog(codespace string, code uint32, log string) (string, bool) {
+	if codespace != sdkerrors.ErrInvalidRequest.Codespace() || code != sdkerrors.ErrInvalidRequest.ABCICode() {
+		return "", false
+	}
+
+	suffix := ": " + sdkerrors.ErrInvalidRequest.Error()
+	if !strings.HasSuffix(log, suffix) {
+		return "", false
+	}
+
+	return strings.TrimSuffix(log, suffix), true
+}
+
+// logMatches checks whether log is the one of err, as is or wrapped.
+func logMatches(log string, err *sdkerrors.Error) bool {
+	desc := err.Error()
+	return log == desc || strings.HasSuffix(log, ": "+desc)
+}
+
+// chainErrorOf returns the error of a failed Tx result.
+func chainErrorOf(res *sdk.TxResponse) *ChainError {
+	return &ChainError{
+		Codespace: res.Codespace,
+		Code:      res.Code,
+		Log:       res.RawLog,
+		TxHash:    res.TxHash,
+	}
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("error %d (%s): %s", e.Code, e.Codespace, e.Log)
+}
+
+// Unwrap returns the registered error with the same codespace and code.
+func (e *ChainError) Unwrap() error {
+	return errors.Cause(sdkerrors.ABCIError(e.Codespace, e.Code, e.Log))
+}
+
+// Is matches the sentinel errors of this package.
+func (e *ChainError) Is(target error) bool {
+	for _, class := range chainErrorClasses {
+		if class.sentinel != target {
+			continue
+		}
+
+		if len(class.logContains) > 0 && !strings.Contains(e.Log, class.logContains) {
+			return false
+		}
+
+		return e.matches(class.errs)
+	}
+
+	return false
+}
+
+// Retryable tells whether the Tx may succeed if rebuilt and resubmitted later, as opposed
+// to failures that require changing the messages.
+func (e *ChainError) Retryable() bool {
+	return e.matches(retryableErrors)
+}
+
+func (e *ChainError) matches(errs []*sdkerrors.Error) bool {
+	// errors of failed queries are matched by their log
+	wrappedLog, isQueryError := queryErrorLog(e.Codespace, e.Code, e.Log)
+
+	for _, err := range errs {
+		if e.Codespace == err.Codespace() && e.Code == err.ABCICode() {
+			return true
+		} else if isQueryError && logMatches(wrappedLog, err) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsRetryable checks whether a failed broadcast may succeed if retried: either the chain reported
+// a retryable error, or the node was unreachable. Txns that have been broadcasted, but
+// not yet included, are not retryable, since they may still land.
+func IsRetryable(err error) bool {
+	var chainErr *ChainError
+	if errors.As(err, &chainErr) {
+		return chainErr.Retryable()
+	}
+
+	return !IsTxNotIncluded(err) && isTransportError(err)
+}
+
+// isSequenceMismatch checks whether the Tx has been rejected for its account sequence. The log is checked
+// as well, in case the error comes from a node that reports it differently.
+func isSequenceMismatch(err error) bool {
+	return errors.Is(err, ErrSequenceMismatch) || (err != nil && strings.Contains(err.Error(), "account sequence mismatch"))
+}
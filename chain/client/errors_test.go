@@ -0,0 +1,86 @@
+package client
+
+import (
+	"testing"
+
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/pkg/errors"
+	abci "github.com/tendermint/tendermint/abci/types"
+
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+)
+
+func TestChainError(t *testing.T) {
+	err := errors.Wrap(NewChainError(exchangetypes.ModuleName, exchangetypes.ErrDerivativeMarketNotFound.ABCICode(), "failed to execute message; message index: 0"), "tx rejected")
+	if !errors.Is(err, ErrMarketNotFound) || !errors.Is(err, exchangetypes.ErrDerivativeMarketNotFound) {
+		t.Fatal("expected market not found error")
+	} else if errors.Is(err, exchangetypes.ErrSpotMarketNotFound) || errors.Is(err, ErrInsufficientFunds) {
+		t.Fatal("expected no match with other errors")
+	} else if IsRetryable(err) {
+		t.Fatal("expected market not found to be final")
+	}
+
+	err = NewChainError(exchangetypes.ModuleName, exchangetypes.ErrInvalidPrice.ABCICode(), "price 1.5 must be a multiple of the minimum price tick size 1: invalid price")
+	if !errors.Is(err, ErrTickSizeBreach) {
+		t.Fatal("expected tick size breach")
+	}
+
+	err = NewChainError(exchangetypes.ModuleName, exchangetypes.ErrInvalidPrice.ABCICode(), "invalid price")
+	if errors.Is(err, ErrTickSizeBreach) {
+		t.Fatal("expected invalid price not to be a tick size breach")
+	}
+
+	err = NewChainError(sdkerrors.RootCodespace, sdkerrors.ErrWrongSequence.ABCICode(), "account sequence mismatch, expected 2, got 1")
+	if !errors.Is(err, ErrSequenceMismatch) || !IsRetryable(err) {
+		t.Fatal("expected retryable sequence mismatch")
+	}
+
+	err = NewChainError("unknown", 1, "")
+	if errors.Is(err, sdkerrors.ErrUnauthorized) || IsRetryable(err) {
+		t.Fatal("expected unregistered error to match nothing")
+	}
+}
+
+// queryError returns the response of a gRPC query over ABCI failed with err, the way the node reports it.
+func queryError(err error) abci.ResponseQuery {
+	return sdkerrors.QueryResult(sdkerrors.Wrap(sdkerrors.ErrInvalidRequest, err.Error()))
+}
+
+func TestQueryChainError(t *testing.T) {
+	res := queryError(sdkerrors.Wrapf(sdkerrors.ErrWrongSequence, "account sequence mismatch, expected 5, got 4"))
+	if res.Codespace != sdkerrors.RootCodespace || res.Code != sdkerrors.ErrInvalidRequest.ABCICode() {
+		t.Fatalf("unexpected query error %s/%d", res.Codespace, res.Code)
+	}
+
+	// matched by log, as is
+	err := NewChainError(res.Codespace, res.Code, res.Log)
+	if !errors.Is(err, ErrSequenceMismatch) || !IsRetryable(err) || isMsgFailure(nil, err) {
+		t.Fatalf("expected retryable Tx level sequence mismatch, got %v", err)
+	}
+
+	// recovered
+	err = newQueryChainError(res.Codespace, res.Code, res.Log)
+	if err.Code != sdkerrors.ErrWrongSequence.ABCICode() || err.Log != "account sequence mismatch, expected 5, got 4: incorrect account sequence" {
+		t.Fatalf("expected recovered sequence mismatch, got %v", err)
+	}
+
+	res = queryError(sdkerrors.Wrap(exchangetypes.ErrSpotMarketNotFound, "failed to execute message; message index: 2"))
+	for _, err := range []*ChainError{NewChainError(res.Codespace, res.Code, res.Log), newQueryChainError(res.Codespace, res.Code, res.Log)} {
+		if !errors.Is(err, ErrMarketNotFound) || IsRetryable(err) || !isMsgFailure(nil, err) {
+			t.Fatalf("expected market not found msg failure, got %v", err)
+		} else if idx, ok := failedMsgIndex(nil, err); !ok || idx != 2 {
+			t.Fatalf("expected failed msg index 2, got %d (%v)", idx, ok)
+		}
+	}
+
+	res = queryError(sdkerrors.Wrap(sdkerrors.ErrInsufficientFee, "insufficient fees; got: 1inj required: 2inj"))
+	if err := newQueryChainError(res.Codespace, res.Code, res.Log); !errors.Is(err, ErrInsufficientFee) || isMsgFailure(nil, err) {
+		t.Fatalf("expected Tx level insufficient fee, got %v", err)
+	}
+
+	// unknown errors keep the invalid request code
+	res = queryError(errors.New("something went wrong"))
+	if err := newQueryChainError(res.Codespace, res.Code, res.Log); err.Code != sdkerrors.ErrInvalidRequest.ABCICode() || errors.Is(err, ErrSequenceMismatch) {
+		t.Fatalf("expected unknown error to be kept, got %v", err)
+	}
+}
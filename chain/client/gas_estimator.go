@@ -0,0 +1,90 @@
+package client
+
+import (
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const defaultMsgGasEstimate = 200000
+
+// msgGasEstimator learns per message type gas usage from Tx simulation results.
+type msgGasEstimator struct {
+	mux        *sync.RWMutex
+	defaultGas uint64
+	estimates  map[string]uint64
+}
+
+func newMsgGasEstimator(defaultGas uint64, initial map[string]uint64) *msgGasEstimator {
+	e := &msgGasEstimator{
+		mux:        new(sync.RWMutex),
+		defaultGas: defaultGas,
+		estimates:  make(map[string]uint64, len(initial)),
+	}
+
+	for msgType, gas := range initial {
+		e.estimates[msgType] = gas
+	}
+
+	return e
+}
+
+// Estimate returns the expected amount of gas the message will use.
+func (e *msgGasEstimator) Estimate(msg sdk.Msg) uint64 {
+	e.mux.RLock()
+	defer e.mux.RUnlock()
+
+	return e.estimate(sdk.MsgTypeURL(msg))
+}
+
+func (e *msgGasEstimator) estimate(msgType string) uint64 {
+	if gas, ok := e.estimates[msgType]; ok {
+		return gas
+	}
+
+	return e.defaultGas
+}
+
+// Observe updates the estimates of message types present in msgs, using the total amount
+// of gas the Tx has used. Gas is attributed to message types proportionally to their current estimates.
+func (e *msgGasEstimator) Observe(msgs []sdk.Msg, gasUsed uint64) {
+	if len(msgs) == 0 || gasUsed == 0 {
+		return
+	}
+
+	e.mux.Lock()
+	defer e.mux.Unlock()
+
+	var predicted uint64
+	msgTypes := make(map[string]struct{})
+	for _, msg := range msgs {
+		msgType := sdk.MsgTypeURL(msg)
+		msgTypes[msgType] = struct{}{}
+		predicted += e.estimate(msgType)
+	}
+
+	ratio := float64(gasUsed) / float64(predicted)
+	for msgType := range msgTypes {
+		prev, seen := e.estimates[msgType]
+		if !seen {
+			e.estimates[msgType] = uint64(float64(e.defaultGas) * ratio)
+			continue
+		}
+
+		// smooth the estimate, so a single outlier won't shrink or inflate batches too much
+		e.estimates[msgType] = uint64((float64(prev) + float64(prev)*ratio) / 2)
+	}
+}
+
+// Estimates returns a copy of all learned estimates, keyed by message type URL.
+func (e *msgGasEstimator) Estimates() map[string]uint64 {
+	e.mux.RLock()
+	defer e.mux.RUnlock()
+
+	estimates := make(map[string]uint64, len(e.estimates))
+	for msgType, gas := range e.estimates {
+		estimates[msgType] = gas
+	}
+
+	return estimates
+}
@@ -0,0 +1,35 @@
+package client
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+)
+
+func TestMsgGasEstimatorObserve(t *testing.T) {
+	send, multiSend := &banktypes.MsgSend{}, &banktypes.MsgMultiSend{}
+	e := newMsgGasEstimator(200000, map[string]uint64{
+		sdk.MsgTypeURL(send): 100000,
+	})
+
+	// 400000 predicted, so each type is attributed 1.5 times its estimate
+	e.Observe([]sdk.Msg{send, send, multiSend}, 600000)
+
+	if gas := e.Estimate(send); gas != 125000 {
+		t.Fatalf("expected the known estimate to be smoothed to 125000, got %d", gas)
+	} else if gas := e.Estimate(multiSend); gas != 300000 {
+		t.Fatalf("expected the new type to be learned as 300000, got %d", gas)
+	}
+
+	e.Observe([]sdk.Msg{send}, 0)
+	if gas := e.Estimates()[sdk.MsgTypeURL(send)]; gas != 125000 {
+		t.Fatalf("expected a Tx without gas to be ignored, got %d", gas)
+	}
+
+	if gas := e.Estimate(&exchangetypes.MsgDeposit{}); gas != 200000 {
+		t.Fatalf("expected the default estimate for an unseen type, got %d", gas)
+	}
+}
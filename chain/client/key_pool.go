@@ -0,0 +1,73 @@
+package client
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// signingKey keeps the signing state of a single key. Every key has its own
+// account sequence and broadcast queue, so Txns signed by different keys don't wait for each other.
+type signingKey struct {
+	ctx       client.Context
+	txFactory tx.Factory
+	signer    TxSigner
+	syncMux   *sync.Mutex
+	msgC      chan *queuedMsg
+	doneC     chan bool
+
+	accNum uint64
+	accSeq uint64
+	// inFlight are Txns broadcasted by the key, but not yet seen in a block.
+	inFlight []InFlightTx
+}
+
+func newSigningKey(ctx client.Context, txFactory tx.Factory, signer TxSigner, queueSize int) *signingKey {
+	return &signingKey{
+		ctx:       ctx,
+		txFactory: txFactory,
+		signer:    signer,
+		syncMux:   new(sync.Mutex),
+		msgC:      make(chan *queuedMsg, queueSize),
+		doneC:     make(chan bool, 1),
+	}
+}
+
+func (k *signingKey) address() sdk.AccAddress {
+	return k.ctx.GetFromAddress()
+}
+
+// KeyDispatcher picks the key to sign msgs with, returning its index in keys.
+type KeyDispatcher func(keys []sdk.AccAddress, msgs ...sdk.Msg) int
+
+// RoundRobinDispatcher spreads messages evenly across all keys.
+func RoundRobinDispatcher() KeyDispatcher {
+	var counter uint64
+
+	return func(keys []sdk.AccAddress, _ ...sdk.Msg) int {
+		n := atomic.AddUint64(&counter, 1) - 1
+		return int(n % uint64(len(keys)))
+	}
+}
+
+// SignerAffinityDispatcher routes messages to the key matching the first signer of the
+// first message, so all orders of a subaccount go through the key that owns it.
+// Messages whose signer is not in the pool are dispatched using fallback.
+func SignerAffinityDispatcher(fallback KeyDispatcher) KeyDispatcher {
+	return func(keys []sdk.AccAddress, msgs ...sdk.Msg) int {
+		if len(msgs) > 0 {
+			if signers := msgs[0].GetSigners(); len(signers) > 0 {
+				for idx, key := range keys {
+					if key.Equals(signers[0]) {
+						return idx
+					}
+				}
+			}
+		}
+
+		return fallback(keys, msgs...)
+	}
+}
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+)
+
+func TestSignerAffinityDispatcher(t *testing.T) {
+	keys := []sdk.AccAddress{
+		sdk.AccAddress("test_key_0__________"),
+		sdk.AccAddress("test_key_1__________"),
+		sdk.AccAddress("test_key_2__________"),
+	}
+
+	send := func(from sdk.AccAddress) sdk.Msg {
+		return banktypes.NewMsgSend(from, keys[0], sdk.NewCoins(sdk.NewInt64Coin("inj", 1)))
+	}
+
+	var fallbacks int
+	dispatch := SignerAffinityDispatcher(func([]sdk.AccAddress, ...sdk.Msg) int {
+		fallbacks++
+		return 0
+	})
+
+	// the first message decides, whatever the signers of the others
+	if idx := dispatch(keys, send(keys[2]), send(keys[1])); idx != 2 {
+		t.Fatalf("expected the key of the first signer, got %d", idx)
+	} else if idx := dispatch(keys, send(keys[1])); idx != 1 || fallbacks != 0 {
+		t.Fatalf("expected the key of the signer without fallback, got %d", idx)
+	}
+
+	if idx := dispatch(keys, send(sdk.AccAddress("test_other__________"))); idx != 0 || fallbacks != 1 {
+		t.Fatalf("expected a signer out of the pool to fall back, got %d", idx)
+	} else if dispatch(keys); fallbacks != 2 {
+		t.Fatal("expected no messages to fall back")
+	}
+
+	roundRobin := SignerAffinityDispatcher(RoundRobinDispatcher())
+	other := send(sdk.AccAddress("test_other__________"))
+	if first, second := roundRobin(keys, other), roundRobin(keys, other); first != 0 || second != 1 {
+		t.Fatalf("expected round robin fallback, got %d and %d", first, second)
+	}
+}
@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/status"
+
+	chainclient "github.com/InjectiveLabs/sdk-go/chain/client"
+)
+
+var _ chainclient.Observer = (*PrometheusObserver)(nil)
+var _ prometheus.Collector = (*PrometheusObserver)(nil)
+
+// PrometheusObserver is an observer of chain and exchange API clients that exposes
+// the observed events as Prometheus metrics. Register it with a prometheus.Registerer.
+type PrometheusObserver struct {
+	broadcastLatency   *prometheus.HistogramVec
+	inclusionLatency   *prometheus.HistogramVec
+	queueDepth         *prometheus.GaugeVec
+	batchSize          prometheus.Histogram
+	sequenceResyncs    *prometheus.CounterVec
+	grpcCallDuration   *prometheus.HistogramVec
+	grpcStreamDuration *prometheus.HistogramVec
+	streamReconnects   *prometheus.CounterVec
+}
+
+// NewPrometheusObserver creates the observer with all metric names prefixed by namespace, e.g. "trading_bot".
+func NewPrometheusObserver(namespace string) *PrometheusObserver {
+	return &PrometheusObserver{
+		broadcastLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "chain_client",
+			Name:      "broadcast_latency_seconds",
+			Help:      "Time for a Tx to be accepted or rejected by the node in CheckTx.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 12),
+		}, []string{"status"}),
+		inclusionLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "chain_client",
+			Name:      "inclusion_latency_seconds",
+			Help:      "Time from Tx acceptance to its inclusion in a block.",
+			Buckets:   prometheus.ExponentialBuckets(0.1, 2, 10),
+		}, []string{"status"}),
+		queueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "chain_client",
+			Name:      "queue_depth",
+			Help:      "Messages waiting in the broadcast queue of a key.",
+		}, []string{"key"}),
+		batchSize: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "chain_client",
+			Name:      "batch_size",
+			Help:      "Queued messages sent in one Tx.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 11),
+		}),
+		sequenceResyncs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "chain_client",
+			Name:      "sequence_resyncs_total",
+			Help:      "Account sequence resyncs from the chain.",
+		}, []string{"key"}),
+		grpcCallDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "grpc_client",
+			Name:      "call_duration_seconds",
+			Help:      "Duration of unary gRPC calls.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+		}, []string{"method", "code"}),
+		grpcStreamDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "grpc_client",
+			Name:      "stream_duration_seconds",
+			Help:      "Lifetime of gRPC streams.",
+			Buckets:   prometheus.ExponentialBuckets(1, 4, 10),
+		}, []string{"method", "code"}),
+		streamReconnects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "stream",
+			Name:      "reconnects_total",
+			Help:      "Reconnects of event streams.",
+		}, []string{"stream"}),
+	}
+}
+
+func (o *PrometheusObserver) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		o.broadcastLatency,
+		o.inclusionLatency,
+		o.queueDepth,
+		o.batchSize,
+		o.sequenceResyncs,
+		o.grpcCallDuration,
+		o.grpcStreamDuration,
+		o.streamReconnects,
+	}
+}
+
+func (o *PrometheusObserver) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range o.collectors() {
+		c.Describe(ch)
+	}
+}
+
+func (o *PrometheusObserver) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range o.collectors() {
+		c.Collect(ch)
+	}
+}
+
+func (o *PrometheusObserver) ObserveBroadcast(latency time.Duration, err error) {
+	o.broadcastLatency.WithLabelValues(txStatus(err)).Observe(latency.Seconds())
+}
+
+func (o *PrometheusObserver) ObserveInclusion(latency time.Duration, err error) {
+	o.inclusionLatency.WithLabelValues(txStatus(err)).Observe(latency.Seconds())
+}
+
+func (o *PrometheusObserver) ObserveQueueDepth(key sdk.AccAddress, depth int) {
+	o.queueDepth.WithLabelValues(key.String()).Set(float64(depth))
+}
+
+func (o *PrometheusObserver) ObserveBatchSize(size int) {
+	o.batchSize.Observe(float64(size))
+}
+
+func (o *PrometheusObserver) ObserveSequenceResync(key sdk.AccAddress) {
+	o.sequenceResyncs.WithLabelValues(key.String()).Inc()
+}
+
+func (o *PrometheusObserver) ObserveGRPCCall(method string, duration time.Duration, err error) {
+	o.grpcCallDuration.WithLabelValues(method, status.Code(err).String()).Observe(duration.Seconds())
+}
+
+func (o *PrometheusObserver) ObserveGRPCStream(method string, duration time.Duration, err error) {
+	o.grpcStreamDuration.WithLabelValues(method, status.Code(err).String()).Observe(duration.Seconds())
+}
+
+func (o *PrometheusObserver) ObserveStreamReconnect(stream string) {
+	o.streamReconnects.WithLabelValues(stream).Inc()
+}
+
+// txStatus is "ok", "timeout" for Txns not seen in a block in time, the codespace of
+// the chain error, e.g. "sdk" or "exchange", or "error" for other failures.
+func txStatus(err error) string {
+	var chainErr *chainclient.ChainError
+
+	switch {
+	case err == nil:
+		return "ok"
+	case chainclient.IsTxNotIncluded(err):
+		return "timeout"
+	case errors.As(err, &chainErr):
+		return chainErr.Codespace
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	chainclient "github.com/InjectiveLabs/sdk-go/chain/client"
+)
+
+func TestPrometheusObserver(t *testing.T) {
+	observer := NewPrometheusObserver("test")
+
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(observer); err != nil {
+		t.Fatal(err)
+	}
+
+	key := sdk.AccAddress("test_address________")
+	observer.ObserveBroadcast(10*time.Millisecond, nil)
+	observer.ObserveBroadcast(10*time.Millisecond, chainclient.NewChainError("sdk", 32, "account sequence mismatch"))
+	observer.ObserveQueueDepth(key, 7)
+	observer.ObserveSequenceResync(key)
+	observer.ObserveGRPCCall("/injective.exchange.v1beta1.Query/SpotMarkets", time.Millisecond, status.Error(codes.Unavailable, "down"))
+	observer.ObserveStreamReconnect("tm.event='NewBlock'")
+
+	if v := testutil.ToFloat64(observer.queueDepth.WithLabelValues(key.String())); v != 7 {
+		t.Fatalf("expected queue depth 7, got %v", v)
+	} else if v := testutil.ToFloat64(observer.sequenceResyncs.WithLabelValues(key.String())); v != 1 {
+		t.Fatalf("expected 1 resync, got %v", v)
+	} else if n := testutil.CollectAndCount(observer.broadcastLatency); n != 2 {
+		t.Fatalf("expected broadcasts by ok and sdk statuses, got %d series", n)
+	} else if n := testutil.CollectAndCount(observer.grpcCallDuration); n != 1 {
+		t.Fatalf("expected 1 gRPC call series, got %d", n)
+	}
+
+	if _, err := registry.Gather(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,376 @@
+package client
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/pkg/errors"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	log "github.com/xlab/suplog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NodeEndpoint describes a chain node the client can talk to.
+type NodeEndpoint struct {
+	// GRPCAddr must be in form "tcp://127.0.0.1:9900" or "unix:///tmp/test.sock", protocol is required.
+	GRPCAddr string
+	// TendermintRPCAddr is the address of Tendermint RPC, e.g. "http://127.0.0.1:26657".
+	TendermintRPCAddr string
+}
+
+// NodeStatus is the last known health of a node.
+type NodeStatus struct {
+	Endpoint NodeEndpoint
+	Height   int64
+	Healthy  bool
+	Current  bool
+	Err      error
+}
+
+const (
+	defaultNodeHealthCheckInterval = 5 * time.Second
+	defaultNodeMaxHeightLag        = 3
+)
+
+type chainNode struct {
+	endpoint NodeEndpoint
+	conn     *grpc.ClientConn
+	tmClient rpcclient.Client
+
+	height  int64
+	healthy bool
+	err     error
+}
+
+// dialNodes connects to the primary node, made of protoAddr and Tendermint RPC client from ctx,
+// and to the additional nodes.
+func dialNodes(ctx client.Context, protoAddr string, endpoints []NodeEndpoint, options ...DialOption) ([]*chainNode, error) {
+	opts, err := parseDialOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := dialGRPC(protoAddr, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []*chainNode{{
+		endpoint: NodeEndpoint{
+			GRPCAddr:          protoAddr,
+			TendermintRPCAddr: ctx.NodeURI,
+		},
+		conn:     conn,
+		tmClient: ctx.Client,
+	}}
+
+	for _, endpoint := range endpoints {
+		conn, err := dialGRPC(endpoint.GRPCAddr, opts)
+		if err != nil {
+			closeNodes(nodes)
+			return nil, err
+		}
+
+		tmClient, err := newTendermintRPCClient(endpoint.TendermintRPCAddr, opts)
+		if err != nil {
+			conn.Close()
+			closeNodes(nodes)
+			return nil, err
+		}
+
+		nodes = append(nodes, &chainNode{
+			endpoint: endpoint,
+			conn:     conn,
+			tmClient: tmClient,
+		})
+	}
+
+	return nodes, nil
+}
+
+func closeNodes(nodes []*chainNode) {
+	for _, n := range nodes {
+		if n.conn != nil {
+			n.conn.Close()
+		}
+	}
+}
+
+// nodePool keeps track of node health and picks the node to route queries and broadcasts to.
+// A node is healthy when it responds, is not catching up and doesn't lag too much behind the
+// highest known block.
+type nodePool struct {
+	mux     *sync.RWMutex
+	nodes   []*chainNode
+	current int
+
+	checkInterval time.Duration
+	maxHeightLag  int64
+
+	logger log.Logger
+	quitC  chan struct{}
+	doneC  chan struct{}
+}
+
+func newNodePool(nodes []*chainNode, checkInterval time.Duration, maxHeightLag int64, logger log.Logger) *nodePool {
+	for _, n := range nodes {
+		// assume all are fine until checked
+		n.healthy = true
+	}
+
+	return &nodePool{
+		mux:           new(sync.RWMutex),
+		nodes:         nodes,
+		checkInterval: checkInterval,
+		maxHeightLag:  maxHeightLag,
+		logger:        logger,
+		quitC:         make(chan struct{}),
+		doneC:         make(chan struct{}),
+	}
+}
+
+// Current returns the node that is currently the healthiest.
+func (p *nodePool) Current() *chainNode {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+
+	return p.nodes[p.current]
+}
+
+// Failover marks the node as failed and switches to the next healthy node.
+// Returns false if there is no other node to switch to.
+func (p *nodePool) Failover(failed *chainNode, err error) bool {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	failed.healthy = false
+	failed.err = err
+
+	if p.nodes[p.current] != failed {
+		// someone has already switched
+		return true
+	}
+
+	for i := 1; i < len(p.nodes); i++ {
+		idx := (p.current + i) % len(p.nodes)
+		if p.nodes[idx].healthy {
+			p.switchTo(idx)
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *nodePool) switchTo(idx int) {
+	if idx == p.current {
+		return
+	}
+
+	p.logger.WithFields(log.Fields{
+		"from": p.nodes[p.current].endpoint.GRPCAddr,
+		"to":   p.nodes[idx].endpoint.GRPCAddr,
+	}).Warningln("switching chain node")
+
+	p.current = idx
+}
+
+// Statuses returns the last known health of all nodes.
+func (p *nodePool) Statuses() []NodeStatus {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+
+	statuses := make([]NodeStatus, 0, len(p.nodes))
+	for idx, n := range p.nodes {
+		statuses = append(statuses, NodeStatus{
+			Endpoint: n.endpoint,
+			Height:   n.height,
+			Healthy:  n.healthy,
+			Current:  idx == p.current,
+			Err:      n.err,
+		})
+	}
+
+	return statuses
+}
+
+// CheckHealth queries the status of all nodes and switches to the healthiest one.
+func (p *nodePool) CheckHealth(ctx context.Context) {
+	heights := make([]int64, len(p.nodes))
+	errs := make([]error, len(p.nodes))
+
+	wg := new(sync.WaitGroup)
+	for idx, n := range p.nodes {
+		wg.Add(1)
+
+		go func(idx int, n *chainNode) {
+			defer wg.Done()
+
+			heights[idx], errs[idx] = nodeHeight(ctx, n.tmClient)
+		}(idx, n)
+	}
+	wg.Wait()
+
+	var maxHeight int64
+	for idx := range p.nodes {
+		if errs[idx] == nil && heights[idx] > maxHeight {
+			maxHeight = heights[idx]
+		}
+	}
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	best := -1
+	for idx, n := range p.nodes {
+		n.height = heights[idx]
+		n.err = errs[idx]
+		n.healthy = errs[idx] == nil && maxHeight-heights[idx] <= p.maxHeightLag
+
+		if n.healthy && (best < 0 || n.height > p.nodes[best].height) {
+			best = idx
+		}
+	}
+
+	if best < 0 {
+		p.logger.Errorln("no healthy chain nodes available")
+		return
+	}
+
+	// stay on the current node unless it's unhealthy or a block behind,
+	// so queries and broadcasts don't flap between nodes on every block
+	if cur := p.nodes[p.current]; cur.healthy && cur.height+1 >= p.nodes[best].height {
+		return
+	}
+
+	p.switchTo(best)
+}
+
+func nodeHeight(ctx context.Context, tmClient rpcclient.Client) (int64, error) {
+	if tmClient == nil {
+		return 0, errors.New("no Tendermint RPC client")
+	}
+
+	status, err := tmClient.Status(ctx)
+	if err != nil {
+		return 0, err
+	} else if status.SyncInfo.CatchingUp {
+		return status.SyncInfo.LatestBlockHeight, errors.New("node is catching up")
+	}
+
+	return status.SyncInfo.LatestBlockHeight, nil
+}
+
+func (p *nodePool) run() {
+	defer close(p.doneC)
+
+	t := time.NewTicker(p.checkInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-p.quitC:
+			return
+		case <-t.C:
+			ctx, cancelFn := context.WithTimeout(context.Background(), p.checkInterval)
+			p.CheckHealth(ctx)
+			cancelFn()
+		}
+	}
+}
+
+// Start runs periodic health checks, if there are nodes to choose from.
+func (p *nodePool) Start() {
+	if len(p.nodes) < 2 {
+		close(p.doneC)
+		return
+	}
+
+	go p.run()
+}
+
+// Close stops health checks and closes all connections.
+func (p *nodePool) Close() {
+	close(p.quitC)
+	<-p.doneC
+
+	closeNodes(p.nodes)
+}
+
+// nodeCtx returns a copy of client context that talks to the current node.
+func (c *cosmosClient) nodeCtx(clientCtx client.Context) client.Context {
+	node := c.nodes.Current()
+	if node.tmClient == nil {
+		return clientCtx
+	}
+
+	return clientCtx.WithClient(node.tmClient)
+}
+
+// currentNodeConn routes every call to the gRPC connection of the current node. A call that fails
+// for the node being unreachable or unresponsive is retried once on the next healthy node.
+type currentNodeConn struct {
+	nodes *nodePool
+}
+
+func (c *currentNodeConn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	node := c.nodes.Current()
+	err := node.conn.Invoke(ctx, method, args, reply, opts...)
+	if c.failover(ctx, node, method, err) {
+		err = c.nodes.Current().conn.Invoke(ctx, method, args, reply, opts...)
+	}
+
+	return err
+}
+
+func (c *currentNodeConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	node := c.nodes.Current()
+	stream, err := node.conn.NewStream(ctx, desc, method, opts...)
+	if c.failover(ctx, node, method, err) {
+		stream, err = c.nodes.Current().conn.NewStream(ctx, desc, method, opts...)
+	}
+
+	return stream, err
+}
+
+// failover switches away from the node if the call has failed with Unavailable or DeadlineExceeded,
+// returns true if the call can be retried on another node.
+func (c *currentNodeConn) failover(ctx context.Context, node *chainNode, method string, err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+	default:
+		return false
+	}
+
+	if !c.nodes.Failover(node, err) || ctx.Err() != nil {
+		return false
+	}
+
+	c.nodes.logger.WithError(err).WithField("method", method).Warningln("node is unreachable, retrying query on another node")
+	return true
+}
+
+// isTransportError checks whether the error is caused by the node being unreachable.
+func isTransportError(err error) bool {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	if st, ok := status.FromError(errors.Cause(err)); ok && st.Code() == codes.Unavailable {
+		return true
+	}
+
+	return false
+}
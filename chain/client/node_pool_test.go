@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	log "github.com/xlab/suplog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type statusClient struct {
+	rpcclient.Client
+
+	height     int64
+	catchingUp bool
+	err        error
+}
+
+func (c *statusClient) Status(context.Context) (*ctypes.ResultStatus, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	status := &ctypes.ResultStatus{}
+	status.SyncInfo.LatestBlockHeight = c.height
+	status.SyncInfo.CatchingUp = c.catchingUp
+
+	return status, nil
+}
+
+func TestNodePoolHealth(t *testing.T) {
+	primary := &statusClient{height: 100}
+	lagging := &statusClient{height: 90}
+	ahead := &statusClient{height: 101}
+
+	pool := newNodePool([]*chainNode{
+		{tmClient: primary},
+		{tmClient: lagging},
+		{tmClient: ahead},
+	}, time.Second, 3, log.DefaultLogger)
+
+	pool.CheckHealth(context.Background())
+	if pool.Current().tmClient != primary {
+		t.Fatal("expected to stay on the primary node, which is just one block behind")
+	} else if pool.Statuses()[1].Healthy {
+		t.Fatal("expected lagging node to be unhealthy")
+	}
+
+	primary.err = errors.New("connection refused")
+	pool.CheckHealth(context.Background())
+	if pool.Current().tmClient != ahead {
+		t.Fatal("expected to switch to the highest healthy node")
+	}
+
+	if pool.Failover(pool.Current(), errors.New("connection refused")) {
+		t.Fatal("expected no node to fail over to")
+	}
+
+	primary.err = nil
+	primary.height = 102
+	pool.CheckHealth(context.Background())
+	if !pool.Failover(pool.Current(), errors.New("connection refused")) || pool.Current().tmClient != primary {
+		t.Fatal("expected to fail over to the primary node")
+	}
+}
+
+func dialBufconn(t *testing.T, lis *bufconn.Listener) *grpc.ClientConn {
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return conn
+}
+
+func TestNodePoolQueryFailover(t *testing.T) {
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, health.NewServer())
+
+	lis := bufconn.Listen(1 << 20)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	// nothing listens there, so queries fail with Unavailable
+	down := bufconn.Listen(1 << 20)
+	down.Close()
+
+	pool := newNodePool([]*chainNode{
+		{conn: dialBufconn(t, down)},
+		{conn: dialBufconn(t, lis)},
+	}, time.Second, 3, log.DefaultLogger)
+	defer closeNodes(pool.nodes)
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFn()
+
+	healthClient := healthpb.NewHealthClient(&currentNodeConn{nodes: pool})
+	res, err := healthClient.Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	} else if res.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("unexpected health status %s", res.Status)
+	} else if statuses := pool.Statuses(); statuses[0].Healthy || !statuses[1].Current {
+		t.Fatalf("expected to fail over to the second node, got %+v", statuses)
+	}
+
+	// streams fail over as well
+	pool.mux.Lock()
+	pool.nodes[0].healthy = true
+	pool.current = 0
+	pool.mux.Unlock()
+
+	watch, err := healthClient.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	} else if res, err := watch.Recv(); err != nil || res.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("unexpected watch result %v, %v", res, err)
+	} else if !pool.Statuses()[1].Current {
+		t.Fatal("expected the stream to be opened on the second node")
+	}
+}
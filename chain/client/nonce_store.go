@@ -0,0 +1,322 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// NonceStore persists account sequences of signing keys, so a restarted client knows
+// which sequences it has already used and which Txns may still be pending.
+type NonceStore interface {
+	// Load returns the state saved for the address, nil if nothing has been saved.
+	Load(address sdk.AccAddress) (*NonceState, error)
+	// Save replaces the state of the address.
+	Save(address sdk.AccAddress, state *NonceState) error
+}
+
+// NonceState is the signing state of an account.
+type NonceState struct {
+	AccountNumber uint64 `json:"account_number"`
+	// NextSequence is the sequence the next Tx is going to be signed with.
+	NextSequence uint64 `json:"next_sequence"`
+	// InFlight are Txns that have been broadcasted, but have not been seen in a block.
+	InFlight []InFlightTx `json:"in_flight,omitempty"`
+}
+
+// InFlightTx is a Tx broadcasted with a sequence.
+type InFlightTx struct {
+	Sequence  uint64    `json:"sequence"`
+	TxHash    string    `json:"tx_hash"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func (s *NonceState) copy() *NonceState {
+	state := *s
+	state.InFlight = append([]InFlightTx(nil), s.InFlight...)
+	return &state
+}
+
+// NewMemoryNonceStore creates a store that keeps nonces for the lifetime of the process.
+func NewMemoryNonceStore() NonceStore {
+	return &memoryNonceStore{
+		mux:    new(sync.RWMutex),
+		states: make(map[string]*NonceState),
+	}
+}
+
+type memoryNonceStore struct {
+	mux    *sync.RWMutex
+	states map[string]*NonceState
+}
+
+func (s *memoryNonceStore) Load(address sdk.AccAddress) (*NonceState, error) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	state, ok := s.states[address.String()]
+	if !ok {
+		return nil, nil
+	}
+
+	return state.copy(), nil
+}
+
+func (s *memoryNonceStore) Save(address sdk.AccAddress, state *NonceState) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.states[address.String()] = state.copy()
+	return nil
+}
+
+// NewFileNonceStore creates a store that keeps nonces of all accounts in a JSON file at path.
+// The file is replaced atomically on every save, so it survives a crash mid-write.
+func NewFileNonceStore(path string) (NonceStore, error) {
+	s := &fileNonceStore{
+		mux:    new(sync.Mutex),
+		path:   path,
+		states: make(map[string]*NonceState),
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	} else if err != nil {
+		err = errors.Wrap(err, "failed to read nonce store file")
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &s.states); err != nil {
+		err = errors.Wrapf(err, "failed to parse nonce store file %s", path)
+		return nil, err
+	}
+
+	return s, nil
+}
+
+type fileNonceStore struct {
+	mux    *sync.Mutex
+	path   string
+	states map[string]*NonceState
+}
+
+func (s *fileNonceStore) Load(address sdk.AccAddress) (*NonceState, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	state, ok := s.states[address.String()]
+	if !ok {
+		return nil, nil
+	}
+
+	return state.copy(), nil
+}
+
+func (s *fileNonceStore) Save(address sdk.AccAddress, state *NonceState) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.states[address.String()] = state.copy()
+
+	data, err := json.MarshalIndent(s.states, "", "  ")
+	if err != nil {
+		err = errors.Wrap(err, "failed to marshal nonces")
+		return err
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		err = errors.Wrap(err, "failed to create temp nonce store file")
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err = tmpFile.Write(data); err == nil {
+		err = tmpFile.Sync()
+	}
+
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		err = errors.Wrap(err, "failed to write temp nonce store file")
+		return err
+	}
+
+	if err := os.Rename(tmpFile.Name(), s.path); err != nil {
+		err = errors.Wrap(err, "failed to replace nonce store file")
+		return err
+	}
+
+	return nil
+}
+
+const (
+	// maxInFlightTxs bounds the amount of in-flight Txns kept per key, when the chain
+	// sequence is not being resynced for a long time, e.g. async broadcasts.
+	maxInFlightTxs = 256
+
+	// mempoolTxsLimit is the max amount of mempool Txns the node lists.
+	mempoolTxsLimit = 100
+
+	mempoolQueryTimeout = 10 * time.Second
+)
+
+// trackInFlight records the Tx as broadcasted with the sequence.
+func (k *signingKey) trackInFlight(seq uint64, txHash string) {
+	k.inFlight = append(k.inFlight, InFlightTx{
+		Sequence:  seq,
+		TxHash:    txHash,
+		Timestamp: time.Now().UTC(),
+	})
+
+	if len(k.inFlight) > maxInFlightTxs {
+		k.inFlight = k.inFlight[len(k.inFlight)-maxInFlightTxs:]
+	}
+}
+
+// untrackInFlight forgets the Tx, either it has been included or rejected.
+func (k *signingKey) untrackInFlight(txHash string) {
+	for idx, inFlight := range k.inFlight {
+		if inFlight.TxHash == txHash {
+			k.inFlight = append(k.inFlight[:idx:idx], k.inFlight[idx+1:]...)
+			return
+		}
+	}
+}
+
+// pruneInFlight forgets Txns with sequences below seq, they either have been
+// included or will never be.
+func (k *signingKey) pruneInFlight(seq uint64) {
+	inFlightTxs := make([]InFlightTx, 0, len(k.inFlight))
+	for _, inFlight := range k.inFlight {
+		if inFlight.Sequence >= seq {
+			inFlightTxs = append(inFlightTxs, inFlight)
+		}
+	}
+
+	k.inFlight = inFlightTxs
+}
+
+func (c *cosmosClient) saveNonces(key *signingKey) {
+	err := c.opts.NonceStore.Save(key.address(), &NonceState{
+		AccountNumber: key.accNum,
+		NextSequence:  key.accSeq,
+		InFlight:      key.inFlight,
+	})
+	if err != nil {
+		c.logger.WithError(err).WithField("address", key.address().String()).Errorln("failed to save nonces")
+	}
+}
+
+// reconcileNonce sets the next sequence of the key from the sequence committed on chain,
+// skipping sequences of in-flight Txns that are still in the mempool. In-flight Txns
+// dropped from the mempool are forgotten, their sequences will be reused. When the mempool
+// can't tell whether a Tx is pending, its sequence is assumed taken if it is below the next
+// sequence the key had, which is the persisted one on startup.
+func (c *cosmosClient) reconcileNonce(key *signingKey, chainSeq uint64) {
+	key.pruneInFlight(chainSeq)
+	localSeq := key.accSeq
+
+	nextSeq := chainSeq
+	if len(key.inFlight) > 0 {
+		sort.SliceStable(key.inFlight, func(i, j int) bool {
+			return key.inFlight[i].Sequence < key.inFlight[j].Sequence
+		})
+
+		mempool := c.newMempoolCheck()
+
+		pendingTxs := make([]InFlightTx, 0, len(key.inFlight))
+		for _, inFlight := range key.inFlight {
+			if inFlight.Sequence != nextSeq {
+				// either a Tx replaced with the same sequence, or one that can't be valid after a gap
+				c.logger.WithField("txHash", inFlight.TxHash).Debugln("in-flight Tx has been dropped, reusing sequence", inFlight.Sequence)
+				continue
+			}
+
+			pending, err := mempool.isPending(inFlight)
+			if err != nil {
+				pending = inFlight.Sequence < localSeq
+				c.logger.WithError(err).WithField("txHash", inFlight.TxHash).Warningln("failed to check in-flight Tx in mempool, assuming pending:", pending)
+			}
+
+			if !pending {
+				c.logger.WithField("txHash", inFlight.TxHash).Debugln("in-flight Tx has been dropped, reusing sequence", inFlight.Sequence)
+				continue
+			}
+
+			pendingTxs = append(pendingTxs, inFlight)
+			nextSeq++
+		}
+
+		key.inFlight = pendingTxs
+	}
+
+	if nextSeq > chainSeq {
+		c.logger.WithField("address", key.address().String()).Infoln(nextSeq-chainSeq, "Txns are pending in mempool, next sequence is", nextSeq)
+	}
+
+	key.accSeq = nextSeq
+	c.saveNonces(key)
+}
+
+// mempoolCheck tells whether in-flight Txns are pending in the mempool of the current node.
+type mempoolCheck struct {
+	// listed are hashes of the mempool Txns listed by the node
+	listed map[string]bool
+	// complete is set if the listing holds the whole mempool
+	complete bool
+	listErr  error
+}
+
+// newMempoolCheck lists the mempool of the current node. Only the first mempoolTxsLimit Txns are listed.
+func (c *cosmosClient) newMempoolCheck() *mempoolCheck {
+	ctx, cancelFn := context.WithTimeout(context.Background(), mempoolQueryTimeout)
+	defer cancelFn()
+
+	limit := mempoolTxsLimit
+	res, err := c.nodes.Current().tmClient.UnconfirmedTxs(ctx, &limit)
+	if err != nil {
+		return &mempoolCheck{
+			listErr: errors.Wrap(err, "failed to list mempool Txns"),
+		}
+	}
+
+	m := &mempoolCheck{
+		listed:   make(map[string]bool, len(res.Txs)),
+		complete: res.Total <= len(res.Txs),
+	}
+
+	for _, txBytes := range res.Txs {
+		m.listed[fmt.Sprintf("%X", tmtypes.Tx(txBytes).Hash())] = true
+	}
+
+	return m
+}
+
+// isPending tells whether the Tx is in the mempool. It fails for a Tx missing from an incomplete listing,
+// there's no way to check it alone: CheckTx would take the sequence of a dropped Tx on the node.
+func (m *mempoolCheck) isPending(inFlight InFlightTx) (bool, error) {
+	if m.listErr != nil {
+		return false, m.listErr
+	} else if m.listed[inFlight.TxHash] {
+		return true, nil
+	} else if m.complete {
+		return false, nil
+	}
+
+	err := errors.Errorf("Tx is not among the first %d mempool Txns", len(m.listed))
+	return false, err
+}
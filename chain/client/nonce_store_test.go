@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	log "github.com/xlab/suplog"
+)
+
+type mempoolClient struct {
+	rpcclient.Client
+
+	txs []tmtypes.Tx
+	// limit is the amount of Txns listed, all if zero
+	limit int
+}
+
+func (c *mempoolClient) UnconfirmedTxs(context.Context, *int) (*ctypes.ResultUnconfirmedTxs, error) {
+	listed := c.txs
+	if c.limit > 0 && c.limit < len(listed) {
+		listed = listed[:c.limit]
+	}
+
+	return &ctypes.ResultUnconfirmedTxs{
+		Count: len(listed),
+		Total: len(c.txs),
+		Txs:   listed,
+	}, nil
+}
+
+func testInFlightTx(seq uint64) string {
+	return fmt.Sprintf("%X", tmtypes.Tx(fmt.Sprintf("tx %d", seq)).Hash())
+}
+
+func TestReconcileNonce(t *testing.T) {
+	store, err := NewFileNonceStore(filepath.Join(t.TempDir(), "nonces.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address := sdk.AccAddress("test_address________")
+	mempool := &mempoolClient{
+		txs: []tmtypes.Tx{tmtypes.Tx("tx 10"), tmtypes.Tx("tx 11"), tmtypes.Tx("tx 13")},
+	}
+
+	cc := &cosmosClient{
+		opts:   &cosmosClientOptions{NonceStore: store},
+		logger: log.DefaultLogger,
+		nodes:  newNodePool([]*chainNode{{tmClient: mempool}}, time.Second, 3, log.DefaultLogger),
+	}
+
+	key := newSigningKey(client.Context{}.WithFromAddress(address), NewTxFactory(client.Context{}), nil, 1)
+	key.accNum = 1
+	for seq := uint64(9); seq <= 13; seq++ {
+		key.trackInFlight(seq, testInFlightTx(seq))
+	}
+
+	cc.saveNonces(key)
+
+	// restart with a fresh store over the same file
+	store, err = NewFileNonceStore(store.(*fileNonceStore).path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := store.Load(address)
+	if err != nil {
+		t.Fatal(err)
+	} else if state == nil || len(state.InFlight) != 5 {
+		t.Fatalf("expected 5 in-flight Txns to be saved, got %v", state)
+	}
+
+	cc.opts.NonceStore = store
+	key = newSigningKey(client.Context{}.WithFromAddress(address), NewTxFactory(client.Context{}), nil, 1)
+	key.accNum = state.AccountNumber
+	key.inFlight = state.InFlight
+
+	// 9 is committed, 10 and 11 are pending, 12 is dropped, so 13 will never be valid
+	cc.reconcileNonce(key, 10)
+	if key.accSeq != 12 {
+		t.Fatalf("expected next sequence 12, got %d", key.accSeq)
+	} else if len(key.inFlight) != 2 {
+		t.Fatalf("expected 2 pending Txns, got %v", key.inFlight)
+	}
+
+	state, _ = store.Load(address)
+	if state.NextSequence != 12 || len(state.InFlight) != 2 {
+		t.Fatalf("expected reconciled state to be saved, got %v", state)
+	}
+}
+
+func TestReconcileNonceTruncatedMempool(t *testing.T) {
+	address := sdk.AccAddress("test_address________")
+	mempool := &mempoolClient{
+		txs:   []tmtypes.Tx{tmtypes.Tx("tx 10"), tmtypes.Tx("tx 11"), tmtypes.Tx("tx 12")},
+		limit: 1,
+	}
+
+	cc := &cosmosClient{
+		opts:   &cosmosClientOptions{NonceStore: NewMemoryNonceStore()},
+		logger: log.DefaultLogger,
+		nodes:  newNodePool([]*chainNode{{tmClient: mempool}}, time.Second, 3, log.DefaultLogger),
+	}
+
+	key := newSigningKey(client.Context{}.WithFromAddress(address), NewTxFactory(client.Context{}), nil, 1)
+	for seq := uint64(10); seq <= 12; seq++ {
+		key.trackInFlight(seq, testInFlightTx(seq))
+	}
+
+	// 11 and 12 are not listed, they're assumed pending below the persisted sequence only
+	key.accSeq = 12
+	cc.reconcileNonce(key, 10)
+	if key.accSeq != 12 || len(key.inFlight) != 2 {
+		t.Fatalf("expected next sequence 12 with 2 pending Txns, got %d with %v", key.accSeq, key.inFlight)
+	}
+
+	// the whole mempool is listed, 11 is gone
+	mempool.txs, mempool.limit = mempool.txs[:1], 0
+	cc.reconcileNonce(key, 10)
+	if key.accSeq != 11 || len(key.inFlight) != 1 {
+		t.Fatalf("expected next sequence 11 with 1 pending Tx, got %d with %v", key.accSeq, key.inFlight)
+	}
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"google.golang.org/grpc"
+)
+
+// Observer receives instrumentation events of the chain and exchange API clients.
+// Methods are called on the hot path, so implementations must be fast and safe for concurrent use.
+// Embed NopObserver to implement only some of the methods.
+type Observer interface {
+	// ObserveBroadcast reports the time it took the node to accept or reject a Tx in CheckTx.
+	ObserveBroadcast(latency time.Duration, err error)
+	// ObserveInclusion reports the time from the Tx acceptance to its inclusion in a block,
+	// for broadcasts that wait for inclusion. err is set if the Tx failed or hasn't been seen in time.
+	ObserveInclusion(latency time.Duration, err error)
+	// ObserveQueueDepth reports the amount of messages waiting in the broadcast queue of the key.
+	ObserveQueueDepth(key sdk.AccAddress, depth int)
+	// ObserveBatchSize reports the amount of queued messages sent in one Tx.
+	ObserveBatchSize(size int)
+	// ObserveSequenceResync reports that the account sequence of the key has been resynced from the chain.
+	ObserveSequenceResync(key sdk.AccAddress)
+	// ObserveGRPCCall reports the duration of an unary gRPC call.
+	ObserveGRPCCall(method string, duration time.Duration, err error)
+	// ObserveGRPCStream reports the lifetime of a gRPC stream, once the stream ends.
+	// err is nil if the server has closed the stream gracefully.
+	ObserveGRPCStream(method string, duration time.Duration, err error)
+	// ObserveStreamReconnect reports a reconnect of an event stream, e.g. a Tendermint subscription.
+	ObserveStreamReconnect(stream string)
+}
+
+// NopObserver is an Observer that ignores all events.
+type NopObserver struct{}
+
+func (NopObserver) ObserveBroadcast(time.Duration, error)          {}
+func (NopObserver) ObserveInclusion(time.Duration, error)          {}
+func (NopObserver) ObserveQueueDepth(sdk.AccAddress, int)          {}
+func (NopObserver) ObserveBatchSize(int)                           {}
+func (NopObserver) ObserveSequenceResync(sdk.AccAddress)           {}
+func (NopObserver) ObserveGRPCCall(string, time.Duration, error)   {}
+func (NopObserver) ObserveGRPCStream(string, time.Duration, error) {}
+func (NopObserver) ObserveStreamReconnect(string)                  {}
+
+// DialOptionObserver reports durations of gRPC calls and streams to the observer.
+// Use it for exchange API clients, chain clients get it with OptionObserver.
+func DialOptionObserver(observer Observer) DialOption {
+	return DialOptionGRPC(
+		grpc.WithChainUnaryInterceptor(observerUnaryInterceptor(observer)),
+		grpc.WithChainStreamInterceptor(observerStreamInterceptor(observer)),
+	)
+}
+
+func observerUnaryInterceptor(observer Observer) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		observer.ObserveGRPCCall(method, time.Since(start), err)
+
+		return err
+	}
+}
+
+func observerStreamInterceptor(observer Observer) grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		start := time.Now()
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			observer.ObserveGRPCStream(method, time.Since(start), err)
+			return nil, err
+		}
+
+		return &observedStream{
+			ClientStream: stream,
+			observer:     observer,
+			method:       method,
+			start:        start,
+			once:         new(sync.Once),
+		}, nil
+	}
+}
+
+// observedStream reports the stream once receiving from it fails, which is how a stream ends.
+type observedStream struct {
+	grpc.ClientStream
+
+	observer Observer
+	method   string
+	start    time.Time
+	once     *sync.Once
+}
+
+func (s *observedStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		s.once.Do(func() {
+			streamErr := err
+			if err == io.EOF {
+				streamErr = nil
+			}
+
+			s.observer.ObserveGRPCStream(s.method, time.Since(s.start), streamErr)
+		})
+	}
+
+	return err
+}
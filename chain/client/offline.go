@@ -0,0 +1,286 @@
+package client
+
+import (
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/cosmos/cosmos-sdk/crypto/types/multisig"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/pkg/errors"
+)
+
+// OfflineTxParams contains everything that is normally fetched from the chain
+// or estimated by simulation, so a Tx can be built and signed without connection to a node.
+type OfflineTxParams struct {
+	AccountNumber uint64
+	Sequence      uint64
+
+	// GasLimit is required, since the Tx cannot be simulated offline.
+	GasLimit uint64
+	// Fees are the fixed fees to pay, e.g. "100000000000000inj". Ignored if GasPrices are set.
+	Fees string
+	// GasPrices are used to compute fees from GasLimit, e.g. "500000000inj".
+	GasPrices string
+
+	Memo          string
+	TimeoutHeight uint64
+}
+
+// NewOfflineClientContext creates a new Cosmos Client context that is not expected to reach
+// the chain, e.g. for cold-wallet signing. Keyring is required to contain the specified key.
+func NewOfflineClientContext(
+	chainId, fromSpec string, kb keyring.Keyring,
+) (client.Context, error) {
+	clientCtx, err := NewClientContext(chainId, fromSpec, kb)
+	if err != nil {
+		return clientCtx, err
+	}
+
+	clientCtx = clientCtx.WithOffline(true).WithGenerateOnly(true)
+	return clientCtx, nil
+}
+
+// NewOfflineTxFactory creates a Tx factory that uses the provided params
+// instead of querying the chain and simulating the Tx.
+func NewOfflineTxFactory(clientCtx client.Context, params OfflineTxParams) (tx.Factory, error) {
+	if params.GasLimit == 0 {
+		err := errors.New("gas limit must be set for offline Tx")
+		return tx.Factory{}, err
+	}
+
+	txf := NewTxFactory(clientCtx).
+		WithSimulateAndExecute(false).
+		WithAccountNumber(params.AccountNumber).
+		WithSequence(params.Sequence).
+		WithGas(params.GasLimit).
+		WithMemo(params.Memo).
+		WithTimeoutHeight(params.TimeoutHeight)
+
+	if len(params.GasPrices) > 0 {
+		if _, err := sdk.ParseDecCoins(params.GasPrices); err != nil {
+			err = errors.Wrapf(err, "failed to ParseDecCoins %s", params.GasPrices)
+			return txf, err
+		}
+
+		txf = txf.WithGasPrices(params.GasPrices)
+	} else if len(params.Fees) > 0 {
+		if _, err := sdk.ParseCoinsNormalized(params.Fees); err != nil {
+			err = errors.Wrapf(err, "failed to ParseCoinsNormalized %s", params.Fees)
+			return txf, err
+		}
+
+		txf = txf.WithFees(params.Fees)
+	}
+
+	return txf, nil
+}
+
+// BuildOfflineTx builds an unsigned Tx with arbitrary messages.
+func BuildOfflineTx(clientCtx client.Context, params OfflineTxParams, msgs ...sdk.Msg) (client.TxBuilder, error) {
+	txf, err := NewOfflineTxFactory(clientCtx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	txBuilder, err := tx.BuildUnsignedTx(txf, msgs...)
+	if err != nil {
+		err = errors.Wrap(err, "failed to BuildUnsignedTx")
+		return nil, err
+	}
+
+	txBuilder.SetFeeGranter(clientCtx.GetFeeGranterAddress())
+	return txBuilder, nil
+}
+
+// SignOfflineTx signs the Tx with the key from client context. The signature is appended to
+// the existing ones, unless overwrite is set, so a Tx with multiple signers can be signed in turns.
+func SignOfflineTx(clientCtx client.Context, params OfflineTxParams, txBuilder client.TxBuilder, overwrite bool) error {
+	txf, err := NewOfflineTxFactory(clientCtx, params)
+	if err != nil {
+		return err
+	}
+
+	if !overwrite {
+		// direct mode doesn't support multiple signers
+		txf = txf.WithSignMode(signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON)
+	}
+
+	if err := tx.Sign(txf, clientCtx.GetFromName(), txBuilder, overwrite); err != nil {
+		err = errors.Wrap(err, "failed to Sign Tx")
+		return err
+	}
+
+	return nil
+}
+
+// SignOfflineTxWithSigner signs the Tx using a custom signer, that is not required to be in
+// the keyring. The signature is appended to the existing ones, unless overwrite is set.
+func SignOfflineTxWithSigner(
+	clientCtx client.Context,
+	params OfflineTxParams,
+	txBuilder client.TxBuilder,
+	signer TxSigner,
+	overwrite bool,
+) error {
+	txf, err := NewOfflineTxFactory(clientCtx, params)
+	if err != nil {
+		return err
+	}
+
+	if !overwrite {
+		// direct mode doesn't support multiple signers
+		txf = txf.WithSignMode(signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON)
+	}
+
+	if err := signTx(clientCtx.TxConfig, txf, signer, txBuilder, overwrite); err != nil {
+		err = errors.Wrap(err, "failed to Sign Tx")
+		return err
+	}
+
+	return nil
+}
+
+// SignOfflineTxEIP712 signs the Tx with EIP-712 typed data using an Ethereum key,
+// and attaches the Web3Tx extension option. Previous signatures are overwritten.
+func SignOfflineTxEIP712(
+	clientCtx client.Context,
+	params OfflineTxParams,
+	txBuilder client.TxBuilder,
+	signer TxSigner,
+	opts EIP712Options,
+) error {
+	txf, err := NewOfflineTxFactory(clientCtx, params)
+	if err != nil {
+		return err
+	}
+
+	if err := signTxEIP712(clientCtx, txf, signer, txBuilder, &opts); err != nil {
+		err = errors.Wrap(err, "failed to Sign Tx")
+		return err
+	}
+
+	return nil
+}
+
+// SignMultisigPart produces a signature of the Tx by the key from client context,
+// on behalf of a multisig account. Params must specify the account number and sequence
+// of the multisig account. The Tx itself is not modified, so all parties can sign the same builder.
+// Signatures collected from all parties are combined using CombineMultisig.
+func SignMultisigPart(clientCtx client.Context, params OfflineTxParams, txBuilder client.TxBuilder) (signing.SignatureV2, error) {
+	txf, err := NewOfflineTxFactory(clientCtx, params)
+	if err != nil {
+		return signing.SignatureV2{}, err
+	}
+
+	// multisig works only with amino JSON signing
+	txf = txf.WithSignMode(signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON)
+
+	// signing a copy, so the signatures set on the caller's Tx are left untouched
+	txBytes, err := ExportTxBytes(clientCtx, txBuilder)
+	if err != nil {
+		err = errors.Wrap(err, "failed to encode Tx")
+		return signing.SignatureV2{}, err
+	}
+
+	partTx, err := ImportTxBytes(clientCtx, txBytes)
+	if err != nil {
+		return signing.SignatureV2{}, err
+	}
+
+	if err := tx.Sign(txf, clientCtx.GetFromName(), partTx, true); err != nil {
+		err = errors.Wrap(err, "failed to Sign Tx")
+		return signing.SignatureV2{}, err
+	}
+
+	sigs, err := partTx.GetTx().GetSignaturesV2()
+	if err != nil {
+		err = errors.Wrap(err, "failed to get Tx signatures")
+		return signing.SignatureV2{}, err
+	} else if len(sigs) != 1 {
+		err = errors.Errorf("expected one signature, got %d", len(sigs))
+		return signing.SignatureV2{}, err
+	}
+
+	return sigs[0], nil
+}
+
+// CombineMultisig combines the signatures of the multisig parties and sets the resulting
+// multisig signature on the Tx. Params must specify the sequence of the multisig account.
+func CombineMultisig(
+	params OfflineTxParams,
+	txBuilder client.TxBuilder,
+	multisigPubKey cryptotypes.PubKey,
+	sigs ...signing.SignatureV2,
+) error {
+	multisigPub, ok := multisigPubKey.(multisig.PubKey)
+	if !ok {
+		err := errors.Errorf("expected multisig pubkey, got %T", multisigPubKey)
+		return err
+	}
+
+	pubKeys := multisigPub.GetPubKeys()
+	multisigSig := multisig.NewMultisig(len(pubKeys))
+	for _, sig := range sigs {
+		if err := multisig.AddSignatureV2(multisigSig, sig, pubKeys); err != nil {
+			err = errors.Wrap(err, "failed to add signature to multisig")
+			return err
+		}
+	}
+
+	sigV2 := signing.SignatureV2{
+		PubKey:   multisigPubKey,
+		Data:     multisigSig,
+		Sequence: params.Sequence,
+	}
+
+	if err := txBuilder.SetSignatures(sigV2); err != nil {
+		err = errors.Wrap(err, "failed to set multisig signature")
+		return err
+	}
+
+	return nil
+}
+
+// ExportTxJSON encodes the Tx into JSON, to be stored in a file or passed around for signing.
+func ExportTxJSON(clientCtx client.Context, txBuilder client.TxBuilder) ([]byte, error) {
+	return clientCtx.TxConfig.TxJSONEncoder()(txBuilder.GetTx())
+}
+
+// ImportTxJSON decodes a Tx exported with ExportTxJSON.
+func ImportTxJSON(clientCtx client.Context, txJSON []byte) (client.TxBuilder, error) {
+	txn, err := clientCtx.TxConfig.TxJSONDecoder()(txJSON)
+	if err != nil {
+		err = errors.Wrap(err, "failed to decode Tx JSON")
+		return nil, err
+	}
+
+	return clientCtx.TxConfig.WrapTxBuilder(txn)
+}
+
+// ExportTxBytes encodes the Tx into protobuf bytes, that can be broadcasted with BroadcastSignedTx.
+func ExportTxBytes(clientCtx client.Context, txBuilder client.TxBuilder) ([]byte, error) {
+	return clientCtx.TxConfig.TxEncoder()(txBuilder.GetTx())
+}
+
+// ImportTxBytes decodes a Tx exported with ExportTxBytes.
+func ImportTxBytes(clientCtx client.Context, txBytes []byte) (client.TxBuilder, error) {
+	txn, err := clientCtx.TxConfig.TxDecoder()(txBytes)
+	if err != nil {
+		err = errors.Wrap(err, "failed to decode Tx bytes")
+		return nil, err
+	}
+
+	return clientCtx.TxConfig.WrapTxBuilder(txn)
+}
+
+// ExportSignaturesJSON encodes signatures, e.g. multisig parts, into JSON.
+func ExportSignaturesJSON(clientCtx client.Context, sigs ...signing.SignatureV2) ([]byte, error) {
+	return clientCtx.TxConfig.MarshalSignatureJSON(sigs)
+}
+
+// ImportSignaturesJSON decodes signatures exported with ExportSignaturesJSON.
+func ImportSignaturesJSON(clientCtx client.Context, sigsJSON []byte) ([]signing.SignatureV2, error) {
+	return clientCtx.TxConfig.UnmarshalSignatureJSON(sigsJSON)
+}
@@ -0,0 +1,169 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	kmultisig "github.com/cosmos/cosmos-sdk/crypto/keys/multisig"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	"github.com/InjectiveLabs/sdk-go/chain/crypto/ethsecp256k1"
+)
+
+func TestOfflineTxRoundtrip(t *testing.T) {
+	privKey, err := ethsecp256k1.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kb, err := KeyringForPrivKey("cold", privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientCtx, err := NewOfflineClientContext("injective-888", "cold", kb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from := clientCtx.GetFromAddress()
+	msg := banktypes.NewMsgSend(from, from, sdk.NewCoins(sdk.NewInt64Coin("inj", 1)))
+	params := OfflineTxParams{
+		AccountNumber: 7,
+		Sequence:      42,
+		GasLimit:      200000,
+		Fees:          "100000000000000inj",
+		Memo:          "cold",
+	}
+
+	txBuilder, err := BuildOfflineTx(clientCtx, params, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txJSON, err := ExportTxJSON(clientCtx, txBuilder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txBuilder, err = ImportTxJSON(clientCtx, txJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SignOfflineTx(clientCtx, params, txBuilder, true); err != nil {
+		t.Fatal(err)
+	}
+
+	txBytes, err := ExportTxBytes(clientCtx, txBuilder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signedTx, err := ImportTxBytes(clientCtx, txBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sigs, err := signedTx.GetTx().GetSignaturesV2()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(sigs) != 1 {
+		t.Fatalf("expected 1 signature, got %d", len(sigs))
+	} else if sigs[0].Sequence != params.Sequence {
+		t.Fatalf("expected signature sequence %d, got %d", params.Sequence, sigs[0].Sequence)
+	} else if signedTx.GetTx().GetMemo() != params.Memo {
+		t.Fatalf("expected memo %s, got %s", params.Memo, signedTx.GetTx().GetMemo())
+	}
+}
+
+func TestMultisigRoundtrip(t *testing.T) {
+	var (
+		parties = make([]client.Context, 3)
+		pubKeys = make([]cryptotypes.PubKey, 3)
+	)
+
+	for idx := range parties {
+		privKey, err := ethsecp256k1.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		kb, err := KeyringForPrivKey("party", privKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if parties[idx], err = NewOfflineClientContext("injective-888", "party", kb); err != nil {
+			t.Fatal(err)
+		}
+
+		pubKeys[idx] = privKey.PubKey()
+	}
+
+	multisigPubKey := kmultisig.NewLegacyAminoPubKey(2, pubKeys)
+	from := sdk.AccAddress(multisigPubKey.Address())
+	params := OfflineTxParams{
+		AccountNumber: 7,
+		Sequence:      42,
+		GasLimit:      200000,
+		Fees:          "100000000000000inj",
+	}
+
+	txBuilder, err := BuildOfflineTx(parties[0], params, banktypes.NewMsgSend(from, from, sdk.NewCoins(sdk.NewInt64Coin("inj", 1))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2 of 3 parties sign the same Tx
+	var sigs []signing.SignatureV2
+	for _, party := range []client.Context{parties[0], parties[2]} {
+		sig, err := SignMultisigPart(party, params, txBuilder)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sigs = append(sigs, sig)
+	}
+
+	if prev, err := txBuilder.GetTx().GetSignaturesV2(); err != nil || len(prev) != 0 {
+		t.Fatalf("expected the Tx to stay unsigned by the parties, got %d signatures, %v", len(prev), err)
+	}
+
+	if err := CombineMultisig(params, txBuilder, multisigPubKey, sigs...); err != nil {
+		t.Fatal(err)
+	}
+
+	txBytes, err := ExportTxBytes(parties[1], txBuilder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signedTx, err := ImportTxBytes(parties[1], txBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txn := signedTx.GetTx()
+	txSigs, err := txn.GetSignaturesV2()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(txSigs) != 1 {
+		t.Fatalf("expected 1 multisig signature, got %d", len(txSigs))
+	}
+
+	signerData := authsigning.SignerData{
+		ChainID:       "injective-888",
+		AccountNumber: params.AccountNumber,
+		Sequence:      params.Sequence,
+	}
+
+	handler := parties[1].TxConfig.SignModeHandler()
+	if err := authsigning.VerifySignature(multisigPubKey, signerData, txSigs[0].Data, handler, txn); err != nil {
+		t.Fatalf("multisig signature doesn't verify: %v", err)
+	}
+}
@@ -0,0 +1,402 @@
+package client
+
+import (
+	"context"
+	"strconv"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	gogogrpc "github.com/gogo/protobuf/grpc"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	auctiontypes "github.com/InjectiveLabs/sdk-go/chain/auction/types"
+	erc20bridgetypes "github.com/InjectiveLabs/sdk-go/chain/erc20bridge/types"
+	evmtypes "github.com/InjectiveLabs/sdk-go/chain/evm/types"
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+	insurancetypes "github.com/InjectiveLabs/sdk-go/chain/insurance/types"
+	oracletypes "github.com/InjectiveLabs/sdk-go/chain/oracle/types"
+	peggytypes "github.com/InjectiveLabs/sdk-go/chain/peggy/types"
+)
+
+// queryPageLimit is the page size used when fetching all pages of a list query.
+const queryPageLimit = 100
+
+// ChainQueryClient exposes typed queries of all chain modules over one gRPC connection.
+// Module query clients are available as fields, list queries are wrapped to fetch all pages.
+// All queries are made at the pinned height, or at the latest height if none is pinned.
+type ChainQueryClient struct {
+	conn              gogogrpc.ClientConn
+	interfaceRegistry codectypes.InterfaceRegistry
+	height            int64
+
+	Exchange    exchangetypes.QueryClient
+	Oracle      oracletypes.QueryClient
+	Insurance   insurancetypes.QueryClient
+	Auction     auctiontypes.QueryClient
+	Peggy       peggytypes.QueryClient
+	EVM         evmtypes.QueryClient
+	ERC20Bridge erc20bridgetypes.QueryClient
+	Bank        banktypes.QueryClient
+	Auth        authtypes.QueryClient
+}
+
+// NewChainQueryClient creates a query client over conn. The interface registry
+// is used to unpack Any values, e.g. accounts.
+func NewChainQueryClient(conn gogogrpc.ClientConn, interfaceRegistry codectypes.InterfaceRegistry) *ChainQueryClient {
+	return newChainQueryClient(conn, interfaceRegistry, 0)
+}
+
+func newChainQueryClient(conn gogogrpc.ClientConn, interfaceRegistry codectypes.InterfaceRegistry, height int64) *ChainQueryClient {
+	pinnedConn := &heightPinnedConn{
+		conn:   conn,
+		height: height,
+	}
+
+	return &ChainQueryClient{
+		conn:              conn,
+		interfaceRegistry: interfaceRegistry,
+		height:            height,
+
+		Exchange:    exchangetypes.NewQueryClient(pinnedConn),
+		Oracle:      oracletypes.NewQueryClient(pinnedConn),
+		Insurance:   insurancetypes.NewQueryClient(pinnedConn),
+		Auction:     auctiontypes.NewQueryClient(pinnedConn),
+		Peggy:       peggytypes.NewQueryClient(pinnedConn),
+		EVM:         evmtypes.NewQueryClient(pinnedConn),
+		ERC20Bridge: erc20bridgetypes.NewQueryClient(pinnedConn),
+		Bank:        banktypes.NewQueryClient(pinnedConn),
+		Auth:        authtypes.NewQueryClient(pinnedConn),
+	}
+}
+
+// AtHeight returns a view of the client with all queries answered from block at height h.
+// Zero height means the latest block.
+func (q *ChainQueryClient) AtHeight(h int64) *ChainQueryClient {
+	return newChainQueryClient(q.conn, q.interfaceRegistry, h)
+}
+
+// AtLatestHeight returns a view pinned to the current latest block, so a set of
+// queries made through it is consistent even if new blocks are produced meanwhile.
+func (q *ChainQueryClient) AtLatestHeight(ctx context.Context) (*ChainQueryClient, error) {
+	var header metadata.MD
+	if _, err := q.Auth.Params(ctx, &authtypes.QueryParamsRequest{}, grpc.Header(&header)); err != nil {
+		err = errors.Wrap(err, "failed to query latest height")
+		return nil, err
+	}
+
+	height, err := heightFromHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.AtHeight(height), nil
+}
+
+// Height returns the pinned height, zero if queries are made at the latest height.
+func (q *ChainQueryClient) Height() int64 {
+	return q.height
+}
+
+// SpotMarkets returns spot markets with the given status, all markets if status is unspecified.
+func (q *ChainQueryClient) SpotMarkets(ctx context.Context, status exchangetypes.MarketStatus) ([]*exchangetypes.SpotMarket, error) {
+	res, err := q.Exchange.SpotMarkets(ctx, &exchangetypes.QuerySpotMarketsRequest{
+		Status: status,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Markets, nil
+}
+
+// DerivativeMarkets returns derivative markets with the given status, all markets if status is unspecified.
+func (q *ChainQueryClient) DerivativeMarkets(ctx context.Context, status exchangetypes.MarketStatus) ([]*exchangetypes.FullDerivativeMarket, error) {
+	res, err := q.Exchange.DerivativeMarkets(ctx, &exchangetypes.QueryDerivativeMarketsRequest{
+		Status: status,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Markets, nil
+}
+
+// SubaccountDeposits returns deposits of the subaccount, keyed by denom.
+func (q *ChainQueryClient) SubaccountDeposits(ctx context.Context, subaccountID string) (map[string]*exchangetypes.Deposit, error) {
+	res, err := q.Exchange.SubaccountDeposits(ctx, &exchangetypes.QuerySubaccountDepositsRequest{
+		SubaccountId: subaccountID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Deposits, nil
+}
+
+// Positions returns all derivative positions.
+func (q *ChainQueryClient) Positions(ctx context.Context) ([]exchangetypes.DerivativePosition, error) {
+	res, err := q.Exchange.Positions(ctx, &exchangetypes.QueryPositionsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.State, nil
+}
+
+// InsuranceFunds returns all insurance funds.
+func (q *ChainQueryClient) InsuranceFunds(ctx context.Context) ([]insurancetypes.InsuranceFund, error) {
+	res, err := q.Insurance.InsuranceFunds(ctx, &insurancetypes.QueryInsuranceFundsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Funds, nil
+}
+
+// PriceFeedPriceStates returns all price feed oracle prices.
+func (q *ChainQueryClient) PriceFeedPriceStates(ctx context.Context) ([]*oracletypes.PriceFeedState, error) {
+	res, err := q.Oracle.PriceFeedPriceStates(ctx, &oracletypes.QueryPriceFeedPriceStatesRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.PriceStates, nil
+}
+
+// BandPriceStates returns all Band oracle prices.
+func (q *ChainQueryClient) BandPriceStates(ctx context.Context) ([]*oracletypes.BandPriceState, error) {
+	res, err := q.Oracle.BandPriceStates(ctx, &oracletypes.QueryBandPriceStatesRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.PriceStates, nil
+}
+
+// AllBalances returns all bank balances of the address, fetching all pages.
+func (q *ChainQueryClient) AllBalances(ctx context.Context, address sdk.AccAddress) (sdk.Coins, error) {
+	var balances sdk.Coins
+
+	err := q.paginate(ctx, func(ctx context.Context, pageReq *query.PageRequest, opts ...grpc.CallOption) (*query.PageResponse, error) {
+		res, err := q.Bank.AllBalances(ctx, &banktypes.QueryAllBalancesRequest{
+			Address:    address.String(),
+			Pagination: pageReq,
+		}, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		balances = append(balances, res.Balances...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		err = errors.Wrap(err, "failed to query balances")
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+// TotalSupply returns total supply of all denoms, fetching all pages.
+func (q *ChainQueryClient) TotalSupply(ctx context.Context) (sdk.Coins, error) {
+	var supply sdk.Coins
+
+	err := q.paginate(ctx, func(ctx context.Context, pageReq *query.PageRequest, opts ...grpc.CallOption) (*query.PageResponse, error) {
+		res, err := q.Bank.TotalSupply(ctx, &banktypes.QueryTotalSupplyRequest{
+			Pagination: pageReq,
+		}, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		supply = append(supply, res.Supply...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		err = errors.Wrap(err, "failed to query total supply")
+		return nil, err
+	}
+
+	return supply, nil
+}
+
+// DenomsMetadata returns metadata of all denoms, fetching all pages.
+func (q *ChainQueryClient) DenomsMetadata(ctx context.Context) ([]banktypes.Metadata, error) {
+	var metadatas []banktypes.Metadata
+
+	err := q.paginate(ctx, func(ctx context.Context, pageReq *query.PageRequest, opts ...grpc.CallOption) (*query.PageResponse, error) {
+		res, err := q.Bank.DenomsMetadata(ctx, &banktypes.QueryDenomsMetadataRequest{
+			Pagination: pageReq,
+		}, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		metadatas = append(metadatas, res.Metadatas...)
+		return res.Pagination, nil
+	})
+	if err != nil {
+		err = errors.Wrap(err, "failed to query denoms metadata")
+		return nil, err
+	}
+
+	return metadatas, nil
+}
+
+// Account returns the account at address.
+func (q *ChainQueryClient) Account(ctx context.Context, address sdk.AccAddress) (authtypes.AccountI, error) {
+	res, err := q.Auth.Account(ctx, &authtypes.QueryAccountRequest{
+		Address: address.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var account authtypes.AccountI
+	if err := q.interfaceRegistry.UnpackAny(res.Account, &account); err != nil {
+		err = errors.Wrap(err, "failed to unpack account")
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// Accounts returns all accounts, fetching all pages.
+func (q *ChainQueryClient) Accounts(ctx context.Context) ([]authtypes.AccountI, error) {
+	var accounts []authtypes.AccountI
+
+	err := q.paginate(ctx, func(ctx context.Context, pageReq *query.PageRequest, opts ...grpc.CallOption) (*query.PageResponse, error) {
+		res, err := q.Auth.Accounts(ctx, &authtypes.QueryAccountsRequest{
+			Pagination: pageReq,
+		}, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, accountAny := range res.Accounts {
+			var account authtypes.AccountI
+			if err := q.interfaceRegistry.UnpackAny(accountAny, &account); err != nil {
+				err = errors.Wrap(err, "failed to unpack account")
+				return nil, err
+			}
+
+			accounts = append(accounts, account)
+		}
+
+		return res.Pagination, nil
+	})
+	if err != nil {
+		err = errors.Wrap(err, "failed to query accounts")
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+type pageQueryFunc func(ctx context.Context, pageReq *query.PageRequest, opts ...grpc.CallOption) (*query.PageResponse, error)
+
+// paginate calls queryPage until all pages are fetched. If the client is not pinned to a height,
+// pages after the first one are queried at the height of the first one, so the result is consistent.
+func (q *ChainQueryClient) paginate(ctx context.Context, queryPage pageQueryFunc) error {
+	pageReq := &query.PageRequest{
+		Limit: queryPageLimit,
+	}
+
+	var header metadata.MD
+	pageRes, err := queryPage(ctx, pageReq, grpc.Header(&header))
+	if err != nil {
+		return err
+	}
+
+	if q.height == 0 && pageRes != nil && len(pageRes.NextKey) > 0 {
+		height, err := heightFromHeader(header)
+		if err != nil {
+			return err
+		}
+
+		return q.AtHeight(height).paginateFrom(ctx, pageRes.NextKey, queryPage)
+	}
+
+	return q.paginateFrom(ctx, nextKeyOf(pageRes), queryPage)
+}
+
+func (q *ChainQueryClient) paginateFrom(ctx context.Context, nextKey []byte, queryPage pageQueryFunc) error {
+	for len(nextKey) > 0 {
+		pageRes, err := queryPage(q.pinContext(ctx), &query.PageRequest{
+			Key:   nextKey,
+			Limit: queryPageLimit,
+		})
+		if err != nil {
+			return err
+		}
+
+		nextKey = nextKeyOf(pageRes)
+	}
+
+	return nil
+}
+
+// pinContext attaches the pinned height to ctx. Needed where queryPage uses clients of another view.
+func (q *ChainQueryClient) pinContext(ctx context.Context) context.Context {
+	if q.height == 0 {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, grpctypes.GRPCBlockHeightHeader, strconv.FormatInt(q.height, 10))
+}
+
+func nextKeyOf(pageRes *query.PageResponse) []byte {
+	if pageRes == nil {
+		return nil
+	}
+
+	return pageRes.NextKey
+}
+
+func heightFromHeader(header metadata.MD) (int64, error) {
+	values := header.Get(grpctypes.GRPCBlockHeightHeader)
+	if len(values) == 0 {
+		err := errors.New("no block height in the response header")
+		return 0, err
+	}
+
+	height, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil {
+		err = errors.Wrap(err, "failed to parse block height header")
+		return 0, err
+	}
+
+	return height, nil
+}
+
+// heightPinnedConn makes every call at the pinned height, unless the call context already has one.
+type heightPinnedConn struct {
+	conn   gogogrpc.ClientConn
+	height int64
+}
+
+func (c *heightPinnedConn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	return c.conn.Invoke(c.withHeight(ctx), method, args, reply, opts...)
+}
+
+func (c *heightPinnedConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return c.conn.NewStream(c.withHeight(ctx), desc, method, opts...)
+}
+
+func (c *heightPinnedConn) withHeight(ctx context.Context) context.Context {
+	if c.height == 0 {
+		return ctx
+	}
+
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(grpctypes.GRPCBlockHeightHeader)) > 0 {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, grpctypes.GRPCBlockHeightHeader, strconv.FormatInt(c.height, 10))
+}
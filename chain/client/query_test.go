@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	"github.com/cosmos/cosmos-sdk/types/query"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestChainQueryClientPagination(t *testing.T) {
+	q := NewChainQueryClient(nil, nil).AtHeight(42)
+
+	pages := map[string][]byte{
+		"":  []byte("b"),
+		"b": []byte("c"),
+		"c": nil,
+	}
+
+	var visited []string
+	err := q.paginate(context.Background(), func(ctx context.Context, pageReq *query.PageRequest, _ ...grpc.CallOption) (*query.PageResponse, error) {
+		if pageReq.Limit != queryPageLimit {
+			t.Fatalf("unexpected page limit %d", pageReq.Limit)
+		}
+
+		if len(pageReq.Key) > 0 {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			if heights := md.Get(grpctypes.GRPCBlockHeightHeader); len(heights) != 1 || heights[0] != "42" {
+				t.Fatalf("expected page to be queried at pinned height, got %v", heights)
+			}
+		}
+
+		visited = append(visited, string(pageReq.Key))
+		return &query.PageResponse{
+			NextKey: pages[string(pageReq.Key)],
+		}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(visited) != 3 {
+		t.Fatalf("expected 3 pages, got %v", visited)
+	}
+}
+
+func TestHeightPinnedConn(t *testing.T) {
+	conn := &heightPinnedConn{height: 7}
+
+	md, _ := metadata.FromOutgoingContext(conn.withHeight(context.Background()))
+	if heights := md.Get(grpctypes.GRPCBlockHeightHeader); len(heights) != 1 || heights[0] != "7" {
+		t.Fatalf("expected pinned height, got %v", heights)
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), grpctypes.GRPCBlockHeightHeader, "3")
+	md, _ = metadata.FromOutgoingContext(conn.withHeight(ctx))
+	if heights := md.Get(grpctypes.GRPCBlockHeightHeader); len(heights) != 1 || heights[0] != "3" {
+		t.Fatalf("expected explicit height to be kept, got %v", heights)
+	}
+}
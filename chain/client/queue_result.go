@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// MsgResult describes the delivery outcome of a single queued message.
+type MsgResult struct {
+	// TxHash is the hash of the Tx that carried the message, empty if the Tx was never broadcasted.
+	TxHash string
+	// Height of the block where the Tx has been included, zero if not included.
+	Height int64
+	// Code is the ABCI result code of the Tx, 0 means success.
+	Code      uint32
+	Codespace string
+	RawLog    string
+
+	// MsgIndex is the index of the message within the Tx.
+	MsgIndex int
+	// Events emitted by this message during the Tx execution.
+	Events sdk.StringEvents
+
+	// Err is set when the message has not been delivered successfully.
+	Err error
+}
+
+// MsgResultFuture is a handle that resolves once the message it has been returned for
+// is either delivered on chain or failed to be delivered.
+type MsgResultFuture struct {
+	doneC  chan struct{}
+	result *MsgResult
+}
+
+func newMsgResultFuture() *MsgResultFuture {
+	return &MsgResultFuture{
+		doneC: make(chan struct{}),
+	}
+}
+
+// NewResolvedMsgResultFuture returns a future that is already resolved with the result,
+// e.g. for test doubles of CosmosClient.
+func NewResolvedMsgResultFuture(result *MsgResult) *MsgResultFuture {
+	f := newMsgResultFuture()
+	f.resolve(result)
+	return f
+}
+
+// Done returns a channel that is closed when the result is available.
+func (f *MsgResultFuture) Done() <-chan struct{} {
+	return f.doneC
+}
+
+// Result returns the delivery result, or nil if it's not yet available.
+func (f *MsgResultFuture) Result() *MsgResult {
+	select {
+	case <-f.doneC:
+		return f.result
+	default:
+		return nil
+	}
+}
+
+// Wait blocks until the result is available or ctx is done. The returned error is
+// either ctx error or the delivery error of the message.
+func (f *MsgResultFuture) Wait(ctx context.Context) (*MsgResult, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-f.doneC:
+		return f.result, f.result.Err
+	}
+}
+
+func (f *MsgResultFuture) resolve(result *MsgResult) {
+	if f == nil {
+		return
+	}
+
+	f.result = result
+	close(f.doneC)
+}
+
+type queuedMsg struct {
+	msg    sdk.Msg
+	future *MsgResultFuture
+}
+
+// resolveBatch resolves all futures of the batch using the broadcast outcome of the Tx.
+func resolveBatch(batch []*queuedMsg, res *sdk.TxResponse, err error) {
+	if err == nil && res != nil && res.Code != 0 {
+		err = chainErrorOf(res)
+	}
+
+	for idx, qm := range batch {
+		if qm.future == nil {
+			continue
+		}
+
+		result := &MsgResult{
+			MsgIndex: idx,
+			Err:      err,
+		}
+
+		if res != nil {
+			result.TxHash = res.TxHash
+			result.Height = res.Height
+			result.Code = res.Code
+			result.Codespace = res.Codespace
+			result.RawLog = res.RawLog
+
+			for _, msgLog := range res.Logs {
+				if int(msgLog.MsgIndex) == idx {
+					result.Events = msgLog.Events
+					break
+				}
+			}
+		}
+
+		qm.future.resolve(result)
+	}
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/pkg/errors"
+)
+
+func testBatch(size int) []*queuedMsg {
+	batch := make([]*queuedMsg, 0, size)
+	for idx := 0; idx < size; idx++ {
+		batch = append(batch, &queuedMsg{future: newMsgResultFuture()})
+	}
+
+	return batch
+}
+
+func TestResolveBatch(t *testing.T) {
+	batch := testBatch(3)
+	// a message queued without result
+	batch[1].future = nil
+
+	if batch[0].future.Result() != nil {
+		t.Fatal("expected no result before the batch is resolved")
+	}
+
+	resolveBatch(batch, &sdk.TxResponse{
+		TxHash: "AB",
+		Height: 10,
+		Logs: sdk.ABCIMessageLogs{{
+			MsgIndex: 2,
+			Events:   sdk.StringEvents{{Type: "message", Attributes: []sdk.Attribute{{Key: "action", Value: "second"}}}},
+		}, {
+			MsgIndex: 0,
+			Events:   sdk.StringEvents{{Type: "message", Attributes: []sdk.Attribute{{Key: "action", Value: "first"}}}},
+		}},
+	}, nil)
+
+	for _, idx := range []int{0, 2} {
+		result, err := batch[idx].future.Wait(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		} else if result.MsgIndex != idx || result.TxHash != "AB" || result.Height != 10 {
+			t.Fatalf("unexpected result of message %d: %+v", idx, result)
+		}
+	}
+
+	if events := batch[0].future.Result().Events; len(events) != 1 || events[0].Attributes[0].Value != "first" {
+		t.Fatalf("expected events of the first message, got %v", events)
+	} else if events := batch[2].future.Result().Events; len(events) != 1 || events[0].Attributes[0].Value != "second" {
+		t.Fatalf("expected events of the third message, got %v", events)
+	}
+}
+
+func TestResolveBatchFailure(t *testing.T) {
+	batch := testBatch(2)
+	resolveBatch(batch, &sdk.TxResponse{
+		TxHash:    "AB",
+		Codespace: sdkerrors.ErrInsufficientFunds.Codespace(),
+		Code:      sdkerrors.ErrInsufficientFunds.ABCICode(),
+		RawLog:    "insufficient funds",
+	}, nil)
+
+	for idx, qm := range batch {
+		result, err := qm.future.Wait(context.Background())
+		if !errors.Is(err, ErrInsufficientFunds) {
+			t.Fatalf("expected insufficient funds for message %d, got %v", idx, err)
+		} else if result.Code != sdkerrors.ErrInsufficientFunds.ABCICode() || result.TxHash != "AB" {
+			t.Fatalf("unexpected result of message %d: %+v", idx, result)
+		}
+	}
+
+	// the Tx has not been broadcasted at all
+	batch = testBatch(1)
+	resolveBatch(batch, nil, ErrReadOnly)
+
+	if result := batch[0].future.Result(); result == nil || result.Err != ErrReadOnly || len(result.TxHash) > 0 {
+		t.Fatalf("expected the broadcast error without Tx, got %+v", result)
+	}
+}
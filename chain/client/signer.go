@@ -0,0 +1,332 @@
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	"github.com/pkg/errors"
+
+	"github.com/InjectiveLabs/sdk-go/chain/crypto/ethsecp256k1"
+)
+
+// TxSigner signs Txns on behalf of a single account. It allows keeping keys outside
+// of the keyring, e.g. in a dedicated signing service.
+type TxSigner interface {
+	// Address returns the account address of the signer.
+	Address() sdk.AccAddress
+	// PubKey returns the public key of the signer, it's required to build the sign bytes.
+	PubKey() cryptotypes.PubKey
+	// Sign signs the provided sign bytes, returning the signature and the pubkey to verify it.
+	Sign(signBytes []byte) ([]byte, cryptotypes.PubKey, error)
+}
+
+// NewKeyringSigner creates a TxSigner that uses a named key from the keyring.
+func NewKeyringSigner(kb keyring.Keyring, name string) (TxSigner, error) {
+	keyInfo, err := kb.Key(name)
+	if err != nil {
+		err = errors.Wrapf(err, "no key in keyring for name: %s", name)
+		return nil, err
+	}
+
+	return &keyringSigner{
+		kb:      kb,
+		keyInfo: keyInfo,
+	}, nil
+}
+
+type keyringSigner struct {
+	kb      keyring.Keyring
+	keyInfo keyring.Info
+}
+
+func (s *keyringSigner) Address() sdk.AccAddress {
+	return s.keyInfo.GetAddress()
+}
+
+func (s *keyringSigner) PubKey() cryptotypes.PubKey {
+	return s.keyInfo.GetPubKey()
+}
+
+func (s *keyringSigner) Sign(signBytes []byte) ([]byte, cryptotypes.PubKey, error) {
+	return s.kb.Sign(s.keyInfo.GetName(), signBytes)
+}
+
+// NewPrivKeySigner creates a TxSigner that signs using the raw private key.
+func NewPrivKeySigner(privKey *ethsecp256k1.PrivKey) TxSigner {
+	return &privKeySigner{
+		privKey: privKey,
+	}
+}
+
+type privKeySigner struct {
+	privKey *ethsecp256k1.PrivKey
+}
+
+func (s *privKeySigner) Address() sdk.AccAddress {
+	return sdk.AccAddress(s.privKey.PubKey().Address())
+}
+
+func (s *privKeySigner) PubKey() cryptotypes.PubKey {
+	return s.privKey.PubKey()
+}
+
+func (s *privKeySigner) Sign(signBytes []byte) ([]byte, cryptotypes.PubKey, error) {
+	sig, err := s.privKey.Sign(signBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return sig, s.privKey.PubKey(), nil
+}
+
+// RemoteSignRequest is sent by the remote signer as JSON in a POST request body.
+type RemoteSignRequest struct {
+	// Address is bech32-address of the account expected to sign.
+	Address string `json:"address"`
+	// SignBytes are base64-encoded bytes to sign.
+	SignBytes string `json:"sign_bytes"`
+}
+
+// RemoteSignResponse is expected by the remote signer as JSON in the response body.
+type RemoteSignResponse struct {
+	// Signature is base64-encoded signature of the sign bytes.
+	Signature string `json:"signature"`
+	// PubKey is base64-encoded compressed public key of the signing key.
+	PubKey string `json:"pub_key"`
+}
+
+const defaultRemoteSignerTimeout = 10 * time.Second
+
+// NewRemoteSigner creates a TxSigner that delegates signing to a remote HTTP service
+// at signURL, using RemoteSignRequest and RemoteSignResponse JSON messages.
+// The pubKey of the remote key must be known in advance. Every signature returned
+// is verified against it. If httpClient is nil, a client with 10s timeout is used,
+// a custom client can be provided to set TLS and auth headers.
+func NewRemoteSigner(signURL string, pubKey cryptotypes.PubKey, httpClient *http.Client) TxSigner {
+	if httpClient == nil {
+		httpClient = &http.Client{
+			Timeout: defaultRemoteSignerTimeout,
+		}
+	}
+
+	return &remoteSigner{
+		signURL:    signURL,
+		pubKey:     pubKey,
+		httpClient: httpClient,
+	}
+}
+
+type remoteSigner struct {
+	signURL    string
+	pubKey     cryptotypes.PubKey
+	httpClient *http.Client
+}
+
+func (s *remoteSigner) Address() sdk.AccAddress {
+	return sdk.AccAddress(s.pubKey.Address())
+}
+
+func (s *remoteSigner) PubKey() cryptotypes.PubKey {
+	return s.pubKey
+}
+
+func (s *remoteSigner) Sign(signBytes []byte) ([]byte, cryptotypes.PubKey, error) {
+	reqBody, err := json.Marshal(&RemoteSignRequest{
+		Address:   s.Address().String(),
+		SignBytes: base64.StdEncoding.EncodeToString(signBytes),
+	})
+	if err != nil {
+		err = errors.Wrap(err, "failed to marshal sign request")
+		return nil, nil, err
+	}
+
+	resp, err := s.httpClient.Post(s.signURL, "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		err = errors.Wrap(err, "failed to send sign request")
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err = errors.Errorf("remote signer responded with status %s", resp.Status)
+		return nil, nil, err
+	}
+
+	var signResp RemoteSignResponse
+	if err := json.NewDecoder(resp.Body).Decode(&signResp); err != nil {
+		err = errors.Wrap(err, "failed to decode sign response")
+		return nil, nil, err
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signResp.Signature)
+	if err != nil {
+		err = errors.Wrap(err, "failed to decode signature")
+		return nil, nil, err
+	}
+
+	pubKeyBytes, err := base64.StdEncoding.DecodeString(signResp.PubKey)
+	if err != nil {
+		err = errors.Wrap(err, "failed to decode pubkey")
+		return nil, nil, err
+	} else if !bytes.Equal(pubKeyBytes, s.pubKey.Bytes()) {
+		err = errors.New("remote signer used an unexpected key")
+		return nil, nil, err
+	}
+
+	if !s.pubKey.VerifySignature(signBytes, sig) {
+		err = errors.New("remote signer returned an invalid signature")
+		return nil, nil, err
+	}
+
+	return sig, s.pubKey, nil
+}
+
+// signTx signs the Tx using the signer, the same way tx.Sign does for the keyring keys.
+// The signature is added to the Tx, overwriting the previous ones if overwrite is set.
+func signTx(
+	txConfig client.TxConfig,
+	txf tx.Factory,
+	signer TxSigner,
+	txBuilder client.TxBuilder,
+	overwrite bool,
+) error {
+	signMode := txf.SignMode()
+	if signMode == signing.SignMode_SIGN_MODE_UNSPECIFIED {
+		signMode = txConfig.SignModeHandler().DefaultMode()
+	}
+
+	pubKey := signer.PubKey()
+	signerData := authsigning.SignerData{
+		ChainID:       txf.ChainID(),
+		AccountNumber: txf.AccountNumber(),
+		Sequence:      txf.Sequence(),
+	}
+
+	var prevSignatures []signing.SignatureV2
+	if !overwrite {
+		var err error
+		prevSignatures, err = txBuilder.GetTx().GetSignaturesV2()
+		if err != nil {
+			return err
+		}
+	}
+
+	// signer infos are part of the sign bytes in direct mode,
+	// so an empty signature must be set before signing
+	sig := signing.SignatureV2{
+		PubKey: pubKey,
+		Data: &signing.SingleSignatureData{
+			SignMode: signMode,
+		},
+		Sequence: txf.Sequence(),
+	}
+	if err := txBuilder.SetSignatures(sig); err != nil {
+		return err
+	}
+
+	bytesToSign, err := txConfig.SignModeHandler().GetSignBytes(signMode, signerData, txBuilder.GetTx())
+	if err != nil {
+		return err
+	}
+
+	sigBytes, sigPubKey, err := signer.Sign(bytesToSign)
+	if err != nil {
+		return err
+	} else if !sigPubKey.Equals(pubKey) {
+		err = errors.New("signer used an unexpected key")
+		return err
+	}
+
+	sig = signing.SignatureV2{
+		PubKey: pubKey,
+		Data: &signing.SingleSignatureData{
+			SignMode:  signMode,
+			Signature: sigBytes,
+		},
+		Sequence: txf.Sequence(),
+	}
+
+	if overwrite {
+		return txBuilder.SetSignatures(sig)
+	}
+
+	prevSignatures = append(prevSignatures, sig)
+	return txBuilder.SetSignatures(prevSignatures...)
+}
+
+// signTxWithFeePayer signs the Tx by the signer and the fee payer. In direct mode the sign bytes
+// cover signer infos of all signers, so both are set before any of them signs.
+func signTxWithFeePayer(
+	txConfig client.TxConfig,
+	txf tx.Factory,
+	signer TxSigner,
+	payer *feePayerAccount,
+	txBuilder client.TxBuilder,
+) error {
+	if err := checkFeePayer(signer.Address(), payer); err != nil {
+		return err
+	}
+
+	signMode := txf.SignMode()
+	if signMode == signing.SignMode_SIGN_MODE_UNSPECIFIED {
+		signMode = txConfig.SignModeHandler().DefaultMode()
+	}
+
+	signers := []TxSigner{signer, payer.signer}
+	signerData := []authsigning.SignerData{{
+		ChainID:       txf.ChainID(),
+		AccountNumber: txf.AccountNumber(),
+		Sequence:      txf.Sequence(),
+	}, {
+		ChainID:       txf.ChainID(),
+		AccountNumber: payer.accNum,
+		Sequence:      payer.accSeq,
+	}}
+
+	sigs := make([]signing.SignatureV2, len(signers))
+	for idx, s := range signers {
+		sigs[idx] = signing.SignatureV2{
+			PubKey: s.PubKey(),
+			Data: &signing.SingleSignatureData{
+				SignMode: signMode,
+			},
+			Sequence: signerData[idx].Sequence,
+		}
+	}
+
+	if err := txBuilder.SetSignatures(sigs...); err != nil {
+		return err
+	}
+
+	for idx, s := range signers {
+		bytesToSign, err := txConfig.SignModeHandler().GetSignBytes(signMode, signerData[idx], txBuilder.GetTx())
+		if err != nil {
+			return err
+		}
+
+		sigBytes, sigPubKey, err := s.Sign(bytesToSign)
+		if err != nil {
+			return err
+		} else if !sigPubKey.Equals(s.PubKey()) {
+			err = errors.New("signer used an unexpected key")
+			return err
+		}
+
+		sigs[idx].Data = &signing.SingleSignatureData{
+			SignMode:  signMode,
+			Signature: sigBytes,
+		}
+	}
+
+	return txBuilder.SetSignatures(sigs...)
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	"github.com/InjectiveLabs/sdk-go/chain/crypto/ethsecp256k1"
+)
+
+func TestRemoteSigner(t *testing.T) {
+	privKey, err := ethsecp256k1.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var signedFor string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req RemoteSignRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		signBytes, err := base64.StdEncoding.DecodeString(req.SignBytes)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		sig, err := privKey.Sign(signBytes)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		signedFor = req.Address
+		_ = json.NewEncoder(w).Encode(&RemoteSignResponse{
+			Signature: base64.StdEncoding.EncodeToString(sig),
+			PubKey:    base64.StdEncoding.EncodeToString(privKey.PubKey().Bytes()),
+		})
+	}))
+	defer srv.Close()
+
+	signer := NewRemoteSigner(srv.URL, privKey.PubKey(), nil)
+
+	clientCtx, err := NewClientContext("injective-888", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from := signer.Address()
+	msg := banktypes.NewMsgSend(from, from, sdk.NewCoins(sdk.NewInt64Coin("inj", 1)))
+	params := OfflineTxParams{
+		AccountNumber: 1,
+		Sequence:      2,
+		GasLimit:      200000,
+	}
+
+	txBuilder, err := BuildOfflineTx(clientCtx, params, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SignOfflineTxWithSigner(clientCtx, params, txBuilder, signer, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if signedFor != from.String() {
+		t.Fatalf("expected sign request for %s, got %s", from.String(), signedFor)
+	}
+
+	sigs, err := txBuilder.GetTx().GetSignaturesV2()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(sigs) != 1 || !sigs[0].PubKey.Equals(privKey.PubKey()) {
+		t.Fatal("expected one signature by the remote key")
+	}
+
+	wrongSigner := NewRemoteSigner(srv.URL, NewPrivKeySigner(mustGenerateKey(t)).PubKey(), nil)
+	if err := SignOfflineTxWithSigner(clientCtx, params, txBuilder, wrongSigner, true); err == nil {
+		t.Fatal("expected signature by an unexpected key to be rejected")
+	}
+}
+
+func mustGenerateKey(t *testing.T) *ethsecp256k1.PrivKey {
+	privKey, err := ethsecp256k1.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return privKey
+}
@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+	abci "github.com/tendermint/tendermint/abci/types"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+)
+
+const simulatePath = "/cosmos.tx.v1beta1.Service/Simulate"
+
+// SimulationResult is the outcome of a successful Tx simulation.
+type SimulationResult struct {
+	// GasUsed is the gas consumed by the simulated Tx.
+	GasUsed uint64
+	// GasAdjusted is GasUsed with gas adjustment applied, that is what a broadcast would ask for.
+	GasAdjusted uint64
+	// Events are all events emitted by the Tx.
+	Events []abci.Event
+	// TypedEvents are events decoded into their proto types, e.g. exchange module's
+	// EventBatchSpotExecution. Events that are not typed are skipped.
+	TypedEvents []proto.Message
+	// Log is the raw log of the Tx execution.
+	Log string
+}
+
+// SimulateMsg executes msgs against the current chain state without broadcasting,
+// using the account sequence of the key msgs would be signed by. The sequence is left untouched.
+// If the Tx is rejected, the error is a *ChainError. The node reports all simulation failures as an invalid request
+// (codespace sdk, code 18), the codespace and code of errors registered by the SDK and the chain modules
+// are recovered from the log. Other errors keep code 18, only their Log is reliable.
+func (c *cosmosClient) SimulateMsg(msgs ...sdk.Msg) (*SimulationResult, error) {
+	return c.SimulateMsgWithContext(context.Background(), msgs...)
+}
+
+// SimulateMsgWithContext is SimulateMsg with a context, which limits the simulation query.
+// Tx options carried by ctx, see ContextWithTxOptions, are applied to the simulated Tx.
+func (c *cosmosClient) SimulateMsgWithContext(ctx context.Context, msgs ...sdk.Msg) (*SimulationResult, error) {
+	if !c.canSign {
+		return nil, ErrReadOnly
+	}
+
+	txOpts, err := c.txOptionsOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key := c.pickKey(msgs...)
+
+	key.syncMux.Lock()
+	txf := key.txFactory.WithSequence(key.accSeq).WithAccountNumber(key.accNum)
+	key.syncMux.Unlock()
+
+	clientCtx := c.nodeCtx(key.ctx)
+	txf, err = c.prepareFactory(clientCtx, txf)
+	if err != nil {
+		err = errors.Wrap(err, "failed to prepareFactory")
+		return nil, err
+	}
+
+	txf, err = applyTxOptions(ctx, clientCtx, txf, txOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	var payer *feePayerAccount
+	if txOpts.FeePayer != nil && c.opts.EIP712 == nil {
+		if payer, err = c.feePayers.peek(clientCtx, txf, txOpts.FeePayer); err != nil {
+			return nil, err
+		}
+	}
+
+	txBytes, err := buildSimTx(clientCtx, txf, txOpts, payer, msgs...)
+	if err != nil {
+		err = errors.Wrap(err, "failed to BuildSimTx")
+		return nil, err
+	}
+
+	simRes, err := c.simulateTx(ctx, clientCtx, txBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	c.gasEstimator.Observe(msgs, simRes.GasInfo.GasUsed)
+
+	result := &SimulationResult{
+		GasUsed:     simRes.GasInfo.GasUsed,
+		GasAdjusted: uint64(txf.GasAdjustment() * float64(simRes.GasInfo.GasUsed)),
+	}
+
+	if simRes.Result != nil {
+		result.Events = simRes.Result.Events
+		result.TypedEvents = parseTypedEvents(simRes.Result.Events)
+		result.Log = simRes.Result.Log
+	}
+
+	return result, nil
+}
+
+// simulateTx runs the simulation of Tx bytes on the node. If the Tx is rejected, the error is a *ChainError
+// with the codespace and code recovered from the query log, see newQueryChainError.
+func (c *cosmosClient) simulateTx(ctx context.Context, clientCtx client.Context, txBytes []byte) (*txtypes.SimulateResponse, error) {
+	reqBytes, err := (&txtypes.SimulateRequest{TxBytes: txBytes}).Marshal()
+	if err != nil {
+		err = errors.Wrap(err, "failed to marshal SimulateRequest")
+		return nil, err
+	}
+
+	node, err := clientCtx.GetNode()
+	if err != nil {
+		return nil, err
+	}
+
+	// querying directly, since client context would turn the error into plain text
+	res, err := node.ABCIQueryWithOptions(ctx, simulatePath, reqBytes, rpcclient.ABCIQueryOptions{})
+	if err != nil {
+		err = errors.Wrap(err, "failed to query simulation")
+		return nil, err
+	} else if !res.Response.IsOK() {
+		return nil, newQueryChainError(res.Response.Codespace, res.Response.Code, res.Response.Log)
+	}
+
+	var simRes txtypes.SimulateResponse
+	if err := simRes.Unmarshal(res.Response.Value); err != nil {
+		err = errors.Wrap(err, "failed to unmarshal SimulateResponse")
+		return nil, err
+	}
+
+	return &simRes, nil
+}
+
+// parseTypedEvents decodes events emitted with EmitTypedEvent, skipping all others.
+func parseTypedEvents(events []abci.Event) []proto.Message {
+	typedEvents := make([]proto.Message, 0, len(events))
+	for _, ev := range events {
+		if proto.MessageType(ev.Type) == nil {
+			continue
+		}
+
+		typedEvent, err := sdk.ParseTypedEvent(ev)
+		if err != nil {
+			continue
+		}
+
+		typedEvents = append(typedEvents, typedEvent)
+	}
+
+	return typedEvents
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/pkg/errors"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/bytes"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+
+	exchange "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+)
+
+func TestParseTypedEvents(t *testing.T) {
+	executionEvent := &exchange.EventBatchSpotExecution{
+		MarketId:      "0x01",
+		IsBuy:         true,
+		ExecutionType: exchange.ExecutionType_LimitMatchNewOrder,
+	}
+
+	typedEvent, err := sdk.TypedEventToEvent(executionEvent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := []abci.Event{
+		abci.Event(sdk.NewEvent(sdk.EventTypeMessage, sdk.NewAttribute(sdk.AttributeKeyModule, exchange.ModuleName))),
+		abci.Event(typedEvent),
+	}
+
+	typedEvents := parseTypedEvents(events)
+	if len(typedEvents) != 1 {
+		t.Fatalf("expected 1 typed event, got %d", len(typedEvents))
+	}
+
+	parsed, ok := typedEvents[0].(*exchange.EventBatchSpotExecution)
+	if !ok {
+		t.Fatalf("unexpected event type %T", typedEvents[0])
+	} else if parsed.MarketId != executionEvent.MarketId || !parsed.IsBuy || parsed.ExecutionType != executionEvent.ExecutionType {
+		t.Fatalf("event doesn't match: %v", parsed)
+	}
+}
+
+type simulationClient struct {
+	rpcclient.Client
+
+	res abci.ResponseQuery
+}
+
+func (c *simulationClient) ABCIQueryWithOptions(context.Context, string, bytes.HexBytes, rpcclient.ABCIQueryOptions) (*ctypes.ResultABCIQuery, error) {
+	return &ctypes.ResultABCIQuery{Response: c.res}, nil
+}
+
+func TestSimulateTxRejected(t *testing.T) {
+	orderErr := sdkerrors.Wrap(exchange.ErrInsufficientDeposit, "failed to execute message; message index: 1")
+	node := &simulationClient{
+		res: queryError(orderErr),
+	}
+
+	c := &cosmosClient{}
+	_, err := c.simulateTx(context.Background(), client.Context{}.WithClient(node), []byte("tx"))
+
+	var chainErr *ChainError
+	if !errors.As(err, &chainErr) {
+		t.Fatalf("expected chain error, got %v", err)
+	} else if chainErr.Codespace != exchange.ModuleName || chainErr.Code != exchange.ErrInsufficientDeposit.ABCICode() {
+		t.Fatalf("expected codespace and code of the exchange error, got %s/%d", chainErr.Codespace, chainErr.Code)
+	} else if chainErr.Log != orderErr.Error() {
+		t.Fatalf("unexpected log %q", chainErr.Log)
+	} else if !errors.Is(err, ErrInsufficientFunds) || !errors.Is(err, exchange.ErrInsufficientDeposit) {
+		t.Fatal("expected insufficient deposit")
+	}
+}
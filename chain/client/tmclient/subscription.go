@@ -0,0 +1,454 @@
+package tmclient
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/xlab/suplog"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	tmquery "github.com/tendermint/tendermint/libs/pubsub/query"
+	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+const (
+	subscriber             = "tmclient"
+	subscriptionBufferSize = 1024
+
+	// staleTimeout is the time without new blocks after which the connection is considered dead
+	staleTimeout      = 30 * time.Second
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+var newBlockQuery = tmtypes.EventQueryNewBlock.String()
+
+// SubscribeNewBlocks delivers new blocks, along with begin and end block results.
+// The channel is closed when ctx is done.
+func (c *tmClient) SubscribeNewBlocks(ctx context.Context) (<-chan *tmtypes.EventDataNewBlock, error) {
+	eventsC, err := c.SubscribeEvents(ctx, newBlockQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	blocksC := make(chan *tmtypes.EventDataNewBlock, subscriptionBufferSize)
+	go func() {
+		defer close(blocksC)
+
+		for ev := range eventsC {
+			data, ok := ev.Data.(tmtypes.EventDataNewBlock)
+			if !ok {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case blocksC <- &data:
+			}
+		}
+	}()
+
+	return blocksC, nil
+}
+
+// SubscribeTxs delivers results of Txns matching query, e.g. "message.sender='inj1...'".
+// Empty query matches all Txns. The channel is closed when ctx is done.
+func (c *tmClient) SubscribeTxs(ctx context.Context, query string) (<-chan *tmtypes.EventDataTx, error) {
+	txQuery := tmtypes.EventQueryTx.String()
+	if len(query) > 0 {
+		txQuery = fmt.Sprintf("%s AND %s", txQuery, query)
+	}
+
+	eventsC, err := c.SubscribeEvents(ctx, txQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	txsC := make(chan *tmtypes.EventDataTx, subscriptionBufferSize)
+	go func() {
+		defer close(txsC)
+
+		for ev := range eventsC {
+			data, ok := ev.Data.(tmtypes.EventDataTx)
+			if !ok {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case txsC <- &data:
+			}
+		}
+	}()
+
+	return txsC, nil
+}
+
+// SubscribeEvents delivers events matching the Tendermint query, e.g. "tm.event='Tx' AND transfer.recipient='inj1...'".
+// The subscription reconnects automatically. NewBlock and Tx events missed while disconnected are backfilled
+// from block results, other event types are not. The channel is closed when ctx is done.
+func (c *tmClient) SubscribeEvents(ctx context.Context, query string) (<-chan ctypes.ResultEvent, error) {
+	filter, err := tmquery.New(query)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to parse query: %s", query)
+		return nil, err
+	}
+
+	height, err := c.GetLatestBlockHeight(ctx)
+	if err != nil {
+		err = errors.Wrap(err, "failed to get latest block height")
+		return nil, err
+	}
+
+	sub := &subscription{
+		client:         c,
+		query:          query,
+		filter:         filter,
+		outC:           make(chan ctypes.ResultEvent, subscriptionBufferSize),
+		completeHeight: height,
+		lastBlock:      height,
+		delivered:      make(map[eventKey]struct{}),
+		logger: log.WithFields(log.Fields{
+			"module": "tmclient",
+			"query":  query,
+		}),
+	}
+
+	go sub.run(ctx)
+
+	return sub.outC, nil
+}
+
+type eventKey struct {
+	height int64
+	// txIndex is -1 for block events
+	txIndex int64
+}
+
+type subscription struct {
+	client *tmClient
+	query  string
+	filter *tmquery.Query
+	outC   chan ctypes.ResultEvent
+
+	// completeHeight is the height up to which all events have been delivered
+	completeHeight int64
+	lastBlock      int64
+	// delivered tracks events above completeHeight, so none is delivered twice
+	delivered map[eventKey]struct{}
+
+	logger log.Logger
+}
+
+func (s *subscription) run(ctx context.Context) {
+	defer close(s.outC)
+
+	delay := minReconnectDelay
+	for {
+		connected, err := s.runConn(ctx)
+		if ctx.Err() != nil {
+			return
+		} else if connected {
+			delay = minReconnectDelay
+		}
+
+		s.logger.WithError(err).Warningf("subscription interrupted, reconnecting in %s", delay)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		if delay *= 2; delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+
+		s.client.onReconnect(s.query)
+	}
+}
+
+// runConn subscribes over a new websocket connection, backfills events missed since the last one,
+// and delivers events until the connection goes stale.
+func (s *subscription) runConn(ctx context.Context) (connected bool, err error) {
+	wsClient, err := rpchttp.New(s.client.rpcNodeAddr, "/websocket")
+	if err != nil {
+		err = errors.Wrap(err, "failed to init websocket client")
+		return false, err
+	}
+
+	if err := wsClient.Start(); err != nil {
+		err = errors.Wrap(err, "failed to start websocket client")
+		return false, err
+	}
+	defer func() {
+		_ = wsClient.Stop()
+	}()
+
+	eventsC, err := wsClient.Subscribe(ctx, subscriber, s.query, subscriptionBufferSize)
+	if err != nil {
+		err = errors.Wrap(err, "failed to subscribe")
+		return false, err
+	}
+
+	// new blocks are tracked to detect gaps and stale connections
+	var blocksC <-chan ctypes.ResultEvent
+	if s.query != newBlockQuery {
+		if blocksC, err = wsClient.Subscribe(ctx, subscriber, newBlockQuery, subscriptionBufferSize); err != nil {
+			err = errors.Wrap(err, "failed to subscribe to new blocks")
+			return false, err
+		}
+	}
+
+	latestHeight, err := s.client.GetLatestBlockHeight(ctx)
+	if err != nil {
+		err = errors.Wrap(err, "failed to get latest block height")
+		return true, err
+	}
+
+	if err := s.backfill(ctx, latestHeight); err != nil {
+		return true, err
+	}
+
+	staleTimer := time.NewTimer(staleTimeout)
+	defer staleTimer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case <-staleTimer.C:
+			err = errors.Errorf("no new blocks in %s", staleTimeout)
+			return true, err
+		case ev := <-eventsC:
+			if s.query == newBlockQuery {
+				// skipped blocks go first
+				if err := s.onNewBlock(ctx, ev); err != nil {
+					return true, err
+				}
+
+				staleTimer.Reset(staleTimeout)
+			}
+
+			if !s.deliver(ctx, ev) {
+				return true, ctx.Err()
+			}
+		case ev := <-blocksC:
+			// events of the previous blocks have been dispatched before this one
+			for drained := false; !drained; {
+				select {
+				case ev := <-eventsC:
+					if !s.deliver(ctx, ev) {
+						return true, ctx.Err()
+					}
+				default:
+					drained = true
+				}
+			}
+
+			if err := s.onNewBlock(ctx, ev); err != nil {
+				return true, err
+			}
+
+			staleTimer.Reset(staleTimeout)
+		}
+	}
+}
+
+// onNewBlock advances the complete height, backfilling blocks that have been skipped.
+func (s *subscription) onNewBlock(ctx context.Context, ev ctypes.ResultEvent) error {
+	data, ok := ev.Data.(tmtypes.EventDataNewBlock)
+	if !ok || data.Block == nil {
+		return nil
+	}
+
+	height := data.Block.Height
+	if height <= s.lastBlock {
+		return nil
+	} else if height > s.lastBlock+1 {
+		s.logger.WithFields(log.Fields{
+			"from": s.lastBlock + 1,
+			"to":   height - 1,
+		}).Warningln("missed blocks, backfilling")
+
+		if err := s.backfill(ctx, height-1); err != nil {
+			return err
+		}
+	}
+
+	s.lastBlock = height
+	s.setComplete(height - 1)
+
+	return nil
+}
+
+// backfill delivers matching NewBlock and Tx events of blocks up to toHeight.
+func (s *subscription) backfill(ctx context.Context, toHeight int64) error {
+	for height := s.completeHeight + 1; height <= toHeight; height++ {
+		block, err := s.client.GetBlock(ctx, height)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to get block %d", height)
+			return err
+		}
+
+		results, err := s.client.GetBlockResults(ctx, height)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to get block results %d", height)
+			return err
+		}
+
+		for _, ev := range blockResultEvents(s.query, block, results) {
+			matches, err := s.filter.Matches(ev.Events)
+			if err != nil {
+				err = errors.Wrap(err, "failed to match event")
+				return err
+			} else if !matches {
+				continue
+			}
+
+			if !s.deliver(ctx, ev) {
+				return ctx.Err()
+			}
+		}
+
+		if height > s.lastBlock {
+			s.lastBlock = height
+		}
+
+		s.setComplete(height)
+	}
+
+	return nil
+}
+
+func (s *subscription) setComplete(height int64) {
+	if height <= s.completeHeight {
+		return
+	}
+
+	s.completeHeight = height
+	for key := range s.delivered {
+		if key.height <= height {
+			delete(s.delivered, key)
+		}
+	}
+}
+
+// deliver sends the event, unless it has been delivered already. Returns false if ctx is done.
+func (s *subscription) deliver(ctx context.Context, ev ctypes.ResultEvent) bool {
+	if key, ok := keyOfEvent(ev); ok {
+		if key.height <= s.completeHeight {
+			return true
+		} else if _, ok := s.delivered[key]; ok {
+			return true
+		}
+
+		s.delivered[key] = struct{}{}
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case s.outC <- ev:
+		return true
+	}
+}
+
+func keyOfEvent(ev ctypes.ResultEvent) (eventKey, bool) {
+	switch data := ev.Data.(type) {
+	case tmtypes.EventDataNewBlock:
+		if data.Block == nil {
+			return eventKey{}, false
+		}
+
+		return eventKey{height: data.Block.Height, txIndex: -1}, true
+	case tmtypes.EventDataTx:
+		return eventKey{height: data.Height, txIndex: int64(data.Index)}, true
+	default:
+		return eventKey{}, false
+	}
+}
+
+// blockResultEvents rebuilds NewBlock and Tx events of a block the way Tendermint publishes them.
+func blockResultEvents(query string, block *ctypes.ResultBlock, results *ctypes.ResultBlockResults) []ctypes.ResultEvent {
+	blockData := tmtypes.EventDataNewBlock{
+		Block: block.Block,
+		ResultBeginBlock: abci.ResponseBeginBlock{
+			Events: results.BeginBlockEvents,
+		},
+		ResultEndBlock: abci.ResponseEndBlock{
+			ValidatorUpdates:      results.ValidatorUpdates,
+			ConsensusParamUpdates: results.ConsensusParamUpdates,
+			Events:                results.EndBlockEvents,
+		},
+	}
+
+	blockEvents := stringifyEvents(results.BeginBlockEvents)
+	for key, values := range stringifyEvents(results.EndBlockEvents) {
+		blockEvents[key] = append(blockEvents[key], values...)
+	}
+	blockEvents[tmtypes.EventTypeKey] = append(blockEvents[tmtypes.EventTypeKey], tmtypes.EventNewBlock)
+
+	events := make([]ctypes.ResultEvent, 0, len(results.TxsResults)+1)
+	events = append(events, ctypes.ResultEvent{
+		Query:  query,
+		Data:   blockData,
+		Events: blockEvents,
+	})
+
+	for idx, txResult := range results.TxsResults {
+		if idx >= len(block.Block.Txs) || txResult == nil {
+			break
+		}
+
+		txBytes := block.Block.Txs[idx]
+		txData := tmtypes.EventDataTx{
+			TxResult: abci.TxResult{
+				Height: results.Height,
+				Index:  uint32(idx),
+				Tx:     txBytes,
+				Result: *txResult,
+			},
+		}
+
+		txEvents := stringifyEvents(txResult.Events)
+		txEvents[tmtypes.EventTypeKey] = append(txEvents[tmtypes.EventTypeKey], tmtypes.EventTx)
+		txEvents[tmtypes.TxHashKey] = append(txEvents[tmtypes.TxHashKey], fmt.Sprintf("%X", txBytes.Hash()))
+		txEvents[tmtypes.TxHeightKey] = append(txEvents[tmtypes.TxHeightKey], strconv.FormatInt(results.Height, 10))
+
+		events = append(events, ctypes.ResultEvent{
+			Query:  query,
+			Data:   txData,
+			Events: txEvents,
+		})
+	}
+
+	return events
+}
+
+func stringifyEvents(events []abci.Event) map[string][]string {
+	result := make(map[string][]string)
+	for _, ev := range events {
+		if len(ev.Type) == 0 {
+			continue
+		}
+
+		for _, attr := range ev.Attributes {
+			if len(attr.Key) == 0 {
+				continue
+			}
+
+			compositeKey := fmt.Sprintf("%s.%s", ev.Type, attr.Key)
+			result[compositeKey] = append(result[compositeKey], string(attr.Value))
+		}
+	}
+
+	return result
+}
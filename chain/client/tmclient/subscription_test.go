@@ -0,0 +1,78 @@
+package tmclient
+
+import (
+	"context"
+	"testing"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	tmquery "github.com/tendermint/tendermint/libs/pubsub/query"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+func TestBackfillEvents(t *testing.T) {
+	query := "tm.event='Tx' AND transfer.recipient='inj1recipient'"
+	block := &ctypes.ResultBlock{
+		Block: &tmtypes.Block{
+			Header: tmtypes.Header{Height: 10},
+			Data: tmtypes.Data{
+				Txs: tmtypes.Txs{tmtypes.Tx("tx0"), tmtypes.Tx("tx1")},
+			},
+		},
+	}
+
+	results := &ctypes.ResultBlockResults{
+		Height: 10,
+		TxsResults: []*abci.ResponseDeliverTx{
+			{Events: []abci.Event{transferEvent("inj1other")}},
+			{Events: []abci.Event{transferEvent("inj1recipient")}},
+		},
+	}
+
+	sub := &subscription{
+		query:          query,
+		filter:         tmquery.MustParse(query),
+		outC:           make(chan ctypes.ResultEvent, 10),
+		completeHeight: 9,
+		delivered:      make(map[eventKey]struct{}),
+	}
+
+	for _, ev := range blockResultEvents(query, block, results) {
+		matches, err := sub.filter.Matches(ev.Events)
+		if err != nil {
+			t.Fatal(err)
+		} else if matches {
+			sub.deliver(context.Background(), ev)
+			// a live duplicate must be skipped
+			sub.deliver(context.Background(), ev)
+		}
+	}
+
+	if len(sub.outC) != 1 {
+		t.Fatalf("expected 1 matching Tx event, got %d", len(sub.outC))
+	}
+
+	ev := <-sub.outC
+	if data, ok := ev.Data.(tmtypes.EventDataTx); !ok || data.Index != 1 || data.Height != 10 {
+		t.Fatalf("unexpected event data %v", ev.Data)
+	}
+
+	sub.setComplete(10)
+	if len(sub.delivered) != 0 {
+		t.Fatal("expected delivered events of complete blocks to be pruned")
+	}
+
+	sub.deliver(context.Background(), ev)
+	if len(sub.outC) != 0 {
+		t.Fatal("expected events of complete blocks to be skipped")
+	}
+}
+
+func transferEvent(recipient string) abci.Event {
+	return abci.Event{
+		Type: "transfer",
+		Attributes: []abci.EventAttribute{
+			{Key: []byte("recipient"), Value: []byte(recipient)},
+		},
+	}
+}
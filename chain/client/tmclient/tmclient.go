@@ -10,6 +10,7 @@ import (
 	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 	tmctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
 )
 
 type TendermintClient interface {
@@ -18,21 +19,46 @@ type TendermintClient interface {
 	GetTxs(ctx context.Context, block *tmctypes.ResultBlock) ([]*ctypes.ResultTx, error)
 	GetBlockResults(ctx context.Context, height int64) (*ctypes.ResultBlockResults, error)
 	GetValidatorSet(ctx context.Context, height int64) (*tmctypes.ResultValidators, error)
+	SubscribeNewBlocks(ctx context.Context) (<-chan *tmtypes.EventDataNewBlock, error)
+	SubscribeTxs(ctx context.Context, query string) (<-chan *tmtypes.EventDataTx, error)
+	SubscribeEvents(ctx context.Context, query string) (<-chan ctypes.ResultEvent, error)
 }
 
 type tmClient struct {
-	rpcClient rpcclient.Client
+	rpcNodeAddr string
+	rpcClient   rpcclient.Client
+
+	// onReconnect is called with the query of a subscription that is reconnecting
+	onReconnect func(query string)
+}
+
+type tmClientOption func(c *tmClient)
+
+// OptionOnReconnect sets the hook called every time an event subscription reconnects,
+// e.g. to feed client.Observer.ObserveStreamReconnect.
+func OptionOnReconnect(fn func(query string)) tmClientOption {
+	return func(c *tmClient) {
+		c.onReconnect = fn
+	}
 }
 
-func NewRPCClient(rpcNodeAddr string) TendermintClient {
+func NewRPCClient(rpcNodeAddr string, options ...tmClientOption) TendermintClient {
 	rpcClient, err := rpchttp.NewWithTimeout(rpcNodeAddr, "/websocket", 10)
 	if err != nil {
 		log.WithError(err).Fatalln("failed to init rpcClient")
 	}
 
-	return &tmClient{
-		rpcClient: rpcClient,
+	c := &tmClient{
+		rpcNodeAddr: rpcNodeAddr,
+		rpcClient:   rpcClient,
+		onReconnect: func(string) {},
+	}
+
+	for _, opt := range options {
+		opt(c)
 	}
+
+	return c
 }
 
 // GetBlock queries for a block by height. An error is returned if the query fails.
@@ -0,0 +1,278 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
+	jsonrpcclient "github.com/tendermint/tendermint/rpc/jsonrpc/client"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+type dialOptions struct {
+	TLSConfig      *tls.Config
+	Metadata       map[string]string
+	PerRPCCreds    []credentials.PerRPCCredentials
+	GRPCDialOption []grpc.DialOption
+}
+
+// DialOption configures the transport used to connect to chain nodes and exchange API servers.
+type DialOption func(opts *dialOptions) error
+
+// DialOptionTLS enables TLS, verifying the server certificate against CA certs from caFile.
+// If caFile is empty, the system CA pool is used.
+func DialOptionTLS(caFile string) DialOption {
+	return func(opts *dialOptions) error {
+		cfg := tlsConfigOf(opts)
+		if len(caFile) == 0 {
+			return nil
+		}
+
+		caPEM, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			err = errors.Wrap(err, "failed to read CA file")
+			return err
+		}
+
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caPEM) {
+			err := errors.Errorf("no CA certs found in %s", caFile)
+			return err
+		}
+
+		cfg.RootCAs = certPool
+		return nil
+	}
+}
+
+// DialOptionMutualTLS enables TLS with a client certificate, the server certificate
+// is verified against CA certs from caFile, or the system CA pool, if caFile is empty.
+func DialOptionMutualTLS(caFile, certFile, keyFile string) DialOption {
+	return func(opts *dialOptions) error {
+		if err := DialOptionTLS(caFile)(opts); err != nil {
+			return err
+		}
+
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			err = errors.Wrap(err, "failed to load client cert")
+			return err
+		}
+
+		opts.TLSConfig.Certificates = append(opts.TLSConfig.Certificates, cert)
+		return nil
+	}
+}
+
+// DialOptionTLSConfig enables TLS with the given config, for cases not covered by other options.
+func DialOptionTLSConfig(cfg *tls.Config) DialOption {
+	return func(opts *dialOptions) error {
+		if cfg == nil {
+			err := errors.New("TLS config must not be nil")
+			return err
+		}
+
+		opts.TLSConfig = cfg.Clone()
+		return nil
+	}
+}
+
+// DialOptionBearerToken sends the token in the authorization header of every request.
+func DialOptionBearerToken(token string) DialOption {
+	return DialOptionMetadata(map[string]string{
+		"authorization": "Bearer " + token,
+	})
+}
+
+// DialOptionMetadata sends the key-value pairs as metadata (headers) of every request.
+func DialOptionMetadata(md map[string]string) DialOption {
+	return func(opts *dialOptions) error {
+		if opts.Metadata == nil {
+			opts.Metadata = make(map[string]string, len(md))
+		}
+
+		for k, v := range md {
+			opts.Metadata[k] = v
+		}
+
+		return nil
+	}
+}
+
+// DialOptionPerRPCCredentials adds custom per-RPC credentials to gRPC connections,
+// e.g. OAuth tokens. They are not applied to Tendermint RPC.
+func DialOptionPerRPCCredentials(creds credentials.PerRPCCredentials) DialOption {
+	return func(opts *dialOptions) error {
+		opts.PerRPCCreds = append(opts.PerRPCCreds, creds)
+		return nil
+	}
+}
+
+// DialOptionGRPC adds raw gRPC dial options, e.g. interceptors or keepalive params.
+func DialOptionGRPC(grpcOpts ...grpc.DialOption) DialOption {
+	return func(opts *dialOptions) error {
+		opts.GRPCDialOption = append(opts.GRPCDialOption, grpcOpts...)
+		return nil
+	}
+}
+
+func tlsConfigOf(opts *dialOptions) *tls.Config {
+	if opts.TLSConfig == nil {
+		opts.TLSConfig = &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+
+	return opts.TLSConfig
+}
+
+func parseDialOptions(options ...DialOption) (*dialOptions, error) {
+	opts := &dialOptions{}
+	for _, opt := range options {
+		if err := opt(opts); err != nil {
+			err = errors.Wrap(err, "error in a dial option")
+			return nil, err
+		}
+	}
+
+	return opts, nil
+}
+
+// DialGRPC connects to the gRPC server at protoAddr, which must be in form
+// "tcp://127.0.0.1:9900" or "unix:///tmp/test.sock", protocol is required.
+// Without TLS options the connection is insecure.
+func DialGRPC(protoAddr string, options ...DialOption) (*grpc.ClientConn, error) {
+	opts, err := parseDialOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return dialGRPC(protoAddr, opts)
+}
+
+func dialGRPC(protoAddr string, opts *dialOptions) (*grpc.ClientConn, error) {
+	grpcOpts := []grpc.DialOption{
+		grpc.WithContextDialer(dialerFunc),
+	}
+
+	if opts.TLSConfig != nil {
+		cfg := opts.TLSConfig.Clone()
+		if len(cfg.ServerName) == 0 {
+			// target has a non-standard scheme, so gRPC can't infer the server name
+			cfg.ServerName = hostOf(protoAddr)
+		}
+
+		grpcOpts = append(grpcOpts, grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+	} else {
+		grpcOpts = append(grpcOpts, grpc.WithInsecure())
+	}
+
+	if len(opts.Metadata) > 0 {
+		grpcOpts = append(grpcOpts, grpc.WithPerRPCCredentials(&metadataCreds{
+			md:         opts.Metadata,
+			requireTLS: opts.TLSConfig != nil,
+		}))
+	}
+
+	for _, creds := range opts.PerRPCCreds {
+		grpcOpts = append(grpcOpts, grpc.WithPerRPCCredentials(creds))
+	}
+
+	grpcOpts = append(grpcOpts, opts.GRPCDialOption...)
+
+	conn, err := grpc.Dial(protoAddr, grpcOpts...)
+	if err != nil {
+		err := errors.Wrapf(err, "failed to connect to the gRPC: %s", protoAddr)
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// NewTendermintRPCClient creates Tendermint RPC client for addr, e.g. "https://127.0.0.1:26657",
+// using TLS and metadata (as HTTP headers) from options.
+func NewTendermintRPCClient(addr string, options ...DialOption) (rpcclient.Client, error) {
+	opts, err := parseDialOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTendermintRPCClient(addr, opts)
+}
+
+func newTendermintRPCClient(addr string, opts *dialOptions) (rpcclient.Client, error) {
+	httpClient, err := jsonrpcclient.DefaultHTTPClient(addr)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to init Tendermint RPC client: %s", addr)
+		return nil, err
+	}
+
+	httpClient.Timeout = tendermintRPCTimeout
+
+	if transport, ok := httpClient.Transport.(*http.Transport); ok && opts.TLSConfig != nil {
+		transport.TLSClientConfig = opts.TLSConfig.Clone()
+	}
+
+	if len(opts.Metadata) > 0 {
+		httpClient.Transport = &headerRoundTripper{
+			headers: opts.Metadata,
+			next:    httpClient.Transport,
+		}
+	}
+
+	tmClient, err := rpchttp.NewWithClient(addr, "/websocket", httpClient)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to init Tendermint RPC client: %s", addr)
+		return nil, err
+	}
+
+	return tmClient, nil
+}
+
+const tendermintRPCTimeout = 10 * time.Second
+
+func hostOf(protoAddr string) string {
+	_, address := ProtocolAndAddress(protoAddr)
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+
+	return host
+}
+
+// metadataCreds attaches static metadata to every gRPC call.
+type metadataCreds struct {
+	md         map[string]string
+	requireTLS bool
+}
+
+func (c *metadataCreds) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return c.md, nil
+}
+
+func (c *metadataCreds) RequireTransportSecurity() bool {
+	return c.requireTLS
+}
+
+type headerRoundTripper struct {
+	headers map[string]string
+	next    http.RoundTripper
+}
+
+func (rt *headerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range rt.headers {
+		req.Header.Set(k, v)
+	}
+
+	return rt.next.RoundTrip(req)
+}
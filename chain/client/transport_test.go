@@ -0,0 +1,265 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// testCerts are PEM files of a CA, a server cert for 127.0.0.1 and a client cert, both issued by the CA.
+type testCerts struct {
+	CAFile         string
+	ServerCertFile string
+	ServerKeyFile  string
+	ClientCertFile string
+	ClientKeyFile  string
+}
+
+func newTestCerts(t *testing.T) *testCerts {
+	dir := t.TempDir()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certs := &testCerts{
+		CAFile:         filepath.Join(dir, "ca.pem"),
+		ServerCertFile: filepath.Join(dir, "server.pem"),
+		ServerKeyFile:  filepath.Join(dir, "server.key"),
+		ClientCertFile: filepath.Join(dir, "client.pem"),
+		ClientKeyFile:  filepath.Join(dir, "client.key"),
+	}
+	writePEM(t, certs.CAFile, "CERTIFICATE", caDER)
+
+	issue := func(serial int64, name string, usage x509.ExtKeyUsage, certFile, keyFile string) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		}
+
+		der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		writePEM(t, certFile, "CERTIFICATE", der)
+		writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	}
+
+	issue(2, "server", x509.ExtKeyUsageServerAuth, certs.ServerCertFile, certs.ServerKeyFile)
+	issue(3, "client", x509.ExtKeyUsageClientAuth, certs.ClientCertFile, certs.ClientKeyFile)
+
+	return certs
+}
+
+func writePEM(t *testing.T, file, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := ioutil.WriteFile(file, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// serverTLSConfig verifies client certs against the test CA, if presented.
+func (c *testCerts) serverTLSConfig(t *testing.T) *tls.Config {
+	cert, err := tls.LoadX509KeyPair(c.ServerCertFile, c.ServerKeyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caPEM, err := ioutil.ReadFile(c.CAFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(caPEM)
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+	}
+}
+
+// seenCall is what the server has seen of the last call.
+type seenCall struct {
+	mux        sync.Mutex
+	md         metadata.MD
+	clientCert *x509.Certificate
+}
+
+func (s *seenCall) intercept(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	s.mux.Lock()
+	s.md, _ = metadata.FromIncomingContext(ctx)
+	s.clientCert = nil
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.PeerCertificates) > 0 {
+			s.clientCert = tlsInfo.State.PeerCertificates[0]
+		}
+	}
+	s.mux.Unlock()
+
+	return handler(ctx, req)
+}
+
+func TestDialGRPCTLS(t *testing.T) {
+	certs := newTestCerts(t)
+	seen := &seenCall{}
+
+	server := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(certs.serverTLSConfig(t))),
+		grpc.UnaryInterceptor(seen.intercept),
+	)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go server.Serve(lis)
+	defer server.Stop()
+
+	protoAddr := "tcp://" + lis.Addr().String()
+	check := func(options ...DialOption) error {
+		conn, err := DialGRPC(protoAddr, options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+
+		ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelFn()
+
+		_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		return err
+	}
+
+	// the server cert is not issued by a system CA
+	if err := check(DialOptionTLS("")); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected the server cert to be rejected, got %v", err)
+	}
+
+	err = check(
+		DialOptionTLS(certs.CAFile),
+		DialOptionBearerToken("secret"),
+		DialOptionMetadata(map[string]string{"x-api-key": "key"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	seen.mux.Lock()
+	if auth := seen.md.Get("authorization"); len(auth) != 1 || auth[0] != "Bearer secret" {
+		t.Fatalf("unexpected authorization header %v", auth)
+	} else if apiKey := seen.md.Get("x-api-key"); len(apiKey) != 1 || apiKey[0] != "key" {
+		t.Fatalf("unexpected x-api-key header %v", apiKey)
+	} else if seen.clientCert != nil {
+		t.Fatal("expected no client cert without mutual TLS")
+	}
+	seen.mux.Unlock()
+
+	if err := check(DialOptionMutualTLS(certs.CAFile, certs.ClientCertFile, certs.ClientKeyFile)); err != nil {
+		t.Fatal(err)
+	}
+
+	seen.mux.Lock()
+	if seen.clientCert == nil || seen.clientCert.Subject.CommonName != "client" {
+		t.Fatalf("expected the client cert to be presented, got %v", seen.clientCert)
+	}
+	seen.mux.Unlock()
+}
+
+func TestTendermintRPCClientTLS(t *testing.T) {
+	certs := newTestCerts(t)
+
+	reqC := make(chan *http.Request, 1)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqC <- r.Clone(context.Background())
+		http.Error(w, "not implemented", http.StatusNotImplemented)
+	}))
+	server.TLS = certs.serverTLSConfig(t)
+	server.StartTLS()
+	defer server.Close()
+
+	tmClient, err := NewTendermintRPCClient(server.URL,
+		DialOptionMutualTLS(certs.CAFile, certs.ClientCertFile, certs.ClientKeyFile),
+		DialOptionBearerToken("secret"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the response is not a valid status, but the request has to make it through TLS
+	_, _ = tmClient.Status(context.Background())
+
+	select {
+	case req := <-reqC:
+		if auth := req.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Fatalf("unexpected authorization header %s", auth)
+		} else if len(req.TLS.PeerCertificates) == 0 || req.TLS.PeerCertificates[0].Subject.CommonName != "client" {
+			t.Fatal("expected the client cert to be presented")
+		}
+	default:
+		t.Fatal("expected the request to reach the server")
+	}
+}
@@ -0,0 +1,365 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/pkg/errors"
+)
+
+// txOptions are Tx fields set on top of what the client's Tx factory sets.
+type txOptions struct {
+	Memo string
+	// TimeoutBlocks is the Tx timeout height relative to the latest block, zero means no timeout.
+	TimeoutBlocks uint64
+	// GasLimit disables simulation, when set.
+	GasLimit uint64
+	// Fees replace fees computed from gas prices, when set.
+	Fees       string
+	FeeGranter sdk.AccAddress
+	FeePayer   TxSigner
+}
+
+type txOption func(opts *txOptions) error
+
+// TxOptionMemo sets the Tx memo.
+func TxOptionMemo(memo string) txOption {
+	return func(opts *txOptions) error {
+		opts.Memo = memo
+		return nil
+	}
+}
+
+// TxOptionTimeoutHeight makes the Tx expire if it is not included within the given amount
+// of blocks after the latest one. An expired Tx is evicted from the mempool instead of landing late.
+func TxOptionTimeoutHeight(blocks uint64) txOption {
+	return func(opts *txOptions) error {
+		opts.TimeoutBlocks = blocks
+		return nil
+	}
+}
+
+// TxOptionGasLimit sets the gas limit of the Tx, so it is broadcasted without simulation.
+func TxOptionGasLimit(gas uint64) txOption {
+	return func(opts *txOptions) error {
+		if gas == 0 {
+			err := errors.New("gas limit must be positive")
+			return err
+		}
+
+		opts.GasLimit = gas
+		return nil
+	}
+}
+
+// TxOptionFees sets fixed Tx fees, e.g. "100000000000000inj", instead of fees computed from gas prices.
+func TxOptionFees(fees string) txOption {
+	return func(opts *txOptions) error {
+		if _, err := sdk.ParseCoinsNormalized(fees); err != nil {
+			err = errors.Wrapf(err, "failed to ParseCoinsNormalized %s", fees)
+			return err
+		}
+
+		opts.Fees = fees
+		return nil
+	}
+}
+
+// TxOptionFeeGranter makes the Tx fees paid from the feegrant allowance given by granter to the Tx signer.
+func TxOptionFeeGranter(granter sdk.AccAddress) txOption {
+	return func(opts *txOptions) error {
+		if granter.Empty() {
+			err := errors.New("fee granter address is empty")
+			return err
+		}
+
+		opts.FeeGranter = granter
+		return nil
+	}
+}
+
+// TxOptionFeePayer makes the Tx fees paid by another account, which co-signs the Tx.
+// The payer account sequence is tracked by the client, so all its keys can broadcast Txns paid by the same
+// payer concurrently, but the payer must not sign Txns elsewhere meanwhile. The payer must not be the Tx signer.
+// In EIP-712 mode it overrides EIP712Options.FeePayer.
+func TxOptionFeePayer(payer TxSigner) txOption {
+	return func(opts *txOptions) error {
+		if payer == nil {
+			err := errors.New("fee payer must not be nil")
+			return err
+		}
+
+		opts.FeePayer = payer
+		return nil
+	}
+}
+
+// OptionTxDefaults sets Tx options applied to all Txns of the client, including the queued ones.
+func OptionTxDefaults(options ...txOption) cosmosClientOption {
+	return func(opts *cosmosClientOptions) error {
+		for _, opt := range options {
+			if err := opt(&opts.TxDefaults); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+type txOptionsKey struct{}
+
+// ContextWithTxOptions returns ctx carrying Tx options for a single call of SyncBroadcastMsgWithContext,
+// AsyncBroadcastMsgWithContext or SimulateMsgWithContext. They override the defaults set by OptionTxDefaults.
+// Invalid options make the call fail.
+func ContextWithTxOptions(ctx context.Context, options ...txOption) context.Context {
+	if prev, ok := ctx.Value(txOptionsKey{}).([]txOption); ok {
+		options = append(append([]txOption(nil), prev...), options...)
+	}
+
+	return context.WithValue(ctx, txOptionsKey{}, options)
+}
+
+// txOptionsOf returns the client defaults with the options carried by ctx applied.
+func (c *cosmosClient) txOptionsOf(ctx context.Context) (*txOptions, error) {
+	opts := c.opts.TxDefaults
+
+	options, _ := ctx.Value(txOptionsKey{}).([]txOption)
+	for _, opt := range options {
+		if err := opt(&opts); err != nil {
+			err = errors.Wrap(err, "error in a tx option")
+			return nil, err
+		}
+	}
+
+	return &opts, nil
+}
+
+// applyTxOptions sets memo, fees and timeout height on the factory. The timeout height is made absolute
+// using the latest block height of the node.
+func applyTxOptions(ctx context.Context, clientCtx client.Context, txf tx.Factory, opts *txOptions) (tx.Factory, error) {
+	if len(opts.Memo) > 0 {
+		txf = txf.WithMemo(opts.Memo)
+	}
+
+	if len(opts.Fees) > 0 {
+		txf = txf.WithGasPrices("").WithFees(opts.Fees)
+	}
+
+	if opts.TimeoutBlocks > 0 {
+		status, err := clientCtx.Client.Status(ctx)
+		if err != nil {
+			err = errors.Wrap(err, "failed to get the latest block height")
+			return txf, err
+		}
+
+		txf = txf.WithTimeoutHeight(uint64(status.SyncInfo.LatestBlockHeight) + opts.TimeoutBlocks)
+	}
+
+	return txf, nil
+}
+
+// feePayerAccount is the fee payer with its account number and sequence, it signs along with the Tx signer.
+type feePayerAccount struct {
+	signer TxSigner
+	accNum uint64
+	accSeq uint64
+}
+
+// checkFeePayer rejects a fee payer that is the Tx signer itself. The Tx signers are deduplicated
+// by the chain, so such a Tx would carry more signatures than signers.
+func checkFeePayer(signerAddr sdk.AccAddress, payer *feePayerAccount) error {
+	if payer.signer.Address().Equals(signerAddr) {
+		err := errors.Errorf("fee payer %s is the Tx signer", signerAddr)
+		return err
+	}
+
+	return nil
+}
+
+// feePayerNonces tracks account sequences of fee payers, so Txns of all keys paid by the same payer
+// get consecutive sequences, the way signing keys track their own. A payer sequence is read from
+// the chain on first use, and again once a Tx it has been reserved for is rejected.
+type feePayerNonces struct {
+	mux      *sync.Mutex
+	accounts map[string]*feePayerNonce
+}
+
+type feePayerNonce struct {
+	accNum uint64
+	accSeq uint64
+	synced bool
+}
+
+func newFeePayerNonces() *feePayerNonces {
+	return &feePayerNonces{
+		mux:      new(sync.Mutex),
+		accounts: make(map[string]*feePayerNonce),
+	}
+}
+
+// nonce returns the tracked nonce of the payer, syncing it with the chain if needed. Must be called under the lock.
+func (n *feePayerNonces) nonce(clientCtx client.Context, txf tx.Factory, payer TxSigner) (*feePayerNonce, error) {
+	nonce, ok := n.accounts[payer.Address().String()]
+	if ok && nonce.synced {
+		return nonce, nil
+	}
+
+	accNum, accSeq, err := txf.AccountRetriever().GetAccountNumberSequence(clientCtx, payer.Address())
+	if err != nil {
+		err = errors.Wrapf(err, "failed to get account num and seq of fee payer %s", payer.Address())
+		return nil, err
+	}
+
+	nonce = &feePayerNonce{
+		accNum: accNum,
+		accSeq: accSeq,
+		synced: true,
+	}
+	n.accounts[payer.Address().String()] = nonce
+
+	return nonce, nil
+}
+
+// peek returns the payer with the sequence the next Tx would be signed with, without reserving it.
+func (n *feePayerNonces) peek(clientCtx client.Context, txf tx.Factory, payer TxSigner) (*feePayerAccount, error) {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+
+	nonce, err := n.nonce(clientCtx, txf, payer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &feePayerAccount{
+		signer: payer,
+		accNum: nonce.accNum,
+		accSeq: nonce.accSeq,
+	}, nil
+}
+
+// acquire reserves the next sequence of the payer for a Tx, it must be released once the Tx is broadcasted.
+func (n *feePayerNonces) acquire(clientCtx client.Context, txf tx.Factory, payer TxSigner) (*feePayerAccount, error) {
+	n.mux.Lock()
+	defer n.mux.Unlock()
+
+	nonce, err := n.nonce(clientCtx, txf, payer)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &feePayerAccount{
+		signer: payer,
+		accNum: nonce.accNum,
+		accSeq: nonce.accSeq,
+	}
+	nonce.accSeq++
+
+	return account, nil
+}
+
+// release keeps the sequence consumed if the Tx has made it to the mempool. Otherwise the sequence is handed back,
+// unless there was a sequence mismatch or later sequences have been reserved meanwhile, those Txns are going
+// to be rejected as well, then the sequence is read from the chain again. Async broadcasts report a CheckTx
+// rejection in res only.
+func (n *feePayerNonces) release(account *feePayerAccount, res *sdk.TxResponse, err error) {
+	if err == nil && res != nil && res.Code != 0 && res.Height == 0 {
+		err = chainErrorOf(res)
+	}
+
+	if err == nil || IsTxNotIncluded(err) {
+		return
+	}
+
+	n.mux.Lock()
+	defer n.mux.Unlock()
+
+	nonce, ok := n.accounts[account.signer.Address().String()]
+	if !ok {
+		return
+	} else if nonce.accSeq == account.accSeq+1 && !isSequenceMismatch(err) {
+		nonce.accSeq = account.accSeq
+		return
+	}
+
+	nonce.synced = false
+}
+
+// setFeeDelegation sets the fee granter and payer of the Tx. The granter from opts
+// takes precedence over the one from client context.
+func setFeeDelegation(clientCtx client.Context, txBuilder client.TxBuilder, opts *txOptions, payer *feePayerAccount) error {
+	if !opts.FeeGranter.Empty() {
+		txBuilder.SetFeeGranter(opts.FeeGranter)
+	} else {
+		txBuilder.SetFeeGranter(clientCtx.GetFeeGranterAddress())
+	}
+
+	if payer == nil {
+		return nil
+	}
+
+	payerBuilder, ok := txBuilder.(interface {
+		SetFeePayer(feePayer sdk.AccAddress)
+	})
+	if !ok {
+		err := errors.Errorf("tx builder %T doesn't support fee payer", txBuilder)
+		return err
+	}
+
+	payerBuilder.SetFeePayer(payer.signer.Address())
+	return nil
+}
+
+// buildSimTx is tx.BuildSimTx that also sets the fee delegation, with an empty signature of the fee payer.
+func buildSimTx(
+	clientCtx client.Context,
+	txf tx.Factory,
+	opts *txOptions,
+	payer *feePayerAccount,
+	msgs ...sdk.Msg,
+) ([]byte, error) {
+	if payer != nil {
+		if err := checkFeePayer(clientCtx.GetFromAddress(), payer); err != nil {
+			return nil, err
+		}
+	}
+
+	txBuilder, err := tx.BuildUnsignedTx(txf, msgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setFeeDelegation(clientCtx, txBuilder, opts, payer); err != nil {
+		return nil, err
+	}
+
+	// the ante handler populates empty signatures with a sentinel pubkey
+	sigs := []signing.SignatureV2{{
+		PubKey: &secp256k1.PubKey{},
+		Data: &signing.SingleSignatureData{
+			SignMode: txf.SignMode(),
+		},
+		Sequence: txf.Sequence(),
+	}}
+
+	if payer != nil {
+		sigs = append(sigs, signing.SignatureV2{
+			PubKey: &secp256k1.PubKey{},
+			Data: &signing.SingleSignatureData{
+				SignMode: txf.SignMode(),
+			},
+			Sequence: payer.accSeq,
+		})
+	}
+
+	if err := txBuilder.SetSignatures(sigs...); err != nil {
+		return nil, err
+	}
+
+	return clientCtx.TxConfig.TxEncoder()(txBuilder.GetTx())
+}
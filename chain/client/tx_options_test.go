@@ -0,0 +1,215 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/pkg/errors"
+
+	"github.com/InjectiveLabs/sdk-go/chain/crypto/ethsecp256k1"
+)
+
+func TestTxOptionsOf(t *testing.T) {
+	opts := defaultCosmosClientOptions()
+	if err := OptionTxDefaults(TxOptionMemo("bot"), TxOptionTimeoutHeight(10))(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &cosmosClient{opts: opts}
+
+	ctx := ContextWithTxOptions(context.Background(), TxOptionMemo("order-42"))
+	ctx = ContextWithTxOptions(ctx, TxOptionFees("100inj"))
+
+	txOpts, err := c.txOptionsOf(ctx)
+	if err != nil {
+		t.Fatal(err)
+	} else if txOpts.Memo != "order-42" || txOpts.TimeoutBlocks != 10 || txOpts.Fees != "100inj" {
+		t.Fatalf("unexpected tx options %+v", txOpts)
+	}
+
+	if txOpts, err := c.txOptionsOf(context.Background()); err != nil {
+		t.Fatal(err)
+	} else if txOpts.Memo != "bot" || len(txOpts.Fees) > 0 {
+		t.Fatalf("expected client defaults only, got %+v", txOpts)
+	}
+
+	if _, err := c.txOptionsOf(ContextWithTxOptions(ctx, TxOptionGasLimit(0))); err == nil {
+		t.Fatal("expected error for zero gas limit")
+	}
+}
+
+func TestSignTxWithFeePayer(t *testing.T) {
+	signerKey, err := ethsecp256k1.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payerKey, err := ethsecp256k1.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientCtx, err := NewClientContext("injective-888", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer := NewPrivKeySigner(signerKey)
+	payer := &feePayerAccount{
+		signer: NewPrivKeySigner(payerKey),
+		accNum: 3,
+		accSeq: 9,
+	}
+
+	params := OfflineTxParams{
+		AccountNumber: 1,
+		Sequence:      2,
+		GasLimit:      200000,
+	}
+
+	from := signer.Address()
+	txBuilder, err := BuildOfflineTx(clientCtx, params, banktypes.NewMsgSend(from, from, sdk.NewCoins(sdk.NewInt64Coin("inj", 1))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txOpts := &txOptions{}
+	if err := setFeeDelegation(clientCtx, txBuilder, txOpts, payer); err != nil {
+		t.Fatal(err)
+	}
+
+	txf, err := NewOfflineTxFactory(clientCtx, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	self := &feePayerAccount{signer: signer}
+	if err := signTxWithFeePayer(clientCtx.TxConfig, txf, signer, self, txBuilder); err == nil {
+		t.Fatal("expected the signer to be rejected as its own fee payer")
+	}
+
+	if err := signTxWithFeePayer(clientCtx.TxConfig, txf, signer, payer, txBuilder); err != nil {
+		t.Fatal(err)
+	}
+
+	txn := txBuilder.GetTx()
+	if signers := txn.GetSigners(); len(signers) != 2 || !signers[1].Equals(payer.signer.Address()) {
+		t.Fatalf("expected the fee payer as the second signer, got %v", signers)
+	}
+
+	sigs, err := txn.GetSignaturesV2()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signerData := []authsigning.SignerData{
+		{ChainID: "injective-888", AccountNumber: 1, Sequence: 2},
+		{ChainID: "injective-888", AccountNumber: 3, Sequence: 9},
+	}
+
+	for idx, sig := range sigs {
+		data := sig.Data.(*signing.SingleSignatureData)
+		signBytes, err := clientCtx.TxConfig.SignModeHandler().GetSignBytes(data.SignMode, signerData[idx], txn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !sig.PubKey.VerifySignature(signBytes, data.Signature) {
+			t.Fatalf("signature %d doesn't verify against the final Tx", idx)
+		}
+	}
+}
+
+type sequenceRetriever struct {
+	client.AccountRetriever
+
+	seq     uint64
+	queries int
+}
+
+func (r *sequenceRetriever) EnsureExists(client.Context, sdk.AccAddress) error {
+	return nil
+}
+
+func (r *sequenceRetriever) GetAccountNumberSequence(client.Context, sdk.AccAddress) (uint64, uint64, error) {
+	r.queries++
+	return 3, r.seq, nil
+}
+
+func TestFeePayerNonces(t *testing.T) {
+	payerKey, err := ethsecp256k1.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payer := NewPrivKeySigner(payerKey)
+	retriever := &sequenceRetriever{seq: 9}
+	txf := tx.Factory{}.WithAccountRetriever(retriever)
+	nonces := newFeePayerNonces()
+
+	first, err := nonces.acquire(client.Context{}, txf, payer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := nonces.acquire(client.Context{}, txf, payer)
+	if err != nil {
+		t.Fatal(err)
+	} else if first.accSeq != 9 || second.accSeq != 10 || retriever.queries != 1 {
+		t.Fatalf("expected consecutive sequences from a single query, got %d and %d", first.accSeq, second.accSeq)
+	}
+
+	// the last reserved sequence is handed back when its Tx is rejected
+	nonces.release(first, &sdk.TxResponse{TxHash: "AB"}, nil)
+	nonces.release(second, nil, errors.New("failed to simulate Tx"))
+	if next, _ := nonces.peek(client.Context{}, txf, payer); next.accSeq != 10 {
+		t.Fatalf("expected sequence 10 to be reused, got %d", next.accSeq)
+	}
+
+	// an async broadcast rejected by CheckTx reports no error, the sequence is handed back still
+	third, _ := nonces.acquire(client.Context{}, txf, payer)
+	nonces.release(third, &sdk.TxResponse{
+		Codespace: sdkerrors.ErrInsufficientFee.Codespace(),
+		Code:      sdkerrors.ErrInsufficientFee.ABCICode(),
+	}, nil)
+	if next, _ := nonces.peek(client.Context{}, txf, payer); next.accSeq != 10 {
+		t.Fatalf("expected sequence 10 to be reused after a CheckTx rejection, got %d", next.accSeq)
+	}
+
+	// while a Tx that has failed in a block consumes its sequence
+	fourth, _ := nonces.acquire(client.Context{}, txf, payer)
+	nonces.release(fourth, &sdk.TxResponse{
+		Codespace: sdkerrors.ErrInsufficientFunds.Codespace(),
+		Code:      sdkerrors.ErrInsufficientFunds.ABCICode(),
+		Height:    5,
+	}, nil)
+	if next, _ := nonces.peek(client.Context{}, txf, payer); next.accSeq != 11 {
+		t.Fatalf("expected sequence 11 after an included Tx, got %d", next.accSeq)
+	}
+
+	// a sequence mismatch resyncs with the chain, whether async or not
+	retriever.seq = 12
+	fifth, _ := nonces.acquire(client.Context{}, txf, payer)
+	nonces.release(fifth, &sdk.TxResponse{
+		Codespace: sdkerrors.ErrWrongSequence.Codespace(),
+		Code:      sdkerrors.ErrWrongSequence.ABCICode(),
+		RawLog:    "account sequence mismatch, expected 12, got 11: incorrect account sequence",
+	}, nil)
+	if next, _ := nonces.peek(client.Context{}, txf, payer); next.accSeq != 12 || retriever.queries != 2 {
+		t.Fatalf("expected sequence 12 from the chain, got %d", next.accSeq)
+	}
+
+	retriever.seq = 13
+	sixth, _ := nonces.acquire(client.Context{}, txf, payer)
+	nonces.release(sixth, nil, NewChainError(sdkerrors.ErrWrongSequence.Codespace(), sdkerrors.ErrWrongSequence.ABCICode(), "account sequence mismatch, expected 13, got 12"))
+	if next, _ := nonces.peek(client.Context{}, txf, payer); next.accSeq != 13 || retriever.queries != 3 {
+		t.Fatalf("expected sequence 13 from the chain, got %d", next.accSeq)
+	}
+}
@@ -0,0 +1,191 @@
+// Package funding projects the funding of perpetual markets and the payments of positions.
+//
+// The chain accumulates the premium of each block with trades, the VWAP of the block relative to the mark price
+// weighted by the seconds since the last block with trades. At the funding timestamp, the funding rate is the
+// accumulated premium over the funding interval, scaled to an hourly rate, plus the hourly interest rate, capped
+// by the hourly funding rate cap. Longs pay the funding rate times the mark price per unit of position to shorts.
+package funding
+
+import (
+	"sort"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+)
+
+// Trade is an execution in a perpetual market, with the mark price of the block it was executed in.
+type Trade struct {
+	// Timestamp is the block time in seconds.
+	Timestamp int64
+	Price     sdk.Dec
+	Quantity  sdk.Dec
+	MarkPrice sdk.Dec
+}
+
+// Projection is the estimated funding of a perpetual market at its next funding timestamp.
+type Projection struct {
+	MarketID         common.Hash
+	FundingTimestamp int64
+
+	// CumulativePrice is the accumulated premium including the given trades.
+	CumulativePrice sdk.Dec
+	// Premium is the time weighted premium of the interval, scaled to an hour.
+	Premium sdk.Dec
+	// FundingRate is the premium plus the interest rate, capped.
+	FundingRate sdk.Dec
+	// Funding is what longs pay per unit of position, negative when shorts pay.
+	Funding           sdk.Dec
+	CumulativeFunding sdk.Dec
+}
+
+// Project estimates the next funding of the market from its funding state and the trades since, as if there were
+// no more trades until the funding timestamp. Trades already accounted in funding, or after the funding timestamp,
+// are ignored. The oracle mark price is the one at the funding timestamp.
+func Project(
+	info *exchangetypes.PerpetualMarketInfo,
+	funding *exchangetypes.PerpetualMarketFunding,
+	trades []*Trade,
+	markPrice sdk.Dec,
+) (*Projection, error) {
+	if info.FundingInterval <= 0 {
+		err := errors.Errorf("invalid funding interval %d", info.FundingInterval)
+		return nil, err
+	} else if markPrice.IsNil() || !markPrice.IsPositive() {
+		err := errors.Errorf("invalid mark price %s", markPrice)
+		return nil, err
+	}
+
+	marketID := common.HexToHash(info.MarketId)
+	cumulativePrice := funding.CumulativePrice
+	lastTimestamp := funding.LastTimestamp
+
+	sorted := make([]*Trade, 0, len(trades))
+	for _, trade := range trades {
+		if trade.Timestamp <= funding.LastTimestamp || trade.Timestamp > info.NextFundingTimestamp {
+			continue
+		} else if trade.MarkPrice.IsNil() || !trade.MarkPrice.IsPositive() {
+			err := errors.Errorf("invalid mark price %s of trade at %d", trade.MarkPrice, trade.Timestamp)
+			return nil, err
+		}
+
+		sorted = append(sorted, trade)
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp < sorted[j].Timestamp
+	})
+
+	for len(sorted) > 0 {
+		timestamp := sorted[0].Timestamp
+
+		vwapInfo := exchangetypes.NewPerpetualVwapInfo()
+		for len(sorted) > 0 && sorted[0].Timestamp == timestamp {
+			trade := sorted[0]
+			vwapInfo.ApplyVwap(marketID, trade.MarkPrice, exchangetypes.NewVwapData().ApplyExecution(trade.Price, trade.Quantity))
+			sorted = sorted[1:]
+		}
+
+		if vwapInfo[marketID].VwapData.Quantity.IsZero() {
+			continue
+		}
+
+		timeElapsed := timestamp - lastTimestamp
+		cumulativePrice = cumulativePrice.Add(vwapInfo.ComputeSyntheticVwapUnitDelta(marketID).MulInt64(timeElapsed))
+		lastTimestamp = timestamp
+	}
+
+	premium := cumulativePrice.QuoInt64(info.FundingInterval * 24)
+	fundingRate := premium.Add(info.HourlyInterestRate)
+	if fundingCap := info.HourlyFundingRateCap; fundingRate.Abs().GT(fundingCap) {
+		if fundingRate.IsNegative() {
+			fundingCap = fundingCap.Neg()
+		}
+		fundingRate = fundingCap
+	}
+
+	fundingAmount := fundingRate.Mul(markPrice)
+
+	return &Projection{
+		MarketID:          marketID,
+		FundingTimestamp:  info.NextFundingTimestamp,
+		CumulativePrice:   cumulativePrice,
+		Premium:           premium,
+		FundingRate:       fundingRate,
+		Funding:           fundingAmount,
+		CumulativeFunding: funding.CumulativeFunding.Add(fundingAmount),
+	}, nil
+}
+
+// Payment returns what the position receives at the next funding, negative if it pays.
+func (p *Projection) Payment(position *exchangetypes.Position) sdk.Dec {
+	return payment(position, p.Funding)
+}
+
+// payment returns what a position receives for the given funding per unit of position.
+func payment(position *exchangetypes.Position, funding sdk.Dec) sdk.Dec {
+	amount := position.Quantity.Mul(funding)
+	if position.IsLong {
+		return amount.Neg()
+	}
+
+	return amount
+}
+
+// Snapshot is the cumulative funding of a perpetual market at a time.
+type Snapshot struct {
+	// Timestamp is the funding time in seconds.
+	Timestamp         int64
+	CumulativeFunding sdk.Dec
+}
+
+// Payment is a funding payment of a position, negative if the position paid.
+type Payment struct {
+	Timestamp int64
+	Amount    sdk.Dec
+}
+
+// History is the funding PnL of a position over time.
+type History struct {
+	Payments []*Payment
+	// Total sums the payments.
+	Total sdk.Dec
+	// Unsettled is the funding not yet applied to the position margin, from its cumulative funding entry
+	// to the last snapshot.
+	Unsettled sdk.Dec
+}
+
+// FundingHistory computes the funding payments of the position from snapshots of its market. The earliest snapshot
+// is the one at the last change of the position quantity or direction.
+func FundingHistory(position *exchangetypes.Position, snapshots []*Snapshot) (*History, error) {
+	if len(snapshots) == 0 {
+		err := errors.New("no funding snapshots")
+		return nil, err
+	}
+
+	sorted := append([]*Snapshot(nil), snapshots...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp < sorted[j].Timestamp
+	})
+
+	history := &History{
+		Payments: make([]*Payment, 0, len(sorted)-1),
+		Total:    sdk.ZeroDec(),
+	}
+
+	for idx := 1; idx < len(sorted); idx++ {
+		amount := payment(position, sorted[idx].CumulativeFunding.Sub(sorted[idx-1].CumulativeFunding))
+		history.Payments = append(history.Payments, &Payment{
+			Timestamp: sorted[idx].Timestamp,
+			Amount:    amount,
+		})
+		history.Total = history.Total.Add(amount)
+	}
+
+	last := sorted[len(sorted)-1]
+	history.Unsettled = payment(position, last.CumulativeFunding.Sub(position.CumulativeFundingEntry))
+
+	return history, nil
+}
@@ -0,0 +1,76 @@
+package funding
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+)
+
+func TestProject(t *testing.T) {
+	info := &exchangetypes.PerpetualMarketInfo{
+		HourlyFundingRateCap: sdk.MustNewDecFromStr("0.0001"),
+		HourlyInterestRate:   sdk.ZeroDec(),
+		NextFundingTimestamp: 3600,
+		FundingInterval:      3600,
+	}
+	funding := &exchangetypes.PerpetualMarketFunding{
+		CumulativeFunding: sdk.NewDec(10),
+		CumulativePrice:   sdk.ZeroDec(),
+		LastTimestamp:     0,
+	}
+	trades := []*Trade{
+		{Timestamp: 2400, Price: sdk.NewDec(99), Quantity: sdk.NewDec(2), MarkPrice: sdk.NewDec(100)},
+		{Timestamp: 1800, Price: sdk.NewDec(101), Quantity: sdk.OneDec(), MarkPrice: sdk.NewDec(100)},
+		{Timestamp: 1800, Price: sdk.NewDec(103), Quantity: sdk.OneDec(), MarkPrice: sdk.NewDec(100)},
+		// already accounted
+		{Timestamp: 0, Price: sdk.NewDec(200), Quantity: sdk.OneDec(), MarkPrice: sdk.NewDec(100)},
+	}
+
+	projection, err := Project(info, funding, trades, sdk.NewDec(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2% premium for 1800s, then -1% for 600s
+	if !projection.CumulativePrice.Equal(sdk.NewDec(30)) {
+		t.Fatalf("unexpected cumulative price %s", projection.CumulativePrice)
+	} else if !projection.FundingRate.Equal(info.HourlyFundingRateCap) || !projection.CumulativeFunding.Equal(sdk.MustNewDecFromStr("10.01")) {
+		t.Fatalf("expected the funding rate to be capped, got %s", projection.FundingRate)
+	}
+
+	long := &exchangetypes.Position{IsLong: true, Quantity: sdk.NewDec(2)}
+	if payment := projection.Payment(long); !payment.Equal(sdk.MustNewDecFromStr("-0.02")) {
+		t.Fatalf("unexpected long payment %s", payment)
+	}
+
+	info.HourlyFundingRateCap = sdk.OneDec()
+	if projection, err := Project(info, funding, trades, sdk.NewDec(100)); err != nil {
+		t.Fatal(err)
+	} else if !projection.FundingRate.Equal(sdk.NewDec(30).QuoInt64(3600 * 24)) {
+		t.Fatalf("unexpected funding rate %s", projection.FundingRate)
+	}
+}
+
+func TestFundingHistory(t *testing.T) {
+	short := &exchangetypes.Position{
+		Quantity:               sdk.NewDec(2),
+		CumulativeFundingEntry: sdk.NewDec(10),
+	}
+
+	history, err := FundingHistory(short, []*Snapshot{
+		{Timestamp: 7200, CumulativeFunding: sdk.MustNewDecFromStr("10.5")},
+		{Timestamp: 3600, CumulativeFunding: sdk.NewDec(10)},
+		{Timestamp: 10800, CumulativeFunding: sdk.MustNewDecFromStr("10.2")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(history.Payments) != 2 || !history.Payments[0].Amount.Equal(sdk.OneDec()) || history.Payments[1].Timestamp != 10800 {
+		t.Fatalf("unexpected payments %+v", history.Payments)
+	} else if !history.Total.Equal(sdk.MustNewDecFromStr("0.4")) || !history.Unsettled.Equal(sdk.MustNewDecFromStr("0.4")) {
+		t.Fatalf("unexpected total %s and unsettled %s", history.Total, history.Unsettled)
+	}
+}
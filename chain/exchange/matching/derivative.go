@@ -0,0 +1,562 @@
+package matching
+
+import (
+	"sort"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+)
+
+// DerivativeAuction is the state of a derivative market at the end of a block, in chain units.
+// Orders and positions are copied by the simulation, the auction is left untouched.
+type DerivativeAuction struct {
+	Market    *exchangetypes.DerivativeMarket
+	MarkPrice sdk.Dec
+	// Funding of a perpetual market, nil for expiry futures.
+	Funding *exchangetypes.PerpetualMarketFunding
+	// Positions of subaccounts trading in the auction, by subaccount ID.
+	Positions map[common.Hash]*exchangetypes.Position
+
+	// RestingBuys and RestingSells are the orderbook before the block, in time priority.
+	RestingBuys  []*exchangetypes.DerivativeLimitOrder
+	RestingSells []*exchangetypes.DerivativeLimitOrder
+
+	// LimitOrders and MarketOrders are the orders posted in the block. Market orders without
+	// a margin hold get the one the chain holds at their worst price.
+	LimitOrders  []*exchangetypes.DerivativeLimitOrder
+	MarketOrders []*exchangetypes.DerivativeMarketOrder
+}
+
+// DerivativeResult is the outcome of a derivative auction.
+type DerivativeResult struct {
+	// MarketExecution and LimitExecution are the batches the chain applies, nil if there were no such orders.
+	MarketExecution *exchangetypes.DerivativeBatchExecutionData
+	LimitExecution  *exchangetypes.DerivativeBatchExecutionData
+
+	// ClearingPrice of the limit orders matching, nil if no limit orders matched.
+	ClearingPrice sdk.Dec
+
+	Fills         []*Fill
+	Cancellations []*Cancellation
+
+	// DepositDeltas sum the quote deposit changes of all batches.
+	DepositDeltas exchangetypes.DepositDeltas
+	// Positions after the auction of subaccounts that traded, with funding applied, by subaccount ID.
+	Positions map[common.Hash]*exchangetypes.Position
+
+	// RestingBuys and RestingSells are the orderbook after the block, in price priority.
+	RestingBuys  []*exchangetypes.DerivativeLimitOrder
+	RestingSells []*exchangetypes.DerivativeLimitOrder
+}
+
+type derivativeSimulation struct {
+	auction        *DerivativeAuction
+	market         *exchangetypes.DerivativeMarket
+	positionStates map[common.Hash]*exchangetypes.PositionState
+	result         *DerivativeResult
+
+	restingBuys  []*exchangetypes.DerivativeLimitOrder
+	restingSells []*exchangetypes.DerivativeLimitOrder
+}
+
+// SimulateDerivative runs one end-block batch auction of the derivative market. New orders the chain
+// would reject at the mark price, and reduce-only orders exceeding the position they reduce, are canceled
+// before matching.
+func SimulateDerivative(auction *DerivativeAuction) (*DerivativeResult, error) {
+	if auction.Market == nil {
+		err := errors.New("market is nil")
+		return nil, err
+	} else if auction.MarkPrice.IsNil() || !auction.MarkPrice.IsPositive() {
+		err := errors.Errorf("invalid mark price %s", auction.MarkPrice)
+		return nil, err
+	}
+
+	s := &derivativeSimulation{
+		auction:        auction,
+		market:         auction.Market,
+		positionStates: exchangetypes.NewPositionStates(),
+		result: &DerivativeResult{
+			DepositDeltas: exchangetypes.NewDepositDeltas(),
+			Positions:     make(map[common.Hash]*exchangetypes.Position),
+		},
+	}
+
+	var err error
+	if s.restingBuys, err = copyDerivativeLimitOrders(auction.RestingBuys, true); err != nil {
+		err = errors.Wrap(err, "invalid resting buys")
+		return nil, err
+	} else if s.restingSells, err = copyDerivativeLimitOrders(auction.RestingSells, false); err != nil {
+		err = errors.Wrap(err, "invalid resting sells")
+		return nil, err
+	}
+
+	var limitBuys, limitSells []*exchangetypes.DerivativeLimitOrder
+	for _, o := range auction.LimitOrders {
+		if o.IsBuy() {
+			limitBuys = append(limitBuys, o)
+		} else {
+			limitSells = append(limitSells, o)
+		}
+	}
+
+	transientBuys, err := copyDerivativeLimitOrders(limitBuys, true)
+	if err != nil {
+		err = errors.Wrap(err, "invalid limit buys")
+		return nil, err
+	}
+
+	transientSells, err := copyDerivativeLimitOrders(limitSells, false)
+	if err != nil {
+		err = errors.Wrap(err, "invalid limit sells")
+		return nil, err
+	}
+
+	marketOrders, err := s.copyMarketOrders(auction.MarketOrders)
+	if err != nil {
+		err = errors.Wrap(err, "invalid market orders")
+		return nil, err
+	}
+
+	if len(marketOrders) > 0 {
+		s.result.MarketExecution = s.executeMarketOrders(marketOrders)
+	}
+
+	if len(transientBuys) > 0 || len(transientSells) > 0 {
+		s.result.LimitExecution = s.executeLimitOrders(transientBuys, transientSells)
+	}
+
+	for subaccountID, state := range s.positionStates {
+		s.result.Positions[subaccountID] = state.Position
+	}
+
+	s.result.RestingBuys = sortedDerivativeLimitOrders(s.restingBuys, true)
+	s.result.RestingSells = sortedDerivativeLimitOrders(s.restingSells, false)
+
+	return s.result, nil
+}
+
+// rejection returns why the chain would reject a new order, empty if it's accepted.
+func (s *derivativeSimulation) rejection(orderInfo *exchangetypes.OrderInfo, isBuy bool, margin sdk.Dec, markPriceCheck func() error) string {
+	if margin.IsPositive() {
+		if err := markPriceCheck(); err != nil {
+			return err.Error()
+		}
+
+		return ""
+	}
+
+	position := s.auction.Positions[orderInfo.SubaccountID()]
+	if position == nil || position.Quantity.IsZero() || position.IsLong == isBuy {
+		return "no position to reduce"
+	} else if orderInfo.Quantity.GT(position.Quantity) {
+		return "reduce-only order exceeds the position"
+	}
+
+	return ""
+}
+
+func (s *derivativeSimulation) cancel(orderHash common.Hash, orderInfo *exchangetypes.OrderInfo, isBuy, isLimit bool, quantity sdk.Dec, reason string) {
+	s.result.Cancellations = append(s.result.Cancellations, &Cancellation{
+		OrderHash:    orderHash,
+		SubaccountID: orderInfo.SubaccountID(),
+		IsBuy:        isBuy,
+		IsLimit:      isLimit,
+		Quantity:     quantity,
+		Reason:       reason,
+	})
+}
+
+// positionState returns the position of the subaccount, with funding applied on first use.
+func (s *derivativeSimulation) positionState(subaccountID common.Hash, isBuy bool) *exchangetypes.PositionState {
+	if state, ok := s.positionStates[subaccountID]; ok {
+		return state
+	}
+
+	var cumulativeFunding sdk.Dec
+	if s.auction.Funding != nil {
+		cumulativeFunding = s.auction.Funding.CumulativeFunding
+	}
+
+	position := exchangetypes.NewPosition(isBuy, cumulativeFunding)
+	if p := s.auction.Positions[subaccountID]; p != nil {
+		copied := *p
+		position = &copied
+	}
+
+	state := position.ApplyFundingAndGetUpdatedPositionState(s.auction.Funding)
+	if s.auction.Funding != nil {
+		position.CumulativeFundingEntry = cumulativeFunding
+	}
+
+	s.positionStates[subaccountID] = state
+	return state
+}
+
+// settle applies an order fill to the position of its subaccount. holdRelease is the part of the order
+// margin hold released by the fill, it goes back to the available balance.
+func (s *derivativeSimulation) settle(
+	orderInfo *exchangetypes.OrderInfo,
+	orderHash common.Hash,
+	isBuy bool,
+	margin, fillQuantity, executionPrice, feeRate, holdRelease sdk.Dec,
+) *exchangetypes.DerivativeOrderStateExpansion {
+	expansion := &exchangetypes.DerivativeOrderStateExpansion{
+		SubaccountID:          orderInfo.SubaccountID(),
+		Payout:                sdk.ZeroDec(),
+		TotalBalanceDelta:     sdk.ZeroDec(),
+		AvailableBalanceDelta: holdRelease,
+		AuctionFeeReward:      sdk.ZeroDec(),
+		FeeRecipientReward:    sdk.ZeroDec(),
+		FeeRecipient:          common.HexToAddress(orderInfo.FeeRecipient),
+		OrderHash:             orderHash,
+	}
+
+	if !fillQuantity.IsPositive() {
+		return expansion
+	}
+
+	tradingFee := executionPrice.Mul(fillQuantity).Mul(feeRate)
+	positionDelta := &exchangetypes.PositionDelta{
+		IsLong:            isBuy,
+		ExecutionQuantity: fillQuantity,
+		ExecutionMargin:   margin.Mul(fillQuantity).Quo(orderInfo.Quantity),
+		ExecutionPrice:    executionPrice,
+	}
+
+	position := s.positionState(expansion.SubaccountID, isBuy).Position
+	payout, _, _, collateralizationMargin := position.ApplyPositionDelta(positionDelta, tradingFee)
+
+	// reduce-only orders pay the fee from the position PnL
+	totalBalanceDelta := payout.Sub(collateralizationMargin)
+	if margin.IsPositive() {
+		totalBalanceDelta = totalBalanceDelta.Sub(tradingFee)
+	}
+
+	expansion.PositionDelta = positionDelta
+	expansion.Payout = payout
+	expansion.TotalBalanceDelta = totalBalanceDelta
+	expansion.AvailableBalanceDelta = totalBalanceDelta.Add(holdRelease)
+	expansion.FeeRecipientReward = s.market.RelayerFeeShareRate.Mul(tradingFee)
+	expansion.AuctionFeeReward = tradingFee.Sub(expansion.FeeRecipientReward)
+
+	return expansion
+}
+
+// limitHoldRelease is the margin hold of the filled quantity of a limit order held at feeRate.
+func limitHoldRelease(o *exchangetypes.DerivativeLimitOrder, fillQuantity, feeRate sdk.Dec) sdk.Dec {
+	if o.IsReduceOnly() {
+		return sdk.ZeroDec()
+	}
+
+	executionMargin := o.Margin.Mul(fillQuantity).Quo(o.OrderInfo.Quantity)
+	return executionMargin.Add(o.OrderInfo.Price.Mul(fillQuantity).Mul(feeRate))
+}
+
+// settleLimitFill settles a limit order fill and deducts it from the order fillable quantity.
+func (s *derivativeSimulation) settleLimitFill(
+	o *exchangetypes.DerivativeLimitOrder,
+	fillQuantity, executionPrice, feeRate, holdRelease sdk.Dec,
+) *exchangetypes.DerivativeOrderStateExpansion {
+	expansion := s.settle(&o.OrderInfo, o.Hash(), o.IsBuy(), o.Margin, fillQuantity, executionPrice, feeRate, holdRelease)
+
+	o.Fillable = o.Fillable.Sub(fillQuantity)
+	expansion.LimitOrderFilledDelta = &exchangetypes.DerivativeLimitOrderDelta{
+		Order:          o,
+		FillQuantity:   fillQuantity,
+		CancelQuantity: sdk.ZeroDec(),
+	}
+
+	return expansion
+}
+
+func (s *derivativeSimulation) executeMarketOrders(orders []*exchangetypes.DerivativeMarketOrder) *exchangetypes.DerivativeBatchExecutionData {
+	data := new(exchangetypes.DerivativeMarketOrderExpansionData)
+
+	for _, isBuy := range []bool{true, false} {
+		var accepted []*exchangetypes.DerivativeMarketOrder
+		var cancels []*exchangetypes.DerivativeMarketOrderCancel
+
+		for _, o := range orders {
+			if o.IsBuy() != isBuy {
+				continue
+			}
+
+			reason := s.rejection(&o.OrderInfo, isBuy, o.Margin, func() error {
+				return o.CheckInitialMarginRequirementMarkPriceThreshold(s.market.InitialMarginRatio, s.auction.MarkPrice)
+			})
+			if len(reason) > 0 {
+				s.cancel(o.Hash(), &o.OrderInfo, isBuy, false, o.OrderInfo.Quantity, reason)
+				cancels = append(cancels, &exchangetypes.DerivativeMarketOrderCancel{
+					MarketOrder:    o,
+					CancelQuantity: o.OrderInfo.Quantity,
+				})
+				continue
+			}
+
+			accepted = append(accepted, o)
+		}
+
+		if len(accepted) == 0 && len(cancels) == 0 {
+			continue
+		}
+
+		resting := s.restingSells
+		if !isBuy {
+			resting = s.restingBuys
+		}
+
+		marketOrders := make([]*marketOrder, 0, len(accepted))
+		for _, o := range accepted {
+			marketOrders = append(marketOrders, newMarketOrder(o.OrderInfo.Price, o.OrderInfo.Quantity))
+		}
+
+		restingSide := newBookSide(!isBuy, derivativeBookOrders(resting, false), nil)
+		clearingPrice, clearingQuantity := matchMarketOrders(isBuy, marketOrders, restingSide)
+
+		// resting orders settle first, their positions are updated in priority order
+		limitExpansions := make([]*exchangetypes.DerivativeOrderStateExpansion, 0)
+		for _, r := range restingSide.orders {
+			if !r.filled.IsPositive() {
+				continue
+			}
+
+			o := resting[r.idx]
+			limitExpansions = append(limitExpansions, s.settleLimitFill(
+				o, r.filled, o.OrderInfo.Price, s.market.MakerFeeRate,
+				limitHoldRelease(o, r.filled, s.market.MakerFeeRate),
+			))
+		}
+
+		marketExpansions := make([]*exchangetypes.DerivativeOrderStateExpansion, 0, len(accepted))
+		for idx, o := range accepted {
+			filled := marketOrders[idx].filled
+			if unfilled := o.OrderInfo.Quantity.Sub(filled); unfilled.IsPositive() {
+				s.cancel(o.Hash(), &o.OrderInfo, isBuy, false, unfilled, "market order not filled")
+				cancels = append(cancels, &exchangetypes.DerivativeMarketOrderCancel{
+					MarketOrder:    o,
+					CancelQuantity: unfilled,
+				})
+			}
+
+			if !filled.IsPositive() {
+				// the cancellation refunds the whole margin hold
+				continue
+			}
+
+			expansion := s.settle(&o.OrderInfo, o.Hash(), isBuy, o.Margin, filled, clearingPrice, s.market.TakerFeeRate, o.MarginHold)
+			expansion.MarketOrderFilledDelta = &exchangetypes.DerivativeMarketOrderDelta{
+				Order:        o,
+				FillQuantity: filled,
+			}
+			marketExpansions = append(marketExpansions, expansion)
+		}
+
+		data.SetExecutionData(isBuy, clearingPrice, clearingQuantity, nil, marketExpansions, limitExpansions, cancels)
+
+		if isBuy {
+			s.restingSells = fillableDerivativeLimitOrders(s.restingSells)
+		} else {
+			s.restingBuys = fillableDerivativeLimitOrders(s.restingBuys)
+		}
+	}
+
+	batch := data.GetMarketDerivativeBatchExecutionData(s.market, s.auction.MarkPrice, s.auction.Funding, s.positionStates)
+	for _, md := range data.MarketBuyOrderCancels {
+		md.ApplyDerivativeMarketCancellation(batch.DepositDeltas)
+	}
+	for _, md := range data.MarketSellOrderCancels {
+		md.ApplyDerivativeMarketCancellation(batch.DepositDeltas)
+	}
+
+	s.applyBatch(batch,
+		batch.MarketBuyOrderExecutionEvent, batch.MarketSellOrderExecutionEvent,
+		batch.RestingLimitBuyOrderExecutionEvent, batch.RestingLimitSellOrderExecutionEvent,
+	)
+
+	return batch
+}
+
+func (s *derivativeSimulation) executeLimitOrders(transientBuys, transientSells []*exchangetypes.DerivativeLimitOrder) *exchangetypes.DerivativeBatchExecutionData {
+	data := new(exchangetypes.DerivativeMatchingExpansionData)
+
+	transientBuys, data.TransientLimitBuyOrderCancels = s.acceptLimitOrders(transientBuys)
+	transientSells, data.TransientLimitSellOrderCancels = s.acceptLimitOrders(transientSells)
+
+	buys := newBookSide(true, derivativeBookOrders(s.restingBuys, false), derivativeBookOrders(transientBuys, true))
+	sells := newBookSide(false, derivativeBookOrders(s.restingSells, false), derivativeBookOrders(transientSells, true))
+	data.ClearingPrice, data.ClearingQuantity = matchLimitOrders(buys, sells)
+
+	data.RestingLimitBuyExpansions, data.TransientLimitBuyExpansions, data.NewRestingLimitBuyOrders = s.settleLimitSide(buys, s.restingBuys, transientBuys, data.ClearingPrice)
+	data.RestingLimitSellExpansions, data.TransientLimitSellExpansions, data.NewRestingLimitSellOrders = s.settleLimitSide(sells, s.restingSells, transientSells, data.ClearingPrice)
+
+	batch := data.GetLimitMatchingDerivativeBatchExecutionData(s.market, s.auction.MarkPrice, s.auction.Funding, s.positionStates)
+	s.applyBatch(batch,
+		batch.RestingLimitBuyOrderExecutionEvent, batch.RestingLimitSellOrderExecutionEvent,
+		batch.TransientLimitBuyOrderExecutionEvent, batch.TransientLimitSellOrderExecutionEvent,
+	)
+	s.result.ClearingPrice = data.ClearingPrice
+
+	s.restingBuys = append(fillableDerivativeLimitOrders(s.restingBuys), data.NewRestingLimitBuyOrders...)
+	s.restingSells = append(fillableDerivativeLimitOrders(s.restingSells), data.NewRestingLimitSellOrders...)
+
+	return batch
+}
+
+// acceptLimitOrders splits new limit orders into the ones accepted by the chain and the canceled ones.
+func (s *derivativeSimulation) acceptLimitOrders(orders []*exchangetypes.DerivativeLimitOrder) (accepted, canceled []*exchangetypes.DerivativeLimitOrder) {
+	for _, o := range orders {
+		reason := s.rejection(&o.OrderInfo, o.IsBuy(), o.Margin, func() error {
+			return o.CheckInitialMarginRequirementMarkPriceThreshold(s.market.InitialMarginRatio, s.auction.MarkPrice)
+		})
+		if len(reason) > 0 {
+			s.cancel(o.Hash(), &o.OrderInfo, o.IsBuy(), true, o.Fillable, reason)
+			canceled = append(canceled, o)
+			continue
+		}
+
+		accepted = append(accepted, o)
+	}
+
+	return accepted, canceled
+}
+
+// settleLimitSide settles fills of one side at the clearing price. Resting orders pay the maker fee.
+// Transient orders pay the taker fee, and get the difference to the maker fee back for the quantity
+// left resting.
+func (s *derivativeSimulation) settleLimitSide(
+	side *bookSide,
+	resting, transient []*exchangetypes.DerivativeLimitOrder,
+	clearingPrice sdk.Dec,
+) (restingExpansions, transientExpansions []*exchangetypes.DerivativeOrderStateExpansion, newResting []*exchangetypes.DerivativeLimitOrder) {
+	restingExpansions = make([]*exchangetypes.DerivativeOrderStateExpansion, 0)
+	transientExpansions = make([]*exchangetypes.DerivativeOrderStateExpansion, 0, len(transient))
+
+	for _, r := range side.orders {
+		if !r.transient {
+			if !r.filled.IsPositive() {
+				continue
+			}
+
+			o := resting[r.idx]
+			restingExpansions = append(restingExpansions, s.settleLimitFill(
+				o, r.filled, clearingPrice, s.market.MakerFeeRate,
+				limitHoldRelease(o, r.filled, s.market.MakerFeeRate),
+			))
+			continue
+		}
+
+		o := transient[r.idx]
+		holdRelease := limitHoldRelease(o, r.filled, s.market.TakerFeeRate)
+		if unfilled := o.Fillable.Sub(r.filled); unfilled.IsPositive() && o.IsVanilla() {
+			feeRefund := unfilled.Mul(o.OrderInfo.Price).Mul(s.market.TakerFeeRate.Sub(s.market.MakerFeeRate))
+			holdRelease = holdRelease.Add(feeRefund)
+		}
+
+		transientExpansions = append(transientExpansions, s.settleLimitFill(o, r.filled, clearingPrice, s.market.TakerFeeRate, holdRelease))
+		if o.Fillable.IsPositive() {
+			newResting = append(newResting, o)
+		}
+	}
+
+	return restingExpansions, transientExpansions, newResting
+}
+
+func (s *derivativeSimulation) applyBatch(batch *exchangetypes.DerivativeBatchExecutionData, events ...*exchangetypes.EventBatchDerivativeExecution) {
+	mergeDepositDeltas(s.result.DepositDeltas, batch.DepositDeltas)
+
+	for _, ev := range events {
+		if ev == nil {
+			continue
+		}
+
+		for _, trade := range ev.Trades {
+			s.result.Fills = append(s.result.Fills, &Fill{
+				OrderHash:     common.BytesToHash(trade.OrderHash),
+				SubaccountID:  common.BytesToHash(trade.SubaccountId),
+				IsBuy:         ev.IsBuy,
+				ExecutionType: ev.ExecutionType,
+				Quantity:      trade.PositionDelta.ExecutionQuantity,
+				Price:         trade.PositionDelta.ExecutionPrice,
+				Fee:           trade.Fee,
+				PositionDelta: trade.PositionDelta,
+				Payout:        trade.Payout,
+			})
+		}
+	}
+}
+
+func (s *derivativeSimulation) copyMarketOrders(orders []*exchangetypes.DerivativeMarketOrder) ([]*exchangetypes.DerivativeMarketOrder, error) {
+	copied := make([]*exchangetypes.DerivativeMarketOrder, 0, len(orders))
+	for _, o := range orders {
+		if o.OrderInfo.Price.IsNil() || o.OrderInfo.Quantity.IsNil() || o.Margin.IsNil() {
+			err := errors.Errorf("order %s has no price, quantity or margin", o.Hash().Hex())
+			return nil, err
+		}
+
+		order := *o
+		if order.MarginHold.IsNil() {
+			order.MarginHold = sdk.ZeroDec()
+			if order.IsVanilla() {
+				order.MarginHold = order.Margin.Add(order.OrderInfo.GetFeeAmount(s.market.TakerFeeRate))
+			}
+		}
+
+		copied = append(copied, &order)
+	}
+
+	return copied, nil
+}
+
+func copyDerivativeLimitOrders(orders []*exchangetypes.DerivativeLimitOrder, isBuy bool) ([]*exchangetypes.DerivativeLimitOrder, error) {
+	copied := make([]*exchangetypes.DerivativeLimitOrder, 0, len(orders))
+	for _, o := range orders {
+		hash := o.Hash().Hex()
+		if o.IsBuy() != isBuy {
+			err := errors.Errorf("order %s is on the wrong side", hash)
+			return nil, err
+		} else if o.OrderInfo.Price.IsNil() || o.OrderInfo.Quantity.IsNil() || o.Fillable.IsNil() || o.Margin.IsNil() {
+			err := errors.Errorf("order %s has no price, quantity, fillable quantity or margin", hash)
+			return nil, err
+		}
+
+		order := *o
+		copied = append(copied, &order)
+	}
+
+	return copied, nil
+}
+
+func derivativeBookOrders(orders []*exchangetypes.DerivativeLimitOrder, transient bool) []*bookOrder {
+	bookOrders := make([]*bookOrder, 0, len(orders))
+	for idx, o := range orders {
+		bookOrders = append(bookOrders, newBookOrder(o.OrderInfo.Price, o.Fillable, transient, idx))
+	}
+
+	return bookOrders
+}
+
+func fillableDerivativeLimitOrders(orders []*exchangetypes.DerivativeLimitOrder) []*exchangetypes.DerivativeLimitOrder {
+	fillable := orders[:0]
+	for _, o := range orders {
+		if o.Fillable.IsPositive() {
+			fillable = append(fillable, o)
+		}
+	}
+
+	return fillable
+}
+
+func sortedDerivativeLimitOrders(orders []*exchangetypes.DerivativeLimitOrder, isBuy bool) []*exchangetypes.DerivativeLimitOrder {
+	sort.SliceStable(orders, func(i, j int) bool {
+		if isBuy {
+			return orders[i].OrderInfo.Price.GT(orders[j].OrderInfo.Price)
+		}
+
+		return orders[i].OrderInfo.Price.LT(orders[j].OrderInfo.Price)
+	})
+
+	return orders
+}
@@ -0,0 +1,207 @@
+// Package matching simulates the end-block batch auction of exchange markets offline. Orders are matched
+// the way the chain does it, and the resulting fills are settled by the chain types, so fees, refunds
+// and deposit and position changes are those the chain would apply.
+//
+// Matching of one block goes in two stages: market orders are filled against the resting orderbook
+// at a uniform clearing price per side, then limit orders posted in the block are matched with each other
+// and with the resting orderbook at one clearing price.
+package matching
+
+import (
+	"sort"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/common"
+
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+)
+
+// Fill is one order execution of the auction.
+type Fill struct {
+	OrderHash     common.Hash
+	SubaccountID  common.Hash
+	IsBuy         bool
+	ExecutionType exchangetypes.ExecutionType
+	Quantity      sdk.Dec
+	Price         sdk.Dec
+	Fee           sdk.Dec
+	// PositionDelta and Payout are set for derivative fills only.
+	PositionDelta *exchangetypes.PositionDelta
+	Payout        sdk.Dec
+}
+
+// Cancellation is an order, or its remaining quantity, removed by the auction. Unfilled quantities
+// of market orders are canceled, as are new derivative orders the chain would reject at the mark price.
+type Cancellation struct {
+	OrderHash    common.Hash
+	SubaccountID common.Hash
+	IsBuy        bool
+	IsLimit      bool
+	Quantity     sdk.Dec
+	Reason       string
+}
+
+// bookOrder is a limit order in the auction. Transient orders are the ones posted in the block,
+// idx is the index of the order in the resting or transient orders it comes from.
+type bookOrder struct {
+	price     sdk.Dec
+	fillable  sdk.Dec
+	filled    sdk.Dec
+	transient bool
+	idx       int
+}
+
+func newBookOrder(price, fillable sdk.Dec, transient bool, idx int) *bookOrder {
+	return &bookOrder{
+		price:     price,
+		fillable:  fillable,
+		filled:    sdk.ZeroDec(),
+		transient: transient,
+		idx:       idx,
+	}
+}
+
+func (o *bookOrder) remaining() sdk.Dec {
+	return o.fillable.Sub(o.filled)
+}
+
+// bookSide holds orders of one side in price priority. Resting orders go before transient ones
+// at the same price, and orders keep their given order otherwise.
+type bookSide struct {
+	orders []*bookOrder
+	next   int
+}
+
+func newBookSide(isBuy bool, resting, transient []*bookOrder) *bookSide {
+	orders := make([]*bookOrder, 0, len(resting)+len(transient))
+	orders = append(orders, resting...)
+	orders = append(orders, transient...)
+
+	sort.SliceStable(orders, func(i, j int) bool {
+		if isBuy {
+			return orders[i].price.GT(orders[j].price)
+		}
+
+		return orders[i].price.LT(orders[j].price)
+	})
+
+	return &bookSide{
+		orders: orders,
+	}
+}
+
+// peek returns the best order with a remaining quantity, or nil if the side is drained.
+func (s *bookSide) peek() *bookOrder {
+	for ; s.next < len(s.orders); s.next++ {
+		if o := s.orders[s.next]; o.remaining().IsPositive() {
+			return o
+		}
+	}
+
+	return nil
+}
+
+// crosses tells whether an order at price can be matched with an opposite order at oppositePrice.
+func crosses(isBuy bool, price, oppositePrice sdk.Dec) bool {
+	if isBuy {
+		return price.GTE(oppositePrice)
+	}
+
+	return price.LTE(oppositePrice)
+}
+
+// marketOrder is a market order in the auction, filled up to its worst price.
+type marketOrder struct {
+	price    sdk.Dec
+	quantity sdk.Dec
+	filled   sdk.Dec
+}
+
+func newMarketOrder(price, quantity sdk.Dec) *marketOrder {
+	return &marketOrder{
+		price:    price,
+		quantity: quantity,
+		filled:   sdk.ZeroDec(),
+	}
+}
+
+// matchMarketOrders fills market orders of one side against the resting orders of the opposite side,
+// market orders with the best worst price first. Resting orders are filled at their price, market orders
+// at the average price of all fills. The clearing price is nil if nothing has been filled.
+func matchMarketOrders(isBuy bool, orders []*marketOrder, resting *bookSide) (clearingPrice, clearingQuantity sdk.Dec) {
+	sorted := append([]*marketOrder(nil), orders...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if isBuy {
+			return sorted[i].price.GT(sorted[j].price)
+		}
+
+		return sorted[i].price.LT(sorted[j].price)
+	})
+
+	notional, quantity := sdk.ZeroDec(), sdk.ZeroDec()
+
+	for _, o := range sorted {
+		for o.filled.LT(o.quantity) {
+			r := resting.peek()
+			if r == nil || !crosses(isBuy, o.price, r.price) {
+				break
+			}
+
+			fillQuantity := sdk.MinDec(o.quantity.Sub(o.filled), r.remaining())
+			o.filled = o.filled.Add(fillQuantity)
+			r.filled = r.filled.Add(fillQuantity)
+
+			notional = notional.Add(fillQuantity.Mul(r.price))
+			quantity = quantity.Add(fillQuantity)
+		}
+	}
+
+	if quantity.IsZero() {
+		return sdk.Dec{}, quantity
+	}
+
+	return notional.Quo(quantity), quantity
+}
+
+// matchLimitOrders matches the best buys and sells while they cross and returns the uniform clearing
+// price of the matched orders, nil if none matched. When matched orders of only one side were resting,
+// the clearing price is the last matched price of that side, so resting orders never trade worse
+// than their price. Otherwise it is the midpoint of the last matched buy and sell prices.
+func matchLimitOrders(buys, sells *bookSide) (clearingPrice, clearingQuantity sdk.Dec) {
+	var lastBuy, lastSell *bookOrder
+	var restingBuyMatched, restingSellMatched bool
+	quantity := sdk.ZeroDec()
+
+	for {
+		b, s := buys.peek(), sells.peek()
+		if b == nil || s == nil || !crosses(true, b.price, s.price) {
+			break
+		}
+
+		fillQuantity := sdk.MinDec(b.remaining(), s.remaining())
+		b.filled = b.filled.Add(fillQuantity)
+		s.filled = s.filled.Add(fillQuantity)
+		quantity = quantity.Add(fillQuantity)
+
+		lastBuy, lastSell = b, s
+		restingBuyMatched = restingBuyMatched || !b.transient
+		restingSellMatched = restingSellMatched || !s.transient
+	}
+
+	switch {
+	case quantity.IsZero():
+		return sdk.Dec{}, quantity
+	case restingBuyMatched && !restingSellMatched:
+		return lastBuy.price, quantity
+	case restingSellMatched && !restingBuyMatched:
+		return lastSell.price, quantity
+	default:
+		return lastBuy.price.Add(lastSell.price).QuoInt64(2), quantity
+	}
+}
+
+func mergeDepositDeltas(dst, src exchangetypes.DepositDeltas) {
+	for subaccountID, delta := range src {
+		dst.ApplyDepositDelta(subaccountID, delta)
+	}
+}
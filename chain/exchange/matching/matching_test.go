@@ -0,0 +1,155 @@
+package matching
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/common"
+
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+)
+
+var (
+	testMaker = common.HexToHash("0x01")
+	testTaker = common.HexToHash("0x02")
+	testOther = common.HexToHash("0x03")
+)
+
+func testOrderInfo(subaccountID common.Hash, price, quantity string) exchangetypes.OrderInfo {
+	return exchangetypes.OrderInfo{
+		SubaccountId: subaccountID.Hex(),
+		Price:        sdk.MustNewDecFromStr(price),
+		Quantity:     sdk.MustNewDecFromStr(quantity),
+	}
+}
+
+func testSpotLimitOrder(hash byte, subaccountID common.Hash, orderType exchangetypes.OrderType, price, quantity string) *exchangetypes.SpotLimitOrder {
+	return &exchangetypes.SpotLimitOrder{
+		OrderInfo: testOrderInfo(subaccountID, price, quantity),
+		OrderType: orderType,
+		Fillable:  sdk.MustNewDecFromStr(quantity),
+		OrderHash: []byte{hash},
+	}
+}
+
+func TestSimulateSpot(t *testing.T) {
+	auction := &SpotAuction{
+		Market: &exchangetypes.SpotMarket{
+			BaseDenom:           "inj",
+			QuoteDenom:          "usdt",
+			MakerFeeRate:        sdk.MustNewDecFromStr("0.001"),
+			TakerFeeRate:        sdk.MustNewDecFromStr("0.002"),
+			RelayerFeeShareRate: sdk.MustNewDecFromStr("0.4"),
+		},
+		RestingBuys: []*exchangetypes.SpotLimitOrder{
+			testSpotLimitOrder(1, testMaker, exchangetypes.OrderType_BUY, "98", "1"),
+		},
+		RestingSells: []*exchangetypes.SpotLimitOrder{
+			testSpotLimitOrder(2, testMaker, exchangetypes.OrderType_SELL, "101", "2"),
+			testSpotLimitOrder(3, testMaker, exchangetypes.OrderType_SELL, "100", "1"),
+		},
+		LimitOrders: []*exchangetypes.SpotLimitOrder{
+			testSpotLimitOrder(4, testOther, exchangetypes.OrderType_SELL, "97", "0.5"),
+		},
+		MarketBuys: []*exchangetypes.SpotMarketOrder{{
+			OrderInfo: testOrderInfo(testTaker, "101", "2.5"),
+			OrderHash: []byte{5},
+		}},
+	}
+
+	result, err := SimulateSpot(auction)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Fills) != 5 {
+		t.Fatalf("expected 5 fills, got %d", len(result.Fills))
+	} else if !result.ClearingPrice.Equal(sdk.NewDec(98)) {
+		t.Fatalf("expected limit orders to clear at the resting buy price, got %s", result.ClearingPrice)
+	}
+
+	// 1 at 100 and 1.5 at 101 at the average price, plus the taker fee
+	if delta := result.BaseDepositDeltas[testTaker]; !delta.TotalBalanceDelta.Equal(sdk.MustNewDecFromStr("2.5")) {
+		t.Fatalf("unexpected taker base delta %+v", delta)
+	} else if delta := result.QuoteDepositDeltas[testTaker]; !delta.TotalBalanceDelta.Equal(sdk.MustNewDecFromStr("-252.003")) {
+		t.Fatalf("unexpected taker quote delta %+v", delta)
+	}
+
+	if len(result.Cancellations) != 0 {
+		t.Fatalf("unexpected cancellations %+v", result.Cancellations)
+	} else if len(result.RestingSells) != 1 || !result.RestingSells[0].Fillable.Equal(sdk.MustNewDecFromStr("0.5")) {
+		t.Fatalf("unexpected resting sells %+v", result.RestingSells)
+	} else if len(result.RestingBuys) != 1 || !result.RestingBuys[0].Fillable.Equal(sdk.MustNewDecFromStr("0.5")) {
+		t.Fatalf("unexpected resting buys %+v", result.RestingBuys)
+	}
+
+	if !auction.RestingSells[1].Fillable.Equal(sdk.NewDec(1)) {
+		t.Fatal("expected the auction to be left untouched")
+	}
+}
+
+func testDerivativeLimitOrder(hash byte, subaccountID common.Hash, orderType exchangetypes.OrderType, price, quantity, margin string) *exchangetypes.DerivativeLimitOrder {
+	return &exchangetypes.DerivativeLimitOrder{
+		OrderInfo: testOrderInfo(subaccountID, price, quantity),
+		OrderType: orderType,
+		Margin:    sdk.MustNewDecFromStr(margin),
+		Fillable:  sdk.MustNewDecFromStr(quantity),
+		OrderHash: []byte{hash},
+	}
+}
+
+func TestSimulateDerivative(t *testing.T) {
+	auction := &DerivativeAuction{
+		Market: &exchangetypes.DerivativeMarket{
+			InitialMarginRatio:     sdk.MustNewDecFromStr("0.05"),
+			MaintenanceMarginRatio: sdk.MustNewDecFromStr("0.02"),
+			MakerFeeRate:           sdk.MustNewDecFromStr("0.001"),
+			TakerFeeRate:           sdk.MustNewDecFromStr("0.002"),
+			RelayerFeeShareRate:    sdk.MustNewDecFromStr("0.4"),
+			IsPerpetual:            true,
+		},
+		MarkPrice: sdk.NewDec(100),
+		Funding: &exchangetypes.PerpetualMarketFunding{
+			CumulativeFunding: sdk.ZeroDec(),
+			CumulativePrice:   sdk.ZeroDec(),
+		},
+		RestingSells: []*exchangetypes.DerivativeLimitOrder{
+			testDerivativeLimitOrder(1, testMaker, exchangetypes.OrderType_SELL, "101", "1", "10"),
+		},
+		LimitOrders: []*exchangetypes.DerivativeLimitOrder{
+			testDerivativeLimitOrder(2, testTaker, exchangetypes.OrderType_BUY, "102", "1", "10"),
+			// undermargined at the mark price
+			testDerivativeLimitOrder(3, testOther, exchangetypes.OrderType_BUY, "150", "1", "1"),
+			// reduce-only without a position
+			testDerivativeLimitOrder(4, testOther, exchangetypes.OrderType_SELL, "90", "1", "0"),
+		},
+	}
+
+	result, err := SimulateDerivative(auction)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Cancellations) != 2 {
+		t.Fatalf("expected 2 cancellations, got %+v", result.Cancellations)
+	} else if len(result.Fills) != 2 || !result.ClearingPrice.Equal(sdk.NewDec(101)) {
+		t.Fatalf("expected 2 fills at the resting sell price, got %d at %s", len(result.Fills), result.ClearingPrice)
+	}
+
+	long, short := result.Positions[testTaker], result.Positions[testMaker]
+	if long == nil || !long.IsLong || !long.Quantity.Equal(sdk.OneDec()) || !long.EntryPrice.Equal(sdk.NewDec(101)) {
+		t.Fatalf("unexpected long position %+v", long)
+	} else if short == nil || short.IsLong || !short.Quantity.Equal(sdk.OneDec()) || !short.Margin.Equal(sdk.NewDec(10)) {
+		t.Fatalf("unexpected short position %+v", short)
+	}
+
+	// margin and taker fee at the clearing price are taken, the fee held at the order price is released
+	if delta := result.DepositDeltas[testTaker]; !delta.TotalBalanceDelta.Equal(sdk.MustNewDecFromStr("-10.202")) ||
+		!delta.AvailableBalanceDelta.Equal(sdk.MustNewDecFromStr("0.002")) {
+		t.Fatalf("unexpected taker deposit delta %+v", delta)
+	}
+
+	if len(result.RestingBuys) != 0 || len(result.RestingSells) != 0 {
+		t.Fatalf("expected an empty book, got %d buys and %d sells", len(result.RestingBuys), len(result.RestingSells))
+	}
+}
@@ -0,0 +1,334 @@
+package matching
+
+import (
+	"sort"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+)
+
+// SpotAuction is the state of a spot market at the end of a block, in chain units.
+// Orders are copied by the simulation, the auction is left untouched.
+type SpotAuction struct {
+	Market *exchangetypes.SpotMarket
+
+	// RestingBuys and RestingSells are the orderbook before the block, in time priority.
+	RestingBuys  []*exchangetypes.SpotLimitOrder
+	RestingSells []*exchangetypes.SpotLimitOrder
+
+	// LimitOrders, MarketBuys and MarketSells are the orders posted in the block. Market orders
+	// without a balance hold get the one the chain holds at their worst price.
+	LimitOrders []*exchangetypes.SpotLimitOrder
+	MarketBuys  []*exchangetypes.SpotMarketOrder
+	MarketSells []*exchangetypes.SpotMarketOrder
+}
+
+// SpotResult is the outcome of a spot auction.
+type SpotResult struct {
+	// MarketBuyExecution, MarketSellExecution and LimitExecution are the batches the chain applies,
+	// nil if there were no such orders.
+	MarketBuyExecution  *exchangetypes.SpotBatchExecutionData
+	MarketSellExecution *exchangetypes.SpotBatchExecutionData
+	LimitExecution      *exchangetypes.SpotBatchExecutionData
+
+	// ClearingPrice of the limit orders matching, nil if no limit orders matched.
+	ClearingPrice sdk.Dec
+
+	Fills         []*Fill
+	Cancellations []*Cancellation
+
+	// BaseDepositDeltas and QuoteDepositDeltas sum the deposit changes of all batches.
+	BaseDepositDeltas  exchangetypes.DepositDeltas
+	QuoteDepositDeltas exchangetypes.DepositDeltas
+
+	// RestingBuys and RestingSells are the orderbook after the block, in price priority.
+	RestingBuys  []*exchangetypes.SpotLimitOrder
+	RestingSells []*exchangetypes.SpotLimitOrder
+}
+
+type spotSimulation struct {
+	market *exchangetypes.SpotMarket
+	result *SpotResult
+
+	restingBuys  []*exchangetypes.SpotLimitOrder
+	restingSells []*exchangetypes.SpotLimitOrder
+}
+
+// SimulateSpot runs one end-block batch auction of the spot market.
+func SimulateSpot(auction *SpotAuction) (*SpotResult, error) {
+	if auction.Market == nil {
+		err := errors.New("market is nil")
+		return nil, err
+	}
+
+	s := &spotSimulation{
+		market: auction.Market,
+		result: &SpotResult{
+			BaseDepositDeltas:  exchangetypes.NewDepositDeltas(),
+			QuoteDepositDeltas: exchangetypes.NewDepositDeltas(),
+		},
+	}
+
+	var err error
+	if s.restingBuys, err = copySpotLimitOrders(auction.RestingBuys, true); err != nil {
+		err = errors.Wrap(err, "invalid resting buys")
+		return nil, err
+	} else if s.restingSells, err = copySpotLimitOrders(auction.RestingSells, false); err != nil {
+		err = errors.Wrap(err, "invalid resting sells")
+		return nil, err
+	}
+
+	marketBuys, err := copySpotMarketOrders(auction.MarketBuys, true, auction.Market)
+	if err != nil {
+		err = errors.Wrap(err, "invalid market buys")
+		return nil, err
+	}
+
+	marketSells, err := copySpotMarketOrders(auction.MarketSells, false, auction.Market)
+	if err != nil {
+		err = errors.Wrap(err, "invalid market sells")
+		return nil, err
+	}
+
+	var limitBuys, limitSells []*exchangetypes.SpotLimitOrder
+	for _, o := range auction.LimitOrders {
+		if o.IsBuy() {
+			limitBuys = append(limitBuys, o)
+		} else {
+			limitSells = append(limitSells, o)
+		}
+	}
+
+	transientBuys, err := copySpotLimitOrders(limitBuys, true)
+	if err != nil {
+		err = errors.Wrap(err, "invalid limit buys")
+		return nil, err
+	}
+
+	transientSells, err := copySpotLimitOrders(limitSells, false)
+	if err != nil {
+		err = errors.Wrap(err, "invalid limit sells")
+		return nil, err
+	}
+
+	if len(marketBuys) > 0 {
+		s.result.MarketBuyExecution = s.executeMarketOrders(true, marketBuys)
+	}
+
+	if len(marketSells) > 0 {
+		s.result.MarketSellExecution = s.executeMarketOrders(false, marketSells)
+	}
+
+	if len(transientBuys) > 0 || len(transientSells) > 0 {
+		s.result.LimitExecution = s.executeLimitOrders(transientBuys, transientSells)
+	}
+
+	s.result.RestingBuys = sortedSpotLimitOrders(s.restingBuys, true)
+	s.result.RestingSells = sortedSpotLimitOrders(s.restingSells, false)
+
+	return s.result, nil
+}
+
+func (s *spotSimulation) executeMarketOrders(isBuy bool, orders []*exchangetypes.SpotMarketOrder) *exchangetypes.SpotBatchExecutionData {
+	resting := s.restingSells
+	if !isBuy {
+		resting = s.restingBuys
+	}
+
+	marketOrders := make([]*marketOrder, 0, len(orders))
+	for _, o := range orders {
+		marketOrders = append(marketOrders, newMarketOrder(o.OrderInfo.Price, o.OrderInfo.Quantity))
+	}
+
+	restingSide := newBookSide(!isBuy, spotBookOrders(resting, false), nil)
+	clearingPrice, _ := matchMarketOrders(isBuy, marketOrders, restingSide)
+
+	fillQuantities := make([]sdk.Dec, 0, len(marketOrders))
+	for idx, o := range marketOrders {
+		fillQuantities = append(fillQuantities, o.filled)
+
+		if unfilled := o.quantity.Sub(o.filled); unfilled.IsPositive() {
+			s.result.Cancellations = append(s.result.Cancellations, &Cancellation{
+				OrderHash:    common.BytesToHash(orders[idx].OrderHash),
+				SubaccountID: orders[idx].SubaccountID(),
+				IsBuy:        isBuy,
+				Quantity:     unfilled,
+				Reason:       "market order not filled",
+			})
+		}
+	}
+
+	marketExpansions := exchangetypes.ProcessSpotMarketOrderStateExpansions(
+		isBuy, orders, fillQuantities, clearingPrice,
+		s.market.TakerFeeRate, s.market.RelayerFeeShareRate,
+	)
+	limitExpansions := exchangetypes.ProcessRestingSpotLimitOrderExpansions(
+		restingSpotFills(resting, restingSide), !isBuy, sdk.Dec{},
+		s.market.MakerFeeRate, s.market.RelayerFeeShareRate,
+	)
+
+	batch := exchangetypes.GetSpotMarketOrderBatchExecution(isBuy, s.market, limitExpansions, marketExpansions, clearingPrice)
+	s.applyBatch(batch, append([]*exchangetypes.EventBatchSpotExecution{batch.MarketOrderExecutionEvent}, batch.LimitOrderExecutionEvent...)...)
+
+	// fill quantities have been deducted from the resting orders by the expansions
+	if isBuy {
+		s.restingSells = fillableSpotLimitOrders(s.restingSells)
+	} else {
+		s.restingBuys = fillableSpotLimitOrders(s.restingBuys)
+	}
+
+	return batch
+}
+
+func (s *spotSimulation) executeLimitOrders(transientBuys, transientSells []*exchangetypes.SpotLimitOrder) *exchangetypes.SpotBatchExecutionData {
+	buys := newBookSide(true, spotBookOrders(s.restingBuys, false), spotBookOrders(transientBuys, true))
+	sells := newBookSide(false, spotBookOrders(s.restingSells, false), spotBookOrders(transientSells, true))
+	clearingPrice, _ := matchLimitOrders(buys, sells)
+
+	stateChange := exchangetypes.NewSpotOrderbookStateChange(transientBuys, transientSells)
+	stateChange.ClearingPrice = clearingPrice
+	stateChange.RestingBuyOrderbookFills = restingSpotFills(s.restingBuys, buys)
+	stateChange.RestingSellOrderbookFills = restingSpotFills(s.restingSells, sells)
+	transientSpotFills(stateChange.TransientBuyOrderbookFills, buys)
+	transientSpotFills(stateChange.TransientSellOrderbookFills, sells)
+
+	batch := exchangetypes.GetSpotLimitMatchingBatchExecution(s.market, stateChange, clearingPrice)
+	s.applyBatch(batch, batch.LimitOrderExecutionEvent...)
+	s.result.ClearingPrice = clearingPrice
+
+	s.restingBuys = fillableSpotLimitOrders(s.restingBuys)
+	s.restingSells = fillableSpotLimitOrders(s.restingSells)
+	if batch.NewOrdersEvent != nil {
+		s.restingBuys = append(s.restingBuys, batch.NewOrdersEvent.BuyOrders...)
+		s.restingSells = append(s.restingSells, batch.NewOrdersEvent.SellOrders...)
+	}
+
+	return batch
+}
+
+func (s *spotSimulation) applyBatch(batch *exchangetypes.SpotBatchExecutionData, events ...*exchangetypes.EventBatchSpotExecution) {
+	mergeDepositDeltas(s.result.BaseDepositDeltas, batch.BaseDenomDepositDeltas)
+	mergeDepositDeltas(s.result.QuoteDepositDeltas, batch.QuoteDenomDepositDeltas)
+
+	for _, ev := range events {
+		if ev == nil {
+			continue
+		}
+
+		for _, trade := range ev.Trades {
+			s.result.Fills = append(s.result.Fills, &Fill{
+				OrderHash:     common.BytesToHash(trade.OrderHash),
+				SubaccountID:  common.BytesToHash(trade.SubaccountId),
+				IsBuy:         ev.IsBuy,
+				ExecutionType: ev.ExecutionType,
+				Quantity:      trade.Quantity,
+				Price:         trade.Price,
+				Fee:           trade.Fee,
+			})
+		}
+	}
+}
+
+func copySpotLimitOrders(orders []*exchangetypes.SpotLimitOrder, isBuy bool) ([]*exchangetypes.SpotLimitOrder, error) {
+	copied := make([]*exchangetypes.SpotLimitOrder, 0, len(orders))
+	for _, o := range orders {
+		hash := common.BytesToHash(o.OrderHash).Hex()
+		if o.IsBuy() != isBuy {
+			err := errors.Errorf("order %s is on the wrong side", hash)
+			return nil, err
+		} else if o.OrderInfo.Price.IsNil() || o.OrderInfo.Quantity.IsNil() || o.Fillable.IsNil() {
+			err := errors.Errorf("order %s has no price, quantity or fillable quantity", hash)
+			return nil, err
+		}
+
+		order := *o
+		copied = append(copied, &order)
+	}
+
+	return copied, nil
+}
+
+func copySpotMarketOrders(orders []*exchangetypes.SpotMarketOrder, isBuy bool, market *exchangetypes.SpotMarket) ([]*exchangetypes.SpotMarketOrder, error) {
+	copied := make([]*exchangetypes.SpotMarketOrder, 0, len(orders))
+	for _, o := range orders {
+		if o.OrderInfo.Price.IsNil() || o.OrderInfo.Quantity.IsNil() {
+			err := errors.Errorf("order %s has no price or quantity", common.BytesToHash(o.OrderHash).Hex())
+			return nil, err
+		}
+
+		order := *o
+		if order.BalanceHold.IsNil() {
+			if isBuy {
+				order.BalanceHold = order.OrderInfo.GetNotional().Add(order.OrderInfo.GetFeeAmount(market.TakerFeeRate))
+			} else {
+				order.BalanceHold = order.OrderInfo.Quantity
+			}
+		}
+
+		copied = append(copied, &order)
+	}
+
+	return copied, nil
+}
+
+func spotBookOrders(orders []*exchangetypes.SpotLimitOrder, transient bool) []*bookOrder {
+	bookOrders := make([]*bookOrder, 0, len(orders))
+	for idx, o := range orders {
+		bookOrders = append(bookOrders, newBookOrder(o.OrderInfo.Price, o.Fillable, transient, idx))
+	}
+
+	return bookOrders
+}
+
+// restingSpotFills collects fills of resting orders of the side, nil if none was filled.
+func restingSpotFills(resting []*exchangetypes.SpotLimitOrder, side *bookSide) *exchangetypes.OrderbookFills {
+	var fills *exchangetypes.OrderbookFills
+	for _, o := range side.orders {
+		if o.transient || !o.filled.IsPositive() {
+			continue
+		}
+
+		if fills == nil {
+			fills = new(exchangetypes.OrderbookFills)
+		}
+
+		fills.Orders = append(fills.Orders, resting[o.idx])
+		fills.FillQuantities = append(fills.FillQuantities, o.filled)
+	}
+
+	return fills
+}
+
+func transientSpotFills(fills *exchangetypes.OrderbookFills, side *bookSide) {
+	for _, o := range side.orders {
+		if o.transient {
+			fills.FillQuantities[o.idx] = o.filled
+		}
+	}
+}
+
+func fillableSpotLimitOrders(orders []*exchangetypes.SpotLimitOrder) []*exchangetypes.SpotLimitOrder {
+	fillable := orders[:0]
+	for _, o := range orders {
+		if o.Fillable.IsPositive() {
+			fillable = append(fillable, o)
+		}
+	}
+
+	return fillable
+}
+
+func sortedSpotLimitOrders(orders []*exchangetypes.SpotLimitOrder, isBuy bool) []*exchangetypes.SpotLimitOrder {
+	sort.SliceStable(orders, func(i, j int) bool {
+		if isBuy {
+			return orders[i].OrderInfo.Price.GT(orders[j].OrderInfo.Price)
+		}
+
+		return orders[i].OrderInfo.Price.LT(orders[j].OrderInfo.Price)
+	})
+
+	return orders
+}
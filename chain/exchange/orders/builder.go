@@ -0,0 +1,197 @@
+// Package orders builds exchange orders and their messages from human prices and quantities,
+// converting them to chain units and rounding them to the tick sizes of the market.
+package orders
+
+import (
+	"math/big"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+)
+
+// RoundingMode decides how values off the tick size grid are put on it.
+type RoundingMode int
+
+const (
+	// RoundDown rounds towards zero.
+	RoundDown RoundingMode = iota
+	// RoundUp rounds away from zero.
+	RoundUp
+	// RoundNearest rounds to the nearest tick, halfway values are rounded up.
+	RoundNearest
+	// RoundPassive rounds prices away from the opposite side of the book, that is down
+	// for buys and up for sells, so the order never crosses further than asked.
+	// Quantities are rounded down.
+	RoundPassive
+)
+
+var (
+	ErrZeroAfterRounding = errors.New("value is zero after rounding to tick size")
+	ErrNotPositive       = errors.New("value must be positive")
+)
+
+type builderOptions struct {
+	SubaccountNonce  uint32
+	FeeRecipient     string
+	PriceRounding    RoundingMode
+	QuantityRounding RoundingMode
+}
+
+func defaultBuilderOptions() *builderOptions {
+	return &builderOptions{
+		PriceRounding:    RoundPassive,
+		QuantityRounding: RoundDown,
+	}
+}
+
+// quantityRounding returns the mode quantities are rounded with, RoundPassive rounds them down.
+func (o *builderOptions) quantityRounding() RoundingMode {
+	if o.QuantityRounding == RoundPassive {
+		return RoundDown
+	}
+
+	return o.QuantityRounding
+}
+
+type builderOption func(opts *builderOptions) error
+
+// OptionSubaccountNonce sets the nonce of the trading subaccount of the sender, 0 by default.
+func OptionSubaccountNonce(nonce uint32) builderOption {
+	return func(opts *builderOptions) error {
+		opts.SubaccountNonce = nonce
+		return nil
+	}
+}
+
+// OptionFeeRecipient sets the bech32 address receiving the relayer fee share, the sender by default.
+func OptionFeeRecipient(feeRecipient string) builderOption {
+	return func(opts *builderOptions) error {
+		if _, err := sdk.AccAddressFromBech32(feeRecipient); err != nil {
+			err = errors.Wrapf(err, "invalid fee recipient %s", feeRecipient)
+			return err
+		}
+
+		opts.FeeRecipient = feeRecipient
+		return nil
+	}
+}
+
+// OptionRounding sets how prices and quantities are rounded to the tick sizes.
+// By default prices are rounded passively and quantities down.
+func OptionRounding(price, quantity RoundingMode) builderOption {
+	return func(opts *builderOptions) error {
+		opts.PriceRounding = price
+		opts.QuantityRounding = quantity
+		return nil
+	}
+}
+
+// orderOwner is the subaccount and fee recipient shared by all orders of a builder.
+type orderOwner struct {
+	sender       sdk.AccAddress
+	subaccountID string
+	feeRecipient string
+}
+
+func newOrderOwner(sender sdk.AccAddress, opts *builderOptions) (*orderOwner, error) {
+	if sender.Empty() {
+		err := errors.New("sender address is empty")
+		return nil, err
+	}
+
+	subaccountID, err := exchangetypes.SdkAddressWithNonceToSubaccountID(sender, opts.SubaccountNonce)
+	if err != nil {
+		err = errors.Wrap(err, "failed to derive subaccount ID")
+		return nil, err
+	}
+
+	owner := &orderOwner{
+		sender:       sender,
+		subaccountID: subaccountID.Hex(),
+		feeRecipient: opts.FeeRecipient,
+	}
+
+	if len(owner.feeRecipient) == 0 {
+		owner.feeRecipient = sender.String()
+	}
+
+	return owner, nil
+}
+
+func (o *orderOwner) orderInfo(price, quantity sdk.Dec) exchangetypes.OrderInfo {
+	return exchangetypes.OrderInfo{
+		SubaccountId: o.subaccountID,
+		FeeRecipient: o.feeRecipient,
+		Price:        price,
+		Quantity:     quantity,
+	}
+}
+
+// ScaleDecimals multiplies value by 10^decimals, decimals may be negative.
+func ScaleDecimals(value sdk.Dec, decimals int) sdk.Dec {
+	if decimals == 0 {
+		return value
+	}
+
+	abs := decimals
+	if abs < 0 {
+		abs = -abs
+	}
+
+	factor := sdk.NewDecFromInt(sdk.NewIntWithDecimal(1, abs))
+	if decimals < 0 {
+		return value.Quo(factor)
+	}
+
+	return value.Mul(factor)
+}
+
+// RoundToTick puts a positive value on the grid of tick size multiples. For RoundPassive,
+// isBuy tells which side the value belongs to.
+func RoundToTick(value, tick sdk.Dec, mode RoundingMode, isBuy bool) sdk.Dec {
+	if tick.IsNil() || !tick.IsPositive() {
+		return value
+	}
+
+	if mode == RoundPassive {
+		if isBuy {
+			mode = RoundDown
+		} else {
+			mode = RoundUp
+		}
+	}
+
+	tickInt := tick.BigInt()
+	quo, rem := new(big.Int).QuoRem(value.BigInt(), tickInt, new(big.Int))
+
+	switch mode {
+	case RoundUp:
+		if rem.Sign() > 0 {
+			quo.Add(quo, big.NewInt(1))
+		}
+	case RoundNearest:
+		if new(big.Int).Lsh(rem, 1).Cmp(tickInt) >= 0 {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+
+	return sdk.NewDecFromBigIntWithPrec(quo.Mul(quo, tickInt), sdk.Precision)
+}
+
+// toTicks converts a human value to chain units and rounds it to the tick size.
+func toTicks(name string, value sdk.Dec, decimals int, tick sdk.Dec, mode RoundingMode, isBuy bool) (sdk.Dec, error) {
+	if value.IsNil() || !value.IsPositive() {
+		err := errors.Wrapf(ErrNotPositive, "%s %s", name, value)
+		return sdk.Dec{}, err
+	}
+
+	chainValue := RoundToTick(ScaleDecimals(value, decimals), tick, mode, isBuy)
+	if !chainValue.IsPositive() {
+		err := errors.Wrapf(ErrZeroAfterRounding, "%s %s with tick size %s", name, value, tick)
+		return sdk.Dec{}, err
+	}
+
+	return chainValue, nil
+}
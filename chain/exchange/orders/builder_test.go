@@ -0,0 +1,138 @@
+package orders
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+)
+
+func TestRoundToTick(t *testing.T) {
+	tick := sdk.MustNewDecFromStr("0.5")
+	value := sdk.MustNewDecFromStr("1.25")
+
+	for _, tc := range []struct {
+		mode     RoundingMode
+		isBuy    bool
+		expected string
+	}{
+		{RoundDown, false, "1.0"},
+		{RoundUp, false, "1.5"},
+		{RoundNearest, false, "1.5"},
+		{RoundPassive, true, "1.0"},
+		{RoundPassive, false, "1.5"},
+	} {
+		if rounded := RoundToTick(value, tick, tc.mode, tc.isBuy); !rounded.Equal(sdk.MustNewDecFromStr(tc.expected)) {
+			t.Fatalf("mode %d: expected %s, got %s", tc.mode, tc.expected, rounded)
+		}
+	}
+}
+
+func TestSpotOrderBuilder(t *testing.T) {
+	sender := sdk.AccAddress("test_sender_________")
+	market := &exchangetypes.SpotMarket{
+		Ticker:              "INJ/USDT",
+		MarketId:            "0xa508cb32923323679f29a032c70342c147c17d0145625922b0ef22e955c844c0",
+		MinPriceTickSize:    sdk.MustNewDecFromStr("0.000000000000001"),
+		MinQuantityTickSize: sdk.MustNewDecFromStr("1000000000000000"),
+	}
+
+	b, err := NewSpotOrderBuilder(market, 18, 6, sender)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order, err := b.Order(exchangetypes.OrderType_BUY, sdk.MustNewDecFromStr("12.3456"), sdk.MustNewDecFromStr("1.23456"))
+	if err != nil {
+		t.Fatal(err)
+	} else if !order.OrderInfo.Price.Equal(sdk.MustNewDecFromStr("0.000000000012345")) {
+		t.Fatalf("unexpected chain price %s", order.OrderInfo.Price)
+	} else if !order.OrderInfo.Quantity.Equal(sdk.MustNewDecFromStr("1234000000000000000")) {
+		t.Fatalf("unexpected chain quantity %s", order.OrderInfo.Quantity)
+	} else if !b.HumanPrice(order.OrderInfo.Price).Equal(sdk.MustNewDecFromStr("12.345")) {
+		t.Fatalf("unexpected human price %s", b.HumanPrice(order.OrderInfo.Price))
+	}
+
+	if err := b.BatchCreateLimitOrdersMsg(order, order).ValidateBasic(); err != nil {
+		t.Fatal(err)
+	}
+
+	passive, err := NewSpotOrderBuilder(market, 18, 6, sender, OptionRounding(RoundPassive, RoundPassive))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// passive quantities are rounded down for both sides
+	for _, orderType := range []exchangetypes.OrderType{exchangetypes.OrderType_BUY, exchangetypes.OrderType_SELL} {
+		order, err := passive.Order(orderType, sdk.MustNewDecFromStr("12.3456"), sdk.MustNewDecFromStr("1.23456"))
+		if err != nil {
+			t.Fatal(err)
+		} else if !order.OrderInfo.Quantity.Equal(sdk.MustNewDecFromStr("1234000000000000000")) {
+			t.Fatalf("unexpected passive %s quantity %s", orderType, order.OrderInfo.Quantity)
+		}
+	}
+
+	if quantity, err := passive.ChainQuantity(sdk.MustNewDecFromStr("1.23456")); err != nil || !quantity.Equal(sdk.MustNewDecFromStr("1234000000000000000")) {
+		t.Fatalf("unexpected passive chain quantity %s, %v", quantity, err)
+	}
+
+	if _, err := b.Order(exchangetypes.OrderType_SELL, sdk.OneDec(), sdk.MustNewDecFromStr("0.0001")); !errors.Is(err, ErrZeroAfterRounding) {
+		t.Fatalf("expected quantity below tick size to fail, got %v", err)
+	}
+}
+
+func TestDerivativeOrderBuilder(t *testing.T) {
+	sender := sdk.AccAddress("test_sender_________")
+	market := &exchangetypes.DerivativeMarket{
+		Ticker:                 "BTC/USDT PERP",
+		MarketId:               "0x4ca0f92fc28be0c9761326016b5a1a2177dd6375558365116b5bdda9abc229ce",
+		InitialMarginRatio:     sdk.MustNewDecFromStr("0.05"),
+		MaintenanceMarginRatio: sdk.MustNewDecFromStr("0.02"),
+		MakerFeeRate:           sdk.MustNewDecFromStr("0.0005"),
+		TakerFeeRate:           sdk.MustNewDecFromStr("0.001"),
+		IsPerpetual:            true,
+		MinPriceTickSize:       sdk.MustNewDecFromStr("1000"),
+		MinQuantityTickSize:    sdk.MustNewDecFromStr("0.0001"),
+	}
+
+	b, err := NewDerivativeOrderBuilder(market, 6, sender, OptionSubaccountNonce(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	markPrice := sdk.NewDec(40000)
+	order, marginHold, err := b.Order(exchangetypes.OrderType_BUY, sdk.MustNewDecFromStr("40000.12345"), sdk.MustNewDecFromStr("0.5"), sdk.NewDec(10), markPrice)
+	if err != nil {
+		t.Fatal(err)
+	} else if !order.OrderInfo.Price.Equal(sdk.NewDec(40000123000)) {
+		t.Fatalf("unexpected chain price %s", order.OrderInfo.Price)
+	} else if !order.Margin.Equal(sdk.NewDec(2000006150)) {
+		t.Fatalf("unexpected margin %s", order.Margin)
+	} else if !marginHold.Equal(sdk.MustNewDecFromStr("2020006211.5")) {
+		t.Fatalf("unexpected margin hold %s", marginHold)
+	}
+
+	if err := b.CreateLimitOrderMsg(order).ValidateBasic(); err != nil {
+		t.Fatal(err)
+	}
+
+	passive, err := NewDerivativeOrderBuilder(market, 6, sender, OptionRounding(RoundPassive, RoundPassive))
+	if err != nil {
+		t.Fatal(err)
+	} else if quantity, err := passive.ChainQuantity(sdk.MustNewDecFromStr("0.12345")); err != nil || !quantity.Equal(sdk.MustNewDecFromStr("0.1234")) {
+		t.Fatalf("unexpected passive chain quantity %s, %v", quantity, err)
+	}
+
+	if _, _, err := b.Order(exchangetypes.OrderType_BUY, markPrice, sdk.OneDec(), sdk.NewDec(25), markPrice); err == nil {
+		t.Fatal("expected leverage above max leverage to fail")
+	}
+
+	reduceOnly, err := b.ReduceOnlyOrder(exchangetypes.OrderType_SELL, markPrice, sdk.OneDec())
+	if err != nil {
+		t.Fatal(err)
+	} else if !reduceOnly.IsReduceOnly() {
+		t.Fatal("expected reduce-only order")
+	}
+}
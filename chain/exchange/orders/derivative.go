@@ -0,0 +1,199 @@
+package orders
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+)
+
+// DerivativeOrderBuilder builds orders of one derivative market for one subaccount. Prices and margins
+// are given in quote tokens, e.g. 40000.5 USDT per contract, and quantities in contracts.
+type DerivativeOrderBuilder struct {
+	market *exchangetypes.DerivativeMarket
+	owner  *orderOwner
+	opts   *builderOptions
+
+	quoteDecimals int
+}
+
+// NewDerivativeOrderBuilder creates a builder for the market, with decimals of its quote denom,
+// e.g. 6 for USDT. Orders are placed from the default subaccount of the sender,
+// unless OptionSubaccountNonce is set.
+func NewDerivativeOrderBuilder(
+	market *exchangetypes.DerivativeMarket,
+	quoteDecimals uint32,
+	sender sdk.AccAddress,
+	options ...builderOption,
+) (*DerivativeOrderBuilder, error) {
+	if market == nil {
+		err := errors.New("market is nil")
+		return nil, err
+	}
+
+	opts := defaultBuilderOptions()
+	for _, opt := range options {
+		if err := opt(opts); err != nil {
+			err = errors.Wrap(err, "error in an order builder option")
+			return nil, err
+		}
+	}
+
+	owner, err := newOrderOwner(sender, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DerivativeOrderBuilder{
+		market:        market,
+		owner:         owner,
+		opts:          opts,
+		quoteDecimals: int(quoteDecimals),
+	}, nil
+}
+
+// SubaccountID returns the subaccount the orders are placed from.
+func (b *DerivativeOrderBuilder) SubaccountID() string {
+	return b.owner.subaccountID
+}
+
+// ChainPrice converts a human price to chain units, rounded to the price tick size.
+func (b *DerivativeOrderBuilder) ChainPrice(price sdk.Dec, isBuy bool) (sdk.Dec, error) {
+	return toTicks("price", price, b.quoteDecimals, b.market.MinPriceTickSize, b.opts.PriceRounding, isBuy)
+}
+
+// ChainQuantity rounds a quantity to the quantity tick size, quantities have no decimals to apply.
+func (b *DerivativeOrderBuilder) ChainQuantity(quantity sdk.Dec) (sdk.Dec, error) {
+	return toTicks("quantity", quantity, 0, b.market.MinQuantityTickSize, b.opts.quantityRounding(), false)
+}
+
+// HumanPrice converts a chain price or any quote amount of the market, e.g. margin, back to quote tokens.
+func (b *DerivativeOrderBuilder) HumanPrice(price sdk.Dec) sdk.Dec {
+	return ScaleDecimals(price, -b.quoteDecimals)
+}
+
+// MaxLeverage returns the leverage allowed by the initial margin ratio of the market.
+func (b *DerivativeOrderBuilder) MaxLeverage() sdk.Dec {
+	if !b.market.InitialMarginRatio.IsPositive() {
+		return sdk.ZeroDec()
+	}
+
+	return sdk.OneDec().Quo(b.market.InitialMarginRatio)
+}
+
+// Order builds an order of human price and quantity, with margin of notional divided by leverage.
+// The order is checked against tick sizes and margin requirements of the market at the human markPrice,
+// the returned margin hold is what the chain locks from the subaccount deposit, in chain units,
+// including the taker fee, since a limit order may be matched as taker.
+func (b *DerivativeOrderBuilder) Order(
+	orderType exchangetypes.OrderType,
+	price, quantity, leverage, markPrice sdk.Dec,
+) (order *exchangetypes.DerivativeOrder, marginHold sdk.Dec, err error) {
+	if leverage.IsNil() || !leverage.IsPositive() {
+		err = errors.Wrapf(ErrNotPositive, "leverage %s", leverage)
+		return nil, sdk.Dec{}, err
+	} else if maxLeverage := b.MaxLeverage(); maxLeverage.IsPositive() && leverage.GT(maxLeverage) {
+		err = errors.Errorf("leverage %s exceeds max leverage %s of the market", leverage, maxLeverage)
+		return nil, sdk.Dec{}, err
+	}
+
+	order, err = b.order(orderType, price, quantity)
+	if err != nil {
+		return nil, sdk.Dec{}, err
+	}
+
+	// the chain checks margin against the quantity tick size
+	notional := order.OrderInfo.GetNotional()
+	margin := notional.Quo(leverage)
+	if minMargin := b.market.InitialMarginRatio.Mul(notional); margin.LT(minMargin) {
+		// lost to division precision at max leverage
+		margin = minMargin
+	}
+	order.Margin = RoundToTick(margin, b.market.MinQuantityTickSize, RoundUp, false)
+
+	if err = b.check(order); err != nil {
+		return nil, sdk.Dec{}, err
+	}
+
+	chainMarkPrice := ScaleDecimals(markPrice, b.quoteDecimals)
+	marginHold, err = order.CheckMarginAndGetMarginHold(b.market, chainMarkPrice, b.market.TakerFeeRate)
+	if err != nil {
+		err = errors.Wrap(err, "order margin check failed")
+		return nil, sdk.Dec{}, err
+	}
+
+	return order, marginHold, nil
+}
+
+// ReduceOnlyOrder builds an order without margin, which only reduces the position of the subaccount.
+func (b *DerivativeOrderBuilder) ReduceOnlyOrder(orderType exchangetypes.OrderType, price, quantity sdk.Dec) (*exchangetypes.DerivativeOrder, error) {
+	order, err := b.order(orderType, price, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	order.Margin = sdk.ZeroDec()
+	if err := b.check(order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (b *DerivativeOrderBuilder) order(orderType exchangetypes.OrderType, price, quantity sdk.Dec) (*exchangetypes.DerivativeOrder, error) {
+	chainPrice, err := b.ChainPrice(price, orderType.IsBuy())
+	if err != nil {
+		return nil, err
+	}
+
+	chainQuantity, err := b.ChainQuantity(quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	return &exchangetypes.DerivativeOrder{
+		MarketId:  b.market.MarketId,
+		OrderInfo: b.owner.orderInfo(chainPrice, chainQuantity),
+		OrderType: orderType,
+	}, nil
+}
+
+func (b *DerivativeOrderBuilder) check(order *exchangetypes.DerivativeOrder) error {
+	if err := order.ValidateBasic(b.owner.sender); err != nil {
+		err = errors.Wrap(err, "invalid derivative order")
+		return err
+	}
+
+	return order.CheckTickSize(b.market.MinPriceTickSize, b.market.MinQuantityTickSize)
+}
+
+// CreateLimitOrderMsg wraps the order into a message ready to broadcast.
+func (b *DerivativeOrderBuilder) CreateLimitOrderMsg(order *exchangetypes.DerivativeOrder) *exchangetypes.MsgCreateDerivativeLimitOrder {
+	return &exchangetypes.MsgCreateDerivativeLimitOrder{
+		Sender: b.owner.sender.String(),
+		Order:  *order,
+	}
+}
+
+// CreateMarketOrderMsg wraps the order into a market order message ready to broadcast,
+// the order price is the worst price accepted.
+func (b *DerivativeOrderBuilder) CreateMarketOrderMsg(order *exchangetypes.DerivativeOrder) *exchangetypes.MsgCreateDerivativeMarketOrder {
+	return &exchangetypes.MsgCreateDerivativeMarketOrder{
+		Sender: b.owner.sender.String(),
+		Order:  *order,
+	}
+}
+
+// BatchCreateLimitOrdersMsg wraps the orders into one message ready to broadcast.
+func (b *DerivativeOrderBuilder) BatchCreateLimitOrdersMsg(orders ...*exchangetypes.DerivativeOrder) *exchangetypes.MsgBatchCreateDerivativeLimitOrders {
+	msg := &exchangetypes.MsgBatchCreateDerivativeLimitOrders{
+		Sender: b.owner.sender.String(),
+		Orders: make([]exchangetypes.DerivativeOrder, 0, len(orders)),
+	}
+
+	for _, order := range orders {
+		msg.Orders = append(msg.Orders, *order)
+	}
+
+	return msg
+}
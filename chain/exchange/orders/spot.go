@@ -0,0 +1,139 @@
+package orders
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+)
+
+// SpotOrderBuilder builds orders of one spot market for one subaccount. Prices are given
+// in quote tokens per base token and quantities in base tokens, e.g. 12.5 USDT for 3 INJ.
+type SpotOrderBuilder struct {
+	market *exchangetypes.SpotMarket
+	owner  *orderOwner
+	opts   *builderOptions
+
+	baseDecimals  int
+	quoteDecimals int
+}
+
+// NewSpotOrderBuilder creates a builder for the market, with decimals of its base and quote denoms,
+// e.g. 18 for INJ and 6 for USDT. Orders are placed from the default subaccount of the sender,
+// unless OptionSubaccountNonce is set.
+func NewSpotOrderBuilder(
+	market *exchangetypes.SpotMarket,
+	baseDecimals, quoteDecimals uint32,
+	sender sdk.AccAddress,
+	options ...builderOption,
+) (*SpotOrderBuilder, error) {
+	if market == nil {
+		err := errors.New("market is nil")
+		return nil, err
+	}
+
+	opts := defaultBuilderOptions()
+	for _, opt := range options {
+		if err := opt(opts); err != nil {
+			err = errors.Wrap(err, "error in an order builder option")
+			return nil, err
+		}
+	}
+
+	owner, err := newOrderOwner(sender, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SpotOrderBuilder{
+		market:        market,
+		owner:         owner,
+		opts:          opts,
+		baseDecimals:  int(baseDecimals),
+		quoteDecimals: int(quoteDecimals),
+	}, nil
+}
+
+// SubaccountID returns the subaccount the orders are placed from.
+func (b *SpotOrderBuilder) SubaccountID() string {
+	return b.owner.subaccountID
+}
+
+// ChainPrice converts a human price to chain units, rounded to the price tick size.
+func (b *SpotOrderBuilder) ChainPrice(price sdk.Dec, isBuy bool) (sdk.Dec, error) {
+	return toTicks("price", price, b.quoteDecimals-b.baseDecimals, b.market.MinPriceTickSize, b.opts.PriceRounding, isBuy)
+}
+
+// ChainQuantity converts a human quantity to chain units, rounded to the quantity tick size.
+func (b *SpotOrderBuilder) ChainQuantity(quantity sdk.Dec) (sdk.Dec, error) {
+	return toTicks("quantity", quantity, b.baseDecimals, b.market.MinQuantityTickSize, b.opts.quantityRounding(), false)
+}
+
+// HumanPrice converts a chain price of the market back to quote tokens per base token.
+func (b *SpotOrderBuilder) HumanPrice(price sdk.Dec) sdk.Dec {
+	return ScaleDecimals(price, b.baseDecimals-b.quoteDecimals)
+}
+
+// HumanQuantity converts a chain quantity of the market back to base tokens.
+func (b *SpotOrderBuilder) HumanQuantity(quantity sdk.Dec) sdk.Dec {
+	return ScaleDecimals(quantity, -b.baseDecimals)
+}
+
+// Order builds an order of human price and quantity, that passes the tick size checks of the market.
+func (b *SpotOrderBuilder) Order(orderType exchangetypes.OrderType, price, quantity sdk.Dec) (*exchangetypes.SpotOrder, error) {
+	chainPrice, err := b.ChainPrice(price, orderType.IsBuy())
+	if err != nil {
+		return nil, err
+	}
+
+	chainQuantity, err := b.ChainQuantity(quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &exchangetypes.SpotOrder{
+		MarketId:  b.market.MarketId,
+		OrderInfo: b.owner.orderInfo(chainPrice, chainQuantity),
+		OrderType: orderType,
+	}
+
+	if err := order.ValidateBasic(b.owner.sender); err != nil {
+		err = errors.Wrap(err, "invalid spot order")
+		return nil, err
+	} else if err := order.CheckTickSize(b.market.MinPriceTickSize, b.market.MinQuantityTickSize); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// CreateLimitOrderMsg wraps the order into a message ready to broadcast.
+func (b *SpotOrderBuilder) CreateLimitOrderMsg(order *exchangetypes.SpotOrder) *exchangetypes.MsgCreateSpotLimitOrder {
+	return &exchangetypes.MsgCreateSpotLimitOrder{
+		Sender: b.owner.sender.String(),
+		Order:  *order,
+	}
+}
+
+// CreateMarketOrderMsg wraps the order into a market order message ready to broadcast,
+// the order price is the worst price accepted.
+func (b *SpotOrderBuilder) CreateMarketOrderMsg(order *exchangetypes.SpotOrder) *exchangetypes.MsgCreateSpotMarketOrder {
+	return &exchangetypes.MsgCreateSpotMarketOrder{
+		Sender: b.owner.sender.String(),
+		Order:  *order,
+	}
+}
+
+// BatchCreateLimitOrdersMsg wraps the orders into one message ready to broadcast.
+func (b *SpotOrderBuilder) BatchCreateLimitOrdersMsg(orders ...*exchangetypes.SpotOrder) *exchangetypes.MsgBatchCreateSpotLimitOrders {
+	msg := &exchangetypes.MsgBatchCreateSpotLimitOrders{
+		Sender: b.owner.sender.String(),
+		Orders: make([]exchangetypes.SpotOrder, 0, len(orders)),
+	}
+
+	for _, order := range orders {
+		msg.Orders = append(msg.Orders, *order)
+	}
+
+	return msg
+}
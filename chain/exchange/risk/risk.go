@@ -0,0 +1,326 @@
+// Package risk assesses the margin risk of the derivative positions of a subaccount at the current mark
+// prices, with unrealized funding accounted the way the chain settles it, and the impact of a price shock
+// on that risk.
+package risk
+
+import (
+	"sort"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+)
+
+// Market is a derivative market with its current prices.
+type Market struct {
+	Market    *exchangetypes.DerivativeMarket
+	MarkPrice sdk.Dec
+	// Funding of a perpetual market, nil for expiry futures.
+	Funding *exchangetypes.PerpetualMarketFunding
+}
+
+// Portfolio is the derivative state of one subaccount, all maps are keyed by market ID except deposits.
+type Portfolio struct {
+	Markets   map[common.Hash]*Market
+	Positions map[common.Hash]*exchangetypes.Position
+	// Orders are the resting derivative limit orders of the subaccount.
+	Orders map[common.Hash][]*exchangetypes.DerivativeLimitOrder
+	// Deposits by denom.
+	Deposits map[string]*exchangetypes.Deposit
+}
+
+// PositionRisk is the risk of one position at the mark price.
+type PositionRisk struct {
+	MarketID  common.Hash
+	IsLong    bool
+	Quantity  sdk.Dec
+	MarkPrice sdk.Dec
+	Notional  sdk.Dec
+
+	// Margin is the position margin with the unrealized funding payment applied.
+	Margin         sdk.Dec
+	FundingPayment sdk.Dec
+	UnrealizedPnl  sdk.Dec
+	// EffectiveMargin is the margin plus the unrealized PnL, what closing the position at the mark price pays out.
+	EffectiveMargin sdk.Dec
+
+	// Leverage is the notional over the effective margin, nil if the effective margin isn't positive.
+	Leverage sdk.Dec
+	// MarginRatio is the effective margin over the notional, liquidation happens below the maintenance margin ratio.
+	MarginRatio       sdk.Dec
+	MaintenanceMargin sdk.Dec
+
+	LiquidationPrice sdk.Dec
+	BankruptcyPrice  sdk.Dec
+	// LiquidationDistance is the relative mark price move reaching the liquidation price,
+	// negative if the position can already be liquidated.
+	LiquidationDistance sdk.Dec
+	Liquidatable        bool
+}
+
+// QuoteRisk sums the risk of the positions and orders of markets sharing a quote denom.
+type QuoteRisk struct {
+	Denom   string
+	Deposit *exchangetypes.Deposit
+
+	Notional          sdk.Dec
+	PositionMargin    sdk.Dec
+	UnrealizedPnl     sdk.Dec
+	MaintenanceMargin sdk.Dec
+	// OrderMargin is the margin and fees held by resting vanilla orders, OrderNotional their notional.
+	OrderMargin   sdk.Dec
+	OrderNotional sdk.Dec
+
+	// Equity is the total deposit balance plus the effective margin of the positions.
+	Equity sdk.Dec
+	// Leverage is the notional over the equity, LeverageWithOrders also counts resting vanilla orders
+	// as filled. Both are nil if the equity isn't positive.
+	Leverage           sdk.Dec
+	LeverageWithOrders sdk.Dec
+	// MarginRatio is the equity over the notional, nil if there are no positions.
+	MarginRatio sdk.Dec
+}
+
+// Report is the risk of a portfolio.
+type Report struct {
+	// Positions are sorted by market ID.
+	Positions []*PositionRisk
+	// Quotes are sorted by denom.
+	Quotes []*QuoteRisk
+}
+
+// Position returns the risk of the position in the market, nil if there's none.
+func (r *Report) Position(marketID common.Hash) *PositionRisk {
+	for _, p := range r.Positions {
+		if p.MarketID == marketID {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// Quote returns the risk of the positions in markets quoted in denom, nil if there are none.
+func (r *Report) Quote(denom string) *QuoteRisk {
+	for _, q := range r.Quotes {
+		if q.Denom == denom {
+			return q
+		}
+	}
+
+	return nil
+}
+
+// Assess computes the risk of the portfolio at the mark prices of its markets.
+func Assess(portfolio *Portfolio) (*Report, error) {
+	report := new(Report)
+	quotes := make(map[string]*QuoteRisk)
+
+	quote := func(denom string) *QuoteRisk {
+		if q, ok := quotes[denom]; ok {
+			return q
+		}
+
+		deposit := exchangetypes.NewDeposit()
+		if d := portfolio.Deposits[denom]; d != nil {
+			deposit = d
+		}
+
+		q := &QuoteRisk{
+			Denom:             denom,
+			Deposit:           deposit,
+			Notional:          sdk.ZeroDec(),
+			PositionMargin:    sdk.ZeroDec(),
+			UnrealizedPnl:     sdk.ZeroDec(),
+			MaintenanceMargin: sdk.ZeroDec(),
+			OrderMargin:       sdk.ZeroDec(),
+			OrderNotional:     sdk.ZeroDec(),
+		}
+		quotes[denom] = q
+		return q
+	}
+
+	for marketID, position := range portfolio.Positions {
+		if position == nil || position.Quantity.IsZero() {
+			continue
+		}
+
+		market, err := portfolio.market(marketID)
+		if err != nil {
+			return nil, err
+		}
+
+		p := assessPosition(marketID, market, position)
+		report.Positions = append(report.Positions, p)
+
+		q := quote(market.Market.QuoteDenom)
+		q.Notional = q.Notional.Add(p.Notional)
+		q.PositionMargin = q.PositionMargin.Add(p.Margin)
+		q.UnrealizedPnl = q.UnrealizedPnl.Add(p.UnrealizedPnl)
+		q.MaintenanceMargin = q.MaintenanceMargin.Add(p.MaintenanceMargin)
+	}
+
+	for marketID, orders := range portfolio.Orders {
+		if len(orders) == 0 {
+			continue
+		}
+
+		market, err := portfolio.market(marketID)
+		if err != nil {
+			return nil, err
+		}
+
+		q := quote(market.Market.QuoteDenom)
+		for _, o := range orders {
+			if !o.IsVanilla() {
+				continue
+			}
+
+			q.OrderMargin = q.OrderMargin.Add(o.GetCancelDepositDelta(market.Market.MakerFeeRate).AvailableBalanceDelta)
+			q.OrderNotional = q.OrderNotional.Add(o.Fillable.Mul(o.OrderInfo.Price))
+		}
+	}
+
+	for _, q := range quotes {
+		q.Equity = q.Deposit.TotalBalance.Add(q.PositionMargin).Add(q.UnrealizedPnl)
+		q.Leverage = ratio(q.Notional, q.Equity)
+		q.LeverageWithOrders = ratio(q.Notional.Add(q.OrderNotional), q.Equity)
+		if q.Notional.IsPositive() {
+			q.MarginRatio = q.Equity.Quo(q.Notional)
+		}
+
+		report.Quotes = append(report.Quotes, q)
+	}
+
+	sort.Slice(report.Positions, func(i, j int) bool {
+		return report.Positions[i].MarketID.Hex() < report.Positions[j].MarketID.Hex()
+	})
+	sort.Slice(report.Quotes, func(i, j int) bool {
+		return report.Quotes[i].Denom < report.Quotes[j].Denom
+	})
+
+	return report, nil
+}
+
+func (p *Portfolio) market(marketID common.Hash) (*Market, error) {
+	market := p.Markets[marketID]
+	if market == nil || market.Market == nil {
+		err := errors.Errorf("market %s not found", marketID.Hex())
+		return nil, err
+	} else if market.MarkPrice.IsNil() || !market.MarkPrice.IsPositive() {
+		err := errors.Errorf("invalid mark price %s of market %s", market.MarkPrice, marketID.Hex())
+		return nil, err
+	}
+
+	return market, nil
+}
+
+func assessPosition(marketID common.Hash, market *Market, position *exchangetypes.Position) *PositionRisk {
+	mmr := market.Market.MaintenanceMarginRatio
+	markPrice := market.MarkPrice
+
+	// liquidation prices account for unrealized funding themselves, so they're taken before it's applied
+	liquidationPrice := position.GetLiquidationPrice(mmr, market.Funding)
+	bankruptcyPrice := position.GetBankruptcyPrice(market.Funding)
+
+	copied := *position
+	state := copied.ApplyFundingAndGetUpdatedPositionState(market.Funding)
+
+	notional := copied.Quantity.Mul(markPrice)
+	unrealizedPnl := copied.GetPayoutFromPnl(markPrice, copied.Quantity)
+	effectiveMargin := copied.Margin.Add(unrealizedPnl)
+
+	liquidationDistance := markPrice.Sub(liquidationPrice).Quo(markPrice)
+	if !copied.IsLong {
+		liquidationDistance = liquidationDistance.Neg()
+	}
+
+	return &PositionRisk{
+		MarketID:            marketID,
+		IsLong:              copied.IsLong,
+		Quantity:            copied.Quantity,
+		MarkPrice:           markPrice,
+		Notional:            notional,
+		Margin:              copied.Margin,
+		FundingPayment:      state.FundingPayment,
+		UnrealizedPnl:       unrealizedPnl,
+		EffectiveMargin:     effectiveMargin,
+		Leverage:            ratio(notional, effectiveMargin),
+		MarginRatio:         effectiveMargin.Quo(notional),
+		MaintenanceMargin:   notional.Mul(mmr),
+		LiquidationPrice:    liquidationPrice,
+		BankruptcyPrice:     bankruptcyPrice,
+		LiquidationDistance: liquidationDistance,
+		Liquidatable:        !liquidationDistance.IsPositive(),
+	}
+}
+
+// ratio returns notional over margin, nil if the margin isn't positive.
+func ratio(notional, margin sdk.Dec) sdk.Dec {
+	if !margin.IsPositive() {
+		return sdk.Dec{}
+	}
+
+	return notional.Quo(margin)
+}
+
+// ShockImpact is the change of a portfolio risk when mark prices move.
+type ShockImpact struct {
+	Shock  sdk.Dec
+	Before *Report
+	After  *Report
+	// EquityChange is the equity change by quote denom.
+	EquityChange map[string]sdk.Dec
+	// Liquidated are the market IDs of positions that can be liquidated after the shock but not before.
+	Liquidated []common.Hash
+}
+
+// AssessShock computes the risk of the portfolio when all mark prices move by shock, relative to the current
+// mark price: -0.1 moves them 10% down. Funding is left as is.
+func AssessShock(portfolio *Portfolio, shock sdk.Dec) (*ShockImpact, error) {
+	if shock.IsNil() || shock.LTE(sdk.OneDec().Neg()) {
+		err := errors.Errorf("invalid price shock %s", shock)
+		return nil, err
+	}
+
+	before, err := Assess(portfolio)
+	if err != nil {
+		err = errors.Wrap(err, "failed to assess the portfolio")
+		return nil, err
+	}
+
+	shocked := *portfolio
+	shocked.Markets = make(map[common.Hash]*Market, len(portfolio.Markets))
+	for marketID, market := range portfolio.Markets {
+		m := *market
+		m.MarkPrice = market.MarkPrice.Mul(sdk.OneDec().Add(shock))
+		shocked.Markets[marketID] = &m
+	}
+
+	after, err := Assess(&shocked)
+	if err != nil {
+		err = errors.Wrap(err, "failed to assess the shocked portfolio")
+		return nil, err
+	}
+
+	impact := &ShockImpact{
+		Shock:        shock,
+		Before:       before,
+		After:        after,
+		EquityChange: make(map[string]sdk.Dec, len(after.Quotes)),
+	}
+
+	for _, q := range after.Quotes {
+		impact.EquityChange[q.Denom] = q.Equity.Sub(before.Quote(q.Denom).Equity)
+	}
+
+	for _, p := range after.Positions {
+		if p.Liquidatable && !before.Position(p.MarketID).Liquidatable {
+			impact.Liquidated = append(impact.Liquidated, p.MarketID)
+		}
+	}
+
+	return impact, nil
+}
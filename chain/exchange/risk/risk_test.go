@@ -0,0 +1,127 @@
+package risk
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/common"
+
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+)
+
+var (
+	testPerpetual = common.HexToHash("0x01")
+	testFutures   = common.HexToHash("0x02")
+)
+
+func testPortfolio() *Portfolio {
+	market := func(marketID common.Hash, isPerpetual bool) *exchangetypes.DerivativeMarket {
+		return &exchangetypes.DerivativeMarket{
+			MarketId:               marketID.Hex(),
+			QuoteDenom:             "usdt",
+			InitialMarginRatio:     sdk.MustNewDecFromStr("0.1"),
+			MaintenanceMarginRatio: sdk.MustNewDecFromStr("0.05"),
+			MakerFeeRate:           sdk.MustNewDecFromStr("0.001"),
+			TakerFeeRate:           sdk.MustNewDecFromStr("0.002"),
+			IsPerpetual:            isPerpetual,
+		}
+	}
+
+	return &Portfolio{
+		Markets: map[common.Hash]*Market{
+			testPerpetual: {
+				Market:    market(testPerpetual, true),
+				MarkPrice: sdk.NewDec(110),
+				Funding: &exchangetypes.PerpetualMarketFunding{
+					CumulativeFunding: sdk.OneDec(),
+					CumulativePrice:   sdk.ZeroDec(),
+				},
+			},
+			testFutures: {
+				Market:    market(testFutures, false),
+				MarkPrice: sdk.NewDec(50),
+			},
+		},
+		Positions: map[common.Hash]*exchangetypes.Position{
+			testPerpetual: {
+				IsLong:                 true,
+				Quantity:               sdk.NewDec(2),
+				EntryPrice:             sdk.NewDec(100),
+				Margin:                 sdk.NewDec(40),
+				CumulativeFundingEntry: sdk.ZeroDec(),
+			},
+			testFutures: {
+				IsLong:                 false,
+				Quantity:               sdk.OneDec(),
+				EntryPrice:             sdk.NewDec(50),
+				Margin:                 sdk.NewDec(10),
+				CumulativeFundingEntry: sdk.ZeroDec(),
+			},
+		},
+		Orders: map[common.Hash][]*exchangetypes.DerivativeLimitOrder{
+			testPerpetual: {{
+				OrderInfo: exchangetypes.OrderInfo{
+					Price:    sdk.NewDec(100),
+					Quantity: sdk.OneDec(),
+				},
+				OrderType: exchangetypes.OrderType_BUY,
+				Margin:    sdk.NewDec(20),
+				Fillable:  sdk.OneDec(),
+			}},
+		},
+		Deposits: map[string]*exchangetypes.Deposit{
+			"usdt": {
+				AvailableBalance: sdk.MustNewDecFromStr("79.9"),
+				TotalBalance:     sdk.NewDec(100),
+			},
+		},
+	}
+}
+
+func TestAssess(t *testing.T) {
+	portfolio := testPortfolio()
+
+	report, err := Assess(portfolio)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the long pays 2 of funding and gains 20 at the mark price
+	long := report.Position(testPerpetual)
+	if !long.Margin.Equal(sdk.NewDec(38)) || !long.UnrealizedPnl.Equal(sdk.NewDec(20)) || !long.EffectiveMargin.Equal(sdk.NewDec(58)) {
+		t.Fatalf("unexpected long risk %+v", long)
+	} else if !long.Leverage.Equal(sdk.NewDec(220).Quo(sdk.NewDec(58))) || long.Liquidatable {
+		t.Fatalf("unexpected long leverage %s", long.Leverage)
+	} else if !portfolio.Positions[testPerpetual].Margin.Equal(sdk.NewDec(40)) {
+		t.Fatal("expected the portfolio to be left untouched")
+	}
+
+	short := report.Position(testFutures)
+	if !short.LiquidationPrice.Equal(sdk.NewDec(60).Quo(sdk.MustNewDecFromStr("1.05"))) || !short.LiquidationDistance.IsPositive() {
+		t.Fatalf("unexpected short liquidation price %s at distance %s", short.LiquidationPrice, short.LiquidationDistance)
+	}
+
+	quote := report.Quote("usdt")
+	if !quote.Equity.Equal(sdk.NewDec(168)) || !quote.Notional.Equal(sdk.NewDec(270)) {
+		t.Fatalf("unexpected equity %s for notional %s", quote.Equity, quote.Notional)
+	} else if !quote.OrderMargin.Equal(sdk.MustNewDecFromStr("20.1")) || !quote.LeverageWithOrders.Equal(sdk.NewDec(370).Quo(sdk.NewDec(168))) {
+		t.Fatalf("unexpected order margin %s and leverage %s", quote.OrderMargin, quote.LeverageWithOrders)
+	}
+}
+
+func TestAssessShock(t *testing.T) {
+	impact, err := AssessShock(testPortfolio(), sdk.MustNewDecFromStr("0.2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if change := impact.EquityChange["usdt"]; !change.Equal(sdk.NewDec(34)) {
+		t.Fatalf("unexpected equity change %s", change)
+	} else if len(impact.Liquidated) != 1 || impact.Liquidated[0] != testFutures {
+		t.Fatalf("expected the short to be liquidated, got %v", impact.Liquidated)
+	}
+
+	if _, err := AssessShock(testPortfolio(), sdk.NewDec(-1)); err == nil {
+		t.Fatal("expected a shock to a zero price to fail")
+	}
+}
@@ -0,0 +1,191 @@
+package indexer
+
+import (
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gogo/protobuf/proto"
+	abci "github.com/tendermint/tendermint/abci/types"
+
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+)
+
+// EventKind groups exchange events by what they describe.
+type EventKind string
+
+const (
+	EventKindOrder    EventKind = "order"
+	EventKindTrade    EventKind = "trade"
+	EventKindDeposit  EventKind = "deposit"
+	EventKindPosition EventKind = "position"
+	EventKindFunding  EventKind = "funding"
+)
+
+// Event is an exchange event indexed from a block.
+type Event struct {
+	Height int64
+	Time   time.Time
+	// TxHash is empty for events emitted in begin or end block, e.g. batch executions.
+	TxHash string
+	// Index is the position of the event among indexed events of the block.
+	Index int
+
+	Kind          EventKind
+	MarketID      string
+	SubaccountIDs []string
+	// Data is the typed exchange event, e.g. *exchangetypes.EventBatchSpotExecution.
+	Data proto.Message
+}
+
+// classifyEvent returns the kind, market and subaccounts of a typed exchange event.
+// Returns false for events that are not indexed.
+func classifyEvent(ev proto.Message) (kind EventKind, marketID string, subaccountIDs []string, ok bool) {
+	subaccounts := newSubaccountSet()
+
+	switch ev := ev.(type) {
+	case *exchangetypes.EventNewSpotOrders:
+		for _, orders := range [][]*exchangetypes.SpotLimitOrder{ev.BuyOrders, ev.SellOrders} {
+			for _, order := range orders {
+				subaccounts.addHex(order.OrderInfo.SubaccountId)
+			}
+		}
+
+		return EventKindOrder, ev.MarketId, subaccounts.ids, true
+	case *exchangetypes.EventNewDerivativeOrders:
+		for _, orders := range [][]*exchangetypes.DerivativeLimitOrder{ev.BuyOrders, ev.SellOrders} {
+			for _, order := range orders {
+				subaccounts.addHex(order.OrderInfo.SubaccountId)
+			}
+		}
+
+		return EventKindOrder, ev.MarketId, subaccounts.ids, true
+	case *exchangetypes.EventCancelSpotOrder:
+		subaccounts.addHex(ev.Order.OrderInfo.SubaccountId)
+
+		return EventKindOrder, ev.MarketId, subaccounts.ids, true
+	case *exchangetypes.EventCancelDerivativeOrder:
+		if ev.LimitOrder != nil {
+			subaccounts.addHex(ev.LimitOrder.OrderInfo.SubaccountId)
+		}
+
+		if ev.MarketOrderCancel != nil && ev.MarketOrderCancel.MarketOrder != nil {
+			subaccounts.addHex(ev.MarketOrderCancel.MarketOrder.OrderInfo.SubaccountId)
+		}
+
+		return EventKindOrder, ev.MarketId, subaccounts.ids, true
+	case *exchangetypes.EventBatchSpotExecution:
+		for _, trade := range ev.Trades {
+			subaccounts.addBytes(trade.SubaccountId)
+		}
+
+		return EventKindTrade, ev.MarketId, subaccounts.ids, true
+	case *exchangetypes.EventBatchDerivativeExecution:
+		for _, trade := range ev.Trades {
+			subaccounts.addBytes(trade.SubaccountId)
+		}
+
+		return EventKindTrade, ev.MarketId, subaccounts.ids, true
+	case *exchangetypes.EventBatchDerivativePosition:
+		for _, position := range ev.Positions {
+			subaccounts.addBytes(position.SubaccountId)
+		}
+
+		return EventKindPosition, ev.MarketId, subaccounts.ids, true
+	case *exchangetypes.EventPerpetualMarketFundingUpdate:
+		return EventKindFunding, ev.MarketId, nil, true
+	case *exchangetypes.EventSubaccountDeposit:
+		subaccounts.addBytes(ev.SubaccountId)
+
+		return EventKindDeposit, "", subaccounts.ids, true
+	case *exchangetypes.EventSubaccountWithdraw:
+		subaccounts.addBytes(ev.SubaccountId)
+
+		return EventKindDeposit, "", subaccounts.ids, true
+	case *exchangetypes.EventSubaccountBalanceTransfer:
+		subaccounts.addHex(ev.SrcSubaccountId)
+		subaccounts.addHex(ev.DstSubaccountId)
+
+		return EventKindDeposit, "", subaccounts.ids, true
+	case *exchangetypes.EventBatchDepositUpdate:
+		for _, update := range ev.DepositUpdates {
+			for _, deposit := range update.Deposits {
+				subaccounts.addBytes(deposit.SubaccountId)
+			}
+		}
+
+		return EventKindDeposit, "", subaccounts.ids, true
+	default:
+		return "", "", nil, false
+	}
+}
+
+// parseEvent decodes an ABCI event into an indexed exchange event.
+// Returns false for events that are not typed exchange events.
+func parseEvent(abciEvent abci.Event) (*Event, bool) {
+	if proto.MessageType(abciEvent.Type) == nil {
+		return nil, false
+	}
+
+	typedEvent, err := sdk.ParseTypedEvent(abciEvent)
+	if err != nil {
+		return nil, false
+	}
+
+	kind, marketID, subaccountIDs, ok := classifyEvent(typedEvent)
+	if !ok {
+		return nil, false
+	}
+
+	return &Event{
+		Kind:          kind,
+		MarketID:      normalizeID(marketID),
+		SubaccountIDs: subaccountIDs,
+		Data:          typedEvent,
+	}, true
+}
+
+type subaccountSet struct {
+	seen map[string]struct{}
+	ids  []string
+}
+
+func newSubaccountSet() *subaccountSet {
+	return &subaccountSet{
+		seen: make(map[string]struct{}),
+	}
+}
+
+func (s *subaccountSet) addHex(id string) {
+	if len(id) == 0 {
+		return
+	}
+
+	s.add(normalizeID(id))
+}
+
+func (s *subaccountSet) addBytes(id []byte) {
+	if len(id) == 0 {
+		return
+	}
+
+	s.add(common.BytesToHash(id).Hex())
+}
+
+func (s *subaccountSet) add(id string) {
+	if _, ok := s.seen[id]; ok {
+		return
+	}
+
+	s.seen[id] = struct{}{}
+	s.ids = append(s.ids, id)
+}
+
+// normalizeID brings market and subaccount IDs to the lowercase 0x-prefixed form.
+func normalizeID(id string) string {
+	if len(id) == 0 {
+		return ""
+	}
+
+	return common.HexToHash(id).Hex()
+}
@@ -0,0 +1,136 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	abci "github.com/tendermint/tendermint/abci/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	log "github.com/xlab/suplog"
+
+	"github.com/InjectiveLabs/sdk-go/chain/client/tmclient"
+)
+
+const defaultPollInterval = time.Second
+
+// Indexer walks blocks and saves exchange events of each block into the store.
+// Progress is checkpointed per block, so a restarted indexer resumes where it stopped.
+type Indexer struct {
+	tmClient     tmclient.TendermintClient
+	store        *Store
+	startHeight  int64
+	pollInterval time.Duration
+	logger       log.Logger
+}
+
+// NewIndexer creates an indexer that starts at startHeight, unless the store has a checkpoint past it.
+func NewIndexer(tmClient tmclient.TendermintClient, store *Store, startHeight int64) *Indexer {
+	return &Indexer{
+		tmClient:     tmClient,
+		store:        store,
+		startHeight:  startHeight,
+		pollInterval: defaultPollInterval,
+		logger: log.WithFields(log.Fields{
+			"module": "sdk-go",
+			"svc":    "indexer",
+		}),
+	}
+}
+
+// SetPollInterval sets how often the chain is checked for new blocks, once the indexer caught up.
+func (i *Indexer) SetPollInterval(interval time.Duration) {
+	i.pollInterval = interval
+}
+
+// Run indexes blocks until ctx is done. Failed blocks are retried after the poll interval.
+func (i *Indexer) Run(ctx context.Context) error {
+	checkpoint, err := i.store.Checkpoint()
+	if err != nil {
+		return err
+	}
+
+	height := checkpoint + 1
+	if height < i.startHeight {
+		height = i.startHeight
+	}
+
+	i.logger.Infoln("indexing from height", height)
+
+	for {
+		latestHeight, err := i.tmClient.GetLatestBlockHeight(ctx)
+		if err != nil {
+			i.logger.WithError(err).Warningln("failed to get latest block height")
+		}
+
+		for err == nil && height <= latestHeight {
+			if err = i.IndexBlock(ctx, height); err != nil {
+				i.logger.WithError(err).WithField("height", height).Warningln("failed to index block, will retry")
+				break
+			}
+
+			height++
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(i.pollInterval):
+		}
+	}
+}
+
+// IndexBlock saves exchange events of the block at height and moves the checkpoint.
+func (i *Indexer) IndexBlock(ctx context.Context, height int64) error {
+	block, err := i.tmClient.GetBlock(ctx, height)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to get block %d", height)
+		return err
+	}
+
+	results, err := i.tmClient.GetBlockResults(ctx, height)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to get block results %d", height)
+		return err
+	}
+
+	var events []*Event
+	addEvents := func(txHash string, abciEvents []abci.Event) {
+		for _, abciEvent := range abciEvents {
+			ev, ok := parseEvent(abciEvent)
+			if !ok {
+				continue
+			}
+
+			ev.Height = height
+			ev.Time = block.Block.Time
+			ev.TxHash = txHash
+			ev.Index = len(events)
+			events = append(events, ev)
+		}
+	}
+
+	addEvents("", results.BeginBlockEvents)
+
+	for idx, txResult := range results.TxsResults {
+		if txResult == nil || txResult.Code != 0 || idx >= len(block.Block.Txs) {
+			// failed Txns have no effect
+			continue
+		}
+
+		addEvents(fmt.Sprintf("%X", tmtypes.Tx(block.Block.Txs[idx]).Hash()), txResult.Events)
+	}
+
+	addEvents("", results.EndBlockEvents)
+
+	if err := i.store.SaveBlock(height, events); err != nil {
+		return err
+	}
+
+	if len(events) > 0 {
+		i.logger.WithField("height", height).Debugln("indexed", len(events), "exchange events")
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package indexer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gogo/protobuf/proto"
+	abci "github.com/tendermint/tendermint/abci/types"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/InjectiveLabs/sdk-go/chain/client/tmclient"
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+)
+
+type blockSource struct {
+	tmclient.TendermintClient
+
+	blockTime time.Time
+	results   map[int64]*ctypes.ResultBlockResults
+}
+
+func (s *blockSource) GetBlock(_ context.Context, height int64) (*ctypes.ResultBlock, error) {
+	return &ctypes.ResultBlock{
+		Block: &tmtypes.Block{
+			Header: tmtypes.Header{
+				Height: height,
+				Time:   s.blockTime.Add(time.Duration(height) * time.Second),
+			},
+			Data: tmtypes.Data{
+				Txs: tmtypes.Txs{tmtypes.Tx("deposit")},
+			},
+		},
+	}, nil
+}
+
+func (s *blockSource) GetBlockResults(_ context.Context, height int64) (*ctypes.ResultBlockResults, error) {
+	return s.results[height], nil
+}
+
+func TestIndexBlocks(t *testing.T) {
+	marketID := common.HexToHash("0x01").Hex()
+	subaccountID := common.HexToHash("0xaa")
+
+	deposit := mustTypedEvent(t, &exchangetypes.EventSubaccountDeposit{
+		SrcAddress:   "inj1src",
+		SubaccountId: subaccountID.Bytes(),
+		Amount:       sdk.NewInt64Coin("inj", 1),
+	})
+
+	trade := mustTypedEvent(t, &exchangetypes.EventBatchSpotExecution{
+		MarketId: marketID,
+		Trades: []*exchangetypes.TradeLog{{
+			SubaccountId: subaccountID.Bytes(),
+			Quantity:     sdk.NewDec(1),
+			Price:        sdk.NewDec(2),
+			Fee:          sdk.ZeroDec(),
+		}},
+	})
+
+	source := &blockSource{
+		blockTime: time.Unix(1600000000, 0).UTC(),
+		results: map[int64]*ctypes.ResultBlockResults{
+			1: {
+				Height:     1,
+				TxsResults: []*abci.ResponseDeliverTx{{Events: []abci.Event{deposit}}},
+			},
+			2: {
+				Height:         2,
+				TxsResults:     []*abci.ResponseDeliverTx{{Code: 5, Events: []abci.Event{deposit}}},
+				EndBlockEvents: []abci.Event{trade},
+			},
+		},
+	}
+
+	store := NewStore(dbm.NewMemDB())
+	indexer := NewIndexer(source, store, 1)
+
+	for height := int64(1); height <= 2; height++ {
+		if err := indexer.IndexBlock(context.Background(), height); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if checkpoint, err := store.Checkpoint(); err != nil {
+		t.Fatal(err)
+	} else if checkpoint != 2 {
+		t.Fatalf("expected checkpoint 2, got %d", checkpoint)
+	}
+
+	events, err := store.Events(Query{SubaccountID: subaccountID.Hex()})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(events) != 2 {
+		t.Fatalf("expected 2 events of the subaccount, failed Tx excluded, got %d", len(events))
+	} else if events[0].Kind != EventKindDeposit || len(events[0].TxHash) == 0 {
+		t.Fatalf("expected deposit in a Tx first, got %v", events[0])
+	} else if _, ok := events[1].Data.(*exchangetypes.EventBatchSpotExecution); !ok {
+		t.Fatalf("expected spot execution, got %T", events[1].Data)
+	}
+
+	events, err = store.Events(Query{MarketID: marketID, Kinds: []EventKind{EventKindTrade}})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(events) != 1 || events[0].Height != 2 {
+		t.Fatalf("expected 1 trade in block 2, got %v", events)
+	}
+
+	events, err = store.Events(Query{
+		From: source.blockTime.Add(2 * time.Second),
+		To:   source.blockTime.Add(3 * time.Second),
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(events) != 1 || events[0].Height != 2 {
+		t.Fatalf("expected only events of block 2 in time range, got %v", events)
+	}
+}
+
+func mustTypedEvent(t *testing.T, ev proto.Message) abci.Event {
+	typedEvent, err := sdk.TypedEventToEvent(ev)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return abci.Event(typedEvent)
+}
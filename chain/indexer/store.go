@@ -0,0 +1,303 @@
+package indexer
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+	dbm "github.com/tendermint/tm-db"
+)
+
+// Key layout, all integers are big-endian so keys sort by height and time:
+//
+//	e/<height><index>                      -> stored event
+//	t/<time><height><index>                -> event key
+//	m/<market>/<time><height><index>       -> event key
+//	s/<subaccount>/<time><height><index>   -> event key
+//	checkpoint                             -> last indexed height
+var (
+	eventPrefix      = []byte("e/")
+	timeIndexPrefix  = []byte("t/")
+	marketPrefix     = []byte("m/")
+	subaccountPrefix = []byte("s/")
+	checkpointKey    = []byte("checkpoint")
+)
+
+// Store keeps indexed exchange events in an embedded key-value database.
+type Store struct {
+	db dbm.DB
+}
+
+// OpenStore opens (or creates) a LevelDB database in dir.
+func OpenStore(dir string) (*Store, error) {
+	db, err := dbm.NewDB("exchange_events", dbm.GoLevelDBBackend, dir)
+	if err != nil {
+		err = errors.Wrap(err, "failed to open events database")
+		return nil, err
+	}
+
+	return NewStore(db), nil
+}
+
+// NewStore creates a store on top of an existing database, e.g. dbm.NewMemDB() for tests.
+func NewStore(db dbm.DB) *Store {
+	return &Store{
+		db: db,
+	}
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Checkpoint returns the last indexed height, zero if nothing has been indexed.
+func (s *Store) Checkpoint() (int64, error) {
+	bz, err := s.db.Get(checkpointKey)
+	if err != nil {
+		err = errors.Wrap(err, "failed to get checkpoint")
+		return 0, err
+	} else if len(bz) == 0 {
+		return 0, nil
+	}
+
+	return int64(binary.BigEndian.Uint64(bz)), nil
+}
+
+// SaveBlock atomically stores events of a block and moves the checkpoint to height.
+func (s *Store) SaveBlock(height int64, events []*Event) error {
+	batch := s.db.NewBatch()
+	defer batch.Close()
+
+	for _, ev := range events {
+		value, err := encodeEvent(ev)
+		if err != nil {
+			return err
+		}
+
+		key := eventKeyOf(ev)
+		if err := batch.Set(key, value); err != nil {
+			return err
+		}
+
+		suffix := timeSuffixOf(ev)
+		if err := batch.Set(concat(timeIndexPrefix, suffix), key); err != nil {
+			return err
+		}
+
+		if len(ev.MarketID) > 0 {
+			if err := batch.Set(concat(marketPrefix, []byte(ev.MarketID+"/"), suffix), key); err != nil {
+				return err
+			}
+		}
+
+		for _, subaccountID := range ev.SubaccountIDs {
+			if err := batch.Set(concat(subaccountPrefix, []byte(subaccountID+"/"), suffix), key); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := batch.Set(checkpointKey, uint64Bytes(uint64(height))); err != nil {
+		return err
+	}
+
+	if err := batch.WriteSync(); err != nil {
+		err = errors.Wrapf(err, "failed to save block %d", height)
+		return err
+	}
+
+	return nil
+}
+
+// Query selects indexed events. Empty fields don't filter.
+type Query struct {
+	SubaccountID string
+	MarketID     string
+	Kinds        []EventKind
+	// From is inclusive, To is exclusive.
+	From time.Time
+	To   time.Time
+	// Limit is the max amount of events to return, zero for no limit.
+	Limit int
+}
+
+// Events returns events matching the query, ordered by time.
+func (s *Store) Events(q Query) ([]*Event, error) {
+	marketID := normalizeID(q.MarketID)
+	subaccountID := normalizeID(q.SubaccountID)
+
+	var prefix []byte
+	switch {
+	case len(subaccountID) > 0:
+		prefix = concat(subaccountPrefix, []byte(subaccountID+"/"))
+	case len(marketID) > 0:
+		prefix = concat(marketPrefix, []byte(marketID+"/"))
+	default:
+		prefix = timeIndexPrefix
+	}
+
+	start := prefix
+	if !q.From.IsZero() {
+		start = concat(prefix, uint64Bytes(uint64(q.From.UnixNano())))
+	}
+
+	end := prefixEnd(prefix)
+	if !q.To.IsZero() {
+		end = concat(prefix, uint64Bytes(uint64(q.To.UnixNano())))
+	}
+
+	it, err := s.db.Iterator(start, end)
+	if err != nil {
+		err = errors.Wrap(err, "failed to iterate events")
+		return nil, err
+	}
+	defer it.Close()
+
+	var events []*Event
+	for ; it.Valid(); it.Next() {
+		value, err := s.db.Get(it.Value())
+		if err != nil {
+			err = errors.Wrap(err, "failed to get event")
+			return nil, err
+		}
+
+		ev, err := decodeEvent(value)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(marketID) > 0 && ev.MarketID != marketID {
+			continue
+		} else if !matchesKind(ev.Kind, q.Kinds) {
+			continue
+		}
+
+		events = append(events, ev)
+		if q.Limit > 0 && len(events) >= q.Limit {
+			break
+		}
+	}
+
+	return events, it.Error()
+}
+
+func matchesKind(kind EventKind, kinds []EventKind) bool {
+	if len(kinds) == 0 {
+		return true
+	}
+
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+type storedEvent struct {
+	Height        int64     `json:"height"`
+	Time          int64     `json:"time"`
+	TxHash        string    `json:"tx_hash,omitempty"`
+	Index         int       `json:"index"`
+	Kind          EventKind `json:"kind"`
+	MarketID      string    `json:"market_id,omitempty"`
+	SubaccountIDs []string  `json:"subaccount_ids,omitempty"`
+	Type          string    `json:"type"`
+	Data          []byte    `json:"data"`
+}
+
+func encodeEvent(ev *Event) ([]byte, error) {
+	data, err := proto.Marshal(ev.Data)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to marshal %s", proto.MessageName(ev.Data))
+		return nil, err
+	}
+
+	return json.Marshal(&storedEvent{
+		Height:        ev.Height,
+		Time:          ev.Time.UnixNano(),
+		TxHash:        ev.TxHash,
+		Index:         ev.Index,
+		Kind:          ev.Kind,
+		MarketID:      ev.MarketID,
+		SubaccountIDs: ev.SubaccountIDs,
+		Type:          proto.MessageName(ev.Data),
+		Data:          data,
+	})
+}
+
+func decodeEvent(bz []byte) (*Event, error) {
+	var stored storedEvent
+	if err := json.Unmarshal(bz, &stored); err != nil {
+		err = errors.Wrap(err, "failed to unmarshal stored event")
+		return nil, err
+	}
+
+	msgType := proto.MessageType(stored.Type)
+	if msgType == nil || msgType.Kind() != reflect.Ptr {
+		err := errors.Errorf("unknown event type %s", stored.Type)
+		return nil, err
+	}
+
+	data := reflect.New(msgType.Elem()).Interface().(proto.Message)
+	if err := proto.Unmarshal(stored.Data, data); err != nil {
+		err = errors.Wrapf(err, "failed to unmarshal %s", stored.Type)
+		return nil, err
+	}
+
+	return &Event{
+		Height:        stored.Height,
+		Time:          time.Unix(0, stored.Time).UTC(),
+		TxHash:        stored.TxHash,
+		Index:         stored.Index,
+		Kind:          stored.Kind,
+		MarketID:      stored.MarketID,
+		SubaccountIDs: stored.SubaccountIDs,
+		Data:          data,
+	}, nil
+}
+
+func eventKeyOf(ev *Event) []byte {
+	return concat(eventPrefix, uint64Bytes(uint64(ev.Height)), uint64Bytes(uint64(ev.Index)))
+}
+
+func timeSuffixOf(ev *Event) []byte {
+	return concat(uint64Bytes(uint64(ev.Time.UnixNano())), uint64Bytes(uint64(ev.Height)), uint64Bytes(uint64(ev.Index)))
+}
+
+// prefixEnd returns the smallest key greater than all keys with the prefix.
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+
+	return nil
+}
+
+func uint64Bytes(v uint64) []byte {
+	bz := make([]byte, 8)
+	binary.BigEndian.PutUint64(bz, v)
+	return bz
+}
+
+func concat(parts ...[]byte) []byte {
+	var size int
+	for _, part := range parts {
+		size += len(part)
+	}
+
+	result := make([]byte, 0, size)
+	for _, part := range parts {
+		result = append(result, part...)
+	}
+
+	return result
+}
@@ -32,6 +32,11 @@ func WrapTxToEIP712(
 		return typeddata.TypedData{}, err
 	}
 
+	if _, ok := txData["timeout_height"]; !ok {
+		// omitted from Amino JSON when not set, but Tx type always has it
+		txData["timeout_height"] = "0"
+	}
+
 	domain := typeddata.TypedDataDomain{
 		Name:              "Injective Web3",
 		Version:           "1.0.0",
@@ -0,0 +1,60 @@
+package exchange
+
+import (
+	"google.golang.org/grpc"
+
+	chainclient "github.com/InjectiveLabs/sdk-go/chain/client"
+	accountsPB "github.com/InjectiveLabs/sdk-go/exchange/accounts_rpc/pb"
+	derivativeExchangePB "github.com/InjectiveLabs/sdk-go/exchange/derivative_exchange_rpc/pb"
+	exchangePB "github.com/InjectiveLabs/sdk-go/exchange/exchange_rpc/pb"
+	insurancePB "github.com/InjectiveLabs/sdk-go/exchange/insurance_rpc/pb"
+	oraclePB "github.com/InjectiveLabs/sdk-go/exchange/oracle_rpc/pb"
+	spotExchangePB "github.com/InjectiveLabs/sdk-go/exchange/spot_exchange_rpc/pb"
+)
+
+// APIClient bundles clients of all exchange API services, sharing one gRPC connection.
+type APIClient struct {
+	conn *grpc.ClientConn
+
+	Accounts           accountsPB.InjectiveAccountsRPCClient
+	DerivativeExchange derivativeExchangePB.InjectiveDerivativeExchangeRPCClient
+	Exchange           exchangePB.InjectiveExchangeRPCClient
+	Insurance          insurancePB.InjectiveInsuranceRPCClient
+	Oracle             oraclePB.InjectiveOracleRPCClient
+	SpotExchange       spotExchangePB.InjectiveSpotExchangeRPCClient
+}
+
+// NewAPIClient connects to the exchange API server at protoAddr, which must be in form
+// "tcp://127.0.0.1:9910" or "unix:///tmp/test.sock", protocol is required.
+// TLS and auth are set up by options, the same way as for the chain client.
+func NewAPIClient(protoAddr string, options ...chainclient.DialOption) (*APIClient, error) {
+	conn, err := chainclient.DialGRPC(protoAddr, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewAPIClientWithConn(conn), nil
+}
+
+// NewAPIClientWithConn creates exchange API clients using an existing connection.
+func NewAPIClientWithConn(conn *grpc.ClientConn) *APIClient {
+	return &APIClient{
+		conn: conn,
+
+		Accounts:           accountsPB.NewInjectiveAccountsRPCClient(conn),
+		DerivativeExchange: derivativeExchangePB.NewInjectiveDerivativeExchangeRPCClient(conn),
+		Exchange:           exchangePB.NewInjectiveExchangeRPCClient(conn),
+		Insurance:          insurancePB.NewInjectiveInsuranceRPCClient(conn),
+		Oracle:             oraclePB.NewInjectiveOracleRPCClient(conn),
+		SpotExchange:       spotExchangePB.NewInjectiveSpotExchangeRPCClient(conn),
+	}
+}
+
+// Conn returns the underlying gRPC connection.
+func (c *APIClient) Conn() *grpc.ClientConn {
+	return c.conn
+}
+
+func (c *APIClient) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,149 @@
+package exchange
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	chainclient "github.com/InjectiveLabs/sdk-go/chain/client"
+	exchangePB "github.com/InjectiveLabs/sdk-go/exchange/exchange_rpc/pb"
+)
+
+// selfSignedCert writes a self-signed cert for 127.0.0.1 and its key, the cert serves as its own CA.
+func selfSignedCert(t *testing.T, dir, name string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile = filepath.Join(dir, name+".pem")
+	keyFile = filepath.Join(dir, name+".key")
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	} else if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return certFile, keyFile
+}
+
+type pingServer struct {
+	exchangePB.UnimplementedInjectiveExchangeRPCServer
+
+	mdC         chan metadata.MD
+	clientCertC chan *x509.Certificate
+}
+
+func (s *pingServer) Ping(ctx context.Context, _ *exchangePB.PingRequest) (*exchangePB.PingResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.mdC <- md
+
+	var clientCert *x509.Certificate
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.PeerCertificates) > 0 {
+			clientCert = tlsInfo.State.PeerCertificates[0]
+		}
+	}
+	s.clientCertC <- clientCert
+
+	return &exchangePB.PingResponse{}, nil
+}
+
+func TestAPIClientMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	serverCertFile, serverKeyFile := selfSignedCert(t, dir, "server")
+	clientCertFile, clientKeyFile := selfSignedCert(t, dir, "client")
+
+	serverCert, err := tls.LoadX509KeyPair(serverCertFile, serverKeyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientPEM, err := ioutil.ReadFile(clientCertFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(clientPEM)
+
+	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})))
+
+	ping := &pingServer{
+		mdC:         make(chan metadata.MD, 1),
+		clientCertC: make(chan *x509.Certificate, 1),
+	}
+	exchangePB.RegisterInjectiveExchangeRPCServer(server, ping)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go server.Serve(lis)
+	defer server.Stop()
+
+	c, err := NewAPIClient("tcp://"+lis.Addr().String(),
+		chainclient.DialOptionMutualTLS(serverCertFile, clientCertFile, clientKeyFile),
+		chainclient.DialOptionBearerToken("secret"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFn()
+
+	if _, err := c.Exchange.Ping(ctx, &exchangePB.PingRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if auth := (<-ping.mdC).Get("authorization"); len(auth) != 1 || auth[0] != "Bearer secret" {
+		t.Fatalf("unexpected authorization header %v", auth)
+	} else if clientCert := <-ping.clientCertC; clientCert == nil || clientCert.Subject.CommonName != "client" {
+		t.Fatalf("expected the client cert to be presented, got %v", clientCert)
+	}
+}
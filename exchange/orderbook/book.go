@@ -0,0 +1,365 @@
+// Package orderbook keeps local replicas of exchange orderbooks, bootstrapped from
+// the exchange API snapshots and kept up to date by the order streams.
+package orderbook
+
+import (
+	"sort"
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+)
+
+var (
+	// ErrOutOfOrder means an update older than the previous one has been received.
+	ErrOutOfOrder = errors.New("out of order update")
+	// ErrGap means an update refers to book state that has never been received.
+	ErrGap = errors.New("gap in updates")
+)
+
+// Order is a resting limit order, prices and quantities are in chain units.
+type Order struct {
+	Hash         string
+	MarketID     string
+	SubaccountID string
+	FeeRecipient string
+	OrderType    exchangetypes.OrderType
+
+	Price    sdk.Dec
+	Quantity sdk.Dec
+	// Fillable is the quantity remaining unfilled.
+	Fillable sdk.Dec
+	// Margin is zero for spot orders and reduce-only derivative orders.
+	Margin sdk.Dec
+
+	// CreatedAt and UpdatedAt are in UNIX millis.
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+func (o *Order) IsBuy() bool {
+	return o.OrderType.IsBuy()
+}
+
+// OrderUpdate is a change of one order, as streamed by the exchange API.
+type OrderUpdate struct {
+	Order *Order
+	// Removed is set for filled and canceled orders.
+	Removed bool
+	// Timestamp of the update in UNIX millis.
+	Timestamp int64
+}
+
+// Level is a price level of the book, with its orders in time priority.
+type Level struct {
+	Price    sdk.Dec
+	Quantity sdk.Dec
+	Orders   []Order
+}
+
+type level struct {
+	price  sdk.Dec
+	orders []*Order
+}
+
+func (l *level) export() Level {
+	exported := Level{
+		Price:    l.price,
+		Quantity: sdk.ZeroDec(),
+		Orders:   make([]Order, 0, len(l.orders)),
+	}
+
+	for _, o := range l.orders {
+		exported.Quantity = exported.Quantity.Add(o.Fillable)
+		exported.Orders = append(exported.Orders, *o)
+	}
+
+	return exported
+}
+
+// Book is an L3 orderbook of one market, safe for concurrent use. L2 levels are aggregated from orders.
+type Book struct {
+	mux      *sync.RWMutex
+	marketID string
+
+	// bids and asks are sorted from the best price
+	bids   []*level
+	asks   []*level
+	orders map[string]*Order
+
+	// snapshotAt is the latest order update time seen in the snapshot
+	snapshotAt int64
+	// timestamp is the time of the latest streamed update
+	timestamp int64
+	synced    bool
+
+	changeC chan struct{}
+}
+
+// NewBook creates an empty book, it's not synced until the first Reset.
+func NewBook(marketID string) *Book {
+	return &Book{
+		mux:      new(sync.RWMutex),
+		marketID: marketID,
+		orders:   make(map[string]*Order),
+		changeC:  make(chan struct{}, 1),
+	}
+}
+
+func (b *Book) MarketID() string {
+	return b.marketID
+}
+
+// Changes returns a channel that receives a value after the book has changed. Notifications
+// are coalesced, a receiver that falls behind gets one for all changes since its last receive.
+func (b *Book) Changes() <-chan struct{} {
+	return b.changeC
+}
+
+func (b *Book) notify() {
+	select {
+	case b.changeC <- struct{}{}:
+	default:
+	}
+}
+
+// Synced tells whether the book reflects the streamed state, that is it has been reset
+// from a snapshot and no gap has been detected since.
+func (b *Book) Synced() bool {
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+
+	return b.synced
+}
+
+// Invalidate marks the book as out of sync until the next Reset.
+func (b *Book) Invalidate() {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.synced = false
+}
+
+// Reset replaces the book contents by the snapshot orders.
+func (b *Book) Reset(orders []*Order) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.bids = nil
+	b.asks = nil
+	b.orders = make(map[string]*Order, len(orders))
+	b.snapshotAt = 0
+	b.timestamp = 0
+
+	sorted := append([]*Order(nil), orders...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt < sorted[j].CreatedAt
+	})
+
+	for _, o := range sorted {
+		if o.UpdatedAt > b.snapshotAt {
+			b.snapshotAt = o.UpdatedAt
+		}
+
+		b.insert(o)
+	}
+
+	b.synced = true
+	b.notify()
+}
+
+// Apply applies a streamed update. Updates older than the order state already in the book, or older than
+// the snapshot for orders missing in it, are skipped, they come from the overlap of the stream and the snapshot. On ErrOutOfOrder or ErrGap the book
+// is left out of sync and must be reset.
+func (b *Book) Apply(u *OrderUpdate) error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	if !b.synced {
+		err := errors.New("book is not synced")
+		return err
+	}
+
+	if u.Timestamp < b.timestamp {
+		b.synced = false
+		err := errors.Wrapf(ErrOutOfOrder, "update at %d after update at %d", u.Timestamp, b.timestamp)
+		return err
+	}
+	b.timestamp = u.Timestamp
+
+	prev, known := b.orders[u.Order.Hash]
+	if known && u.Order.UpdatedAt < prev.UpdatedAt {
+		return nil
+	}
+
+	removed := u.Removed || !u.Order.Fillable.IsPositive()
+	switch {
+	case removed && !known:
+		// never seen or already removed
+		return nil
+	case !known && u.Timestamp <= b.snapshotAt:
+		// buffered before the snapshot, which has no such order, so it has been removed by then,
+		// e.g. created and canceled while the snapshot was fetched
+		return nil
+	case !known && u.Order.CreatedAt <= b.snapshotAt:
+		// a resting order older than the snapshot should have been in it
+		b.synced = false
+		err := errors.Wrapf(ErrGap, "order %s created at %d is missing in the snapshot", u.Order.Hash, u.Order.CreatedAt)
+		return err
+	}
+
+	switch {
+	case removed:
+		b.remove(prev)
+	case known && prev.Price.Equal(u.Order.Price):
+		// keeps time priority of a partially filled order
+		b.replace(prev, u.Order)
+	default:
+		if known {
+			b.remove(prev)
+		}
+
+		b.insert(u.Order)
+	}
+
+	b.notify()
+	return nil
+}
+
+func (b *Book) side(isBuy bool) *[]*level {
+	if isBuy {
+		return &b.bids
+	}
+
+	return &b.asks
+}
+
+// levelIndex returns the index of the price level in the side, or where it would be inserted.
+func levelIndex(levels []*level, price sdk.Dec, isBuy bool) int {
+	return sort.Search(len(levels), func(i int) bool {
+		if isBuy {
+			return levels[i].price.LTE(price)
+		}
+
+		return levels[i].price.GTE(price)
+	})
+}
+
+func (b *Book) insert(o *Order) {
+	levels := b.side(o.IsBuy())
+	idx := levelIndex(*levels, o.Price, o.IsBuy())
+
+	if idx == len(*levels) || !(*levels)[idx].price.Equal(o.Price) {
+		*levels = append(*levels, nil)
+		copy((*levels)[idx+1:], (*levels)[idx:])
+		(*levels)[idx] = &level{
+			price: o.Price,
+		}
+	}
+
+	lvl := (*levels)[idx]
+	lvl.orders = append(lvl.orders, o)
+	b.orders[o.Hash] = o
+}
+
+// find returns the level of the order and the order index within it.
+func (b *Book) find(o *Order) (levels *[]*level, levelIdx, orderIdx int, ok bool) {
+	levels = b.side(o.IsBuy())
+	levelIdx = levelIndex(*levels, o.Price, o.IsBuy())
+	if levelIdx == len(*levels) || !(*levels)[levelIdx].price.Equal(o.Price) {
+		return levels, 0, 0, false
+	}
+
+	for i, lo := range (*levels)[levelIdx].orders {
+		if lo.Hash == o.Hash {
+			return levels, levelIdx, i, true
+		}
+	}
+
+	return levels, 0, 0, false
+}
+
+func (b *Book) replace(prev, o *Order) {
+	levels, levelIdx, orderIdx, ok := b.find(prev)
+	if !ok {
+		b.insert(o)
+		return
+	}
+
+	(*levels)[levelIdx].orders[orderIdx] = o
+	b.orders[o.Hash] = o
+}
+
+func (b *Book) remove(o *Order) {
+	delete(b.orders, o.Hash)
+
+	levels, levelIdx, orderIdx, ok := b.find(o)
+	if !ok {
+		return
+	}
+
+	lvl := (*levels)[levelIdx]
+	lvl.orders = append(lvl.orders[:orderIdx], lvl.orders[orderIdx+1:]...)
+
+	if len(lvl.orders) == 0 {
+		*levels = append((*levels)[:levelIdx], (*levels)[levelIdx+1:]...)
+	}
+}
+
+// BestBid returns the highest buy level, if any.
+func (b *Book) BestBid() (Level, bool) {
+	return b.best(true)
+}
+
+// BestAsk returns the lowest sell level, if any.
+func (b *Book) BestAsk() (Level, bool) {
+	return b.best(false)
+}
+
+func (b *Book) best(isBuy bool) (Level, bool) {
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+
+	levels := *b.side(isBuy)
+	if len(levels) == 0 {
+		return Level{}, false
+	}
+
+	return levels[0].export(), true
+}
+
+// Depth returns up to n best levels of each side, all levels if n is not positive.
+func (b *Book) Depth(n int) (bids, asks []Level) {
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+
+	return exportLevels(b.bids, n), exportLevels(b.asks, n)
+}
+
+func exportLevels(levels []*level, n int) []Level {
+	if n <= 0 || n > len(levels) {
+		n = len(levels)
+	}
+
+	exported := make([]Level, 0, n)
+	for _, lvl := range levels[:n] {
+		exported = append(exported, lvl.export())
+	}
+
+	return exported
+}
+
+// Order returns the resting order by its hash.
+func (b *Book) Order(hash string) (Order, bool) {
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+
+	o, ok := b.orders[hash]
+	if !ok {
+		return Order{}, false
+	}
+
+	return *o, true
+}
@@ -0,0 +1,263 @@
+package orderbook
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+
+	chainclient "github.com/InjectiveLabs/sdk-go/chain/client"
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+)
+
+func testOrder(hash string, orderType exchangetypes.OrderType, price, fillable int64, createdAt int64) *Order {
+	return &Order{
+		Hash:      hash,
+		OrderType: orderType,
+		Price:     sdk.NewDec(price),
+		Quantity:  sdk.NewDec(fillable),
+		Fillable:  sdk.NewDec(fillable),
+		Margin:    sdk.ZeroDec(),
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+}
+
+func testSnapshot() []*Order {
+	return []*Order{
+		testOrder("0x01", exchangetypes.OrderType_BUY, 100, 1, 10),
+		testOrder("0x02", exchangetypes.OrderType_BUY, 99, 2, 11),
+		testOrder("0x03", exchangetypes.OrderType_BUY, 100, 3, 12),
+		testOrder("0x04", exchangetypes.OrderType_SELL, 102, 4, 13),
+		testOrder("0x05", exchangetypes.OrderType_SELL, 101, 5, 14),
+	}
+}
+
+func TestBook(t *testing.T) {
+	book := NewBook("market")
+	book.Reset(testSnapshot())
+	<-book.Changes()
+
+	bid, ok := book.BestBid()
+	if !ok || !bid.Price.Equal(sdk.NewDec(100)) || !bid.Quantity.Equal(sdk.NewDec(4)) || bid.Orders[0].Hash != "0x01" {
+		t.Fatalf("unexpected best bid %+v", bid)
+	}
+
+	ask, ok := book.BestAsk()
+	if !ok || !ask.Price.Equal(sdk.NewDec(101)) {
+		t.Fatalf("unexpected best ask %+v", ask)
+	}
+
+	// partial fill keeps time priority, stale update from the snapshot overlap is skipped
+	partial := testOrder("0x01", exchangetypes.OrderType_BUY, 100, 1, 10)
+	partial.Fillable = sdk.MustNewDecFromStr("0.5")
+	partial.UpdatedAt = 20
+
+	stale := testOrder("0x03", exchangetypes.OrderType_BUY, 100, 3, 12)
+	stale.Fillable = sdk.NewDec(1)
+	stale.UpdatedAt = 5
+
+	for _, u := range []*OrderUpdate{
+		{Order: partial, Timestamp: 20},
+		{Order: stale, Timestamp: 20},
+		{Order: testOrder("0x05", exchangetypes.OrderType_SELL, 101, 5, 14), Removed: true, Timestamp: 21},
+		{Order: testOrder("0x06", exchangetypes.OrderType_SELL, 103, 6, 22), Timestamp: 22},
+	} {
+		if err := book.Apply(u); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bids, asks := book.Depth(0)
+	if len(bids) != 2 || !bids[0].Quantity.Equal(sdk.MustNewDecFromStr("3.5")) || bids[0].Orders[0].Hash != "0x01" {
+		t.Fatalf("unexpected bids %+v", bids)
+	} else if len(asks) != 2 || !asks[0].Price.Equal(sdk.NewDec(102)) || !asks[1].Price.Equal(sdk.NewDec(103)) {
+		t.Fatalf("unexpected asks %+v", asks)
+	}
+
+	if bids, asks := book.Depth(1); len(bids) != 1 || len(asks) != 1 {
+		t.Fatalf("expected one level of each side, got %d and %d", len(bids), len(asks))
+	}
+
+	select {
+	case <-book.Changes():
+	default:
+		t.Fatal("expected change notification")
+	}
+
+	err := book.Apply(&OrderUpdate{Order: testOrder("0x07", exchangetypes.OrderType_BUY, 98, 1, 19), Timestamp: 19})
+	if !errors.Is(err, ErrOutOfOrder) || book.Synced() {
+		t.Fatalf("expected out of order update to unsync the book, got %v", err)
+	}
+
+	book.Reset(testSnapshot())
+	missing := testOrder("0x08", exchangetypes.OrderType_BUY, 98, 1, 11)
+	missing.UpdatedAt = 30
+	if err := book.Apply(&OrderUpdate{Order: missing, Timestamp: 30}); !errors.Is(err, ErrGap) || book.Synced() {
+		t.Fatalf("expected update of an order missing in the snapshot to unsync the book, got %v", err)
+	}
+}
+
+func TestDerivativeOrderbook(t *testing.T) {
+	book := NewBook("market")
+	book.Reset(testSnapshot())
+
+	it := book.DerivativeOrderbook(true)
+	defer it.Close()
+
+	level := it.Peek(sdk.Context{})
+	if !level.Price.Equal(sdk.NewDec(100)) || !level.Quantity.Equal(sdk.NewDec(4)) {
+		t.Fatalf("unexpected level %+v", level)
+	}
+
+	it.Fill(sdk.NewDec(2))
+	it.Fill(sdk.NewDec(2))
+	it.Fill(sdk.NewDec(1))
+
+	if level := it.Peek(sdk.Context{}); !level.Price.Equal(sdk.NewDec(99)) || !level.Quantity.Equal(sdk.NewDec(1)) {
+		t.Fatalf("unexpected level %+v", level)
+	} else if !it.GetTotalQuantityFilled().Equal(sdk.NewDec(5)) || !it.GetNotional().Equal(sdk.NewDec(499)) {
+		t.Fatalf("unexpected filled %s for notional %s", it.GetTotalQuantityFilled(), it.GetNotional())
+	}
+
+	fills := it.GetRestingOrderbookFills()
+	if len(fills.Orders) != 3 || !fills.FillQuantities[0].Equal(sdk.NewDec(1)) || !fills.FillQuantities[1].Equal(sdk.NewDec(3)) {
+		t.Fatalf("unexpected fills %+v", fills.FillQuantities)
+	}
+
+	if bid, _ := book.BestBid(); !bid.Quantity.Equal(sdk.NewDec(4)) {
+		t.Fatal("expected fills to leave the book untouched")
+	}
+}
+
+type testSource struct {
+	mux       sync.Mutex
+	snapshots int
+	updatesC  chan *OrderUpdate
+}
+
+func (s *testSource) Name() string {
+	return "test_orders"
+}
+
+func (s *testSource) Snapshot(context.Context) ([]*Order, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.snapshots++
+	return testSnapshot(), nil
+}
+
+func (s *testSource) Stream(ctx context.Context) (func() (*OrderUpdate, error), error) {
+	return func() (*OrderUpdate, error) {
+		select {
+		case <-ctx.Done():
+			return nil, io.EOF
+		case u := <-s.updatesC:
+			return u, nil
+		}
+	}, nil
+}
+
+type reconnectCounter struct {
+	chainclient.NopObserver
+	reconnects chan string
+}
+
+func (o *reconnectCounter) ObserveStreamReconnect(stream string) {
+	o.reconnects <- stream
+}
+
+func TestReplicaResync(t *testing.T) {
+	source := &testSource{
+		updatesC: make(chan *OrderUpdate),
+	}
+	observer := &reconnectCounter{
+		reconnects: make(chan string, 1),
+	}
+
+	r, err := newReplica(source, "market", OptionObserver(observer), OptionResyncDelay(time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFn()
+
+	doneC := make(chan error, 1)
+	go func() {
+		doneC <- r.Run(ctx)
+	}()
+
+	source.updatesC <- &OrderUpdate{Order: testOrder("0x06", exchangetypes.OrderType_SELL, 103, 6, 22), Timestamp: 22}
+	source.updatesC <- &OrderUpdate{Order: testOrder("0x07", exchangetypes.OrderType_SELL, 104, 1, 1), Timestamp: 23}
+
+	if stream := <-observer.reconnects; stream != "test_orders" {
+		t.Fatalf("unexpected reconnect of %s", stream)
+	}
+
+	cancelFn()
+	if err := <-doneC; !errors.Is(err, context.Canceled) {
+		t.Fatalf("unexpected Run result %v", err)
+	} else if source.snapshots < 2 {
+		t.Fatalf("expected resync after a gap, got %d snapshots", source.snapshots)
+	} else if r.Synced() {
+		t.Fatal("expected the book to be out of sync once Run is done")
+	}
+}
+
+func TestReplicaOverlap(t *testing.T) {
+	source := &testSource{
+		updatesC: make(chan *OrderUpdate),
+	}
+
+	r, err := newReplica(source, "market", OptionResyncDelay(time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFn()
+
+	doneC := make(chan error, 1)
+	go func() {
+		doneC <- r.Run(ctx)
+	}()
+
+	// created and canceled before the snapshot, so missing in it
+	canceled := testOrder("0x09", exchangetypes.OrderType_BUY, 98, 1, 12)
+	source.updatesC <- &OrderUpdate{Order: canceled, Timestamp: 12}
+	canceled.UpdatedAt = 13
+	source.updatesC <- &OrderUpdate{Order: canceled, Removed: true, Timestamp: 13}
+	source.updatesC <- &OrderUpdate{Order: testOrder("0x06", exchangetypes.OrderType_SELL, 103, 6, 22), Timestamp: 22}
+
+	for {
+		if _, ok := r.Order("0x06"); ok {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			t.Fatal("update after the overlap has not been applied")
+		case <-r.Changes():
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	source.mux.Lock()
+	snapshots := source.snapshots
+	source.mux.Unlock()
+
+	if _, ok := r.Order("0x09"); ok {
+		t.Fatal("expected the canceled order to be skipped")
+	} else if snapshots != 1 || !r.Synced() {
+		t.Fatalf("expected the book to stay synced from the first snapshot, got %d snapshots", snapshots)
+	}
+
+	cancelFn()
+	<-doneC
+}
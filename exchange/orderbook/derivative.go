@@ -0,0 +1,134 @@
+package orderbook
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/common"
+
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+)
+
+var _ exchangetypes.DerivativeOrderbook = (*derivativeOrderbook)(nil)
+
+// DerivativeOrderbook returns the resting orders of one side as the orderbook iterator of the chain
+// matching, best price first and in time priority within a level. It works on a copy of the book,
+// fills are recorded in the iterator and don't change the book.
+func (b *Book) DerivativeOrderbook(isBuy bool) exchangetypes.DerivativeOrderbook {
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+
+	levels := *b.side(isBuy)
+	it := &derivativeOrderbook{
+		levels:      make([]Level, 0, len(levels)),
+		orderFilled: sdk.ZeroDec(),
+		notional:    sdk.ZeroDec(),
+		filled:      sdk.ZeroDec(),
+		fills:       new(exchangetypes.DerivativeOrderbookFills),
+		fillIdx:     make(map[string]int),
+	}
+
+	for _, lvl := range levels {
+		it.levels = append(it.levels, lvl.export())
+	}
+
+	return it
+}
+
+type derivativeOrderbook struct {
+	// levels are drained as they are filled
+	levels []Level
+	// orderIdx is the next order to fill in the first level, orderFilled is what is filled of it
+	orderIdx    int
+	orderFilled sdk.Dec
+
+	notional sdk.Dec
+	filled   sdk.Dec
+	fills    *exchangetypes.DerivativeOrderbookFills
+	// fillIdx maps order hashes to their index in fills
+	fillIdx map[string]int
+}
+
+func (it *derivativeOrderbook) GetNotional() sdk.Dec {
+	return it.notional
+}
+
+func (it *derivativeOrderbook) GetTotalQuantityFilled() sdk.Dec {
+	return it.filled
+}
+
+// GetTransientOrderbookFills returns no fills, the replica has no orders posted in the current block.
+func (it *derivativeOrderbook) GetTransientOrderbookFills() *exchangetypes.DerivativeOrderbookFills {
+	return new(exchangetypes.DerivativeOrderbookFills)
+}
+
+func (it *derivativeOrderbook) GetRestingOrderbookFills() *exchangetypes.DerivativeOrderbookFills {
+	return it.fills
+}
+
+// Peek returns the best level with its remaining quantity, or nil if the side is drained.
+func (it *derivativeOrderbook) Peek(sdk.Context) *exchangetypes.PriceLevel {
+	if len(it.levels) == 0 {
+		return nil
+	}
+
+	lvl := it.levels[0]
+	quantity := it.orderFilled.Neg()
+	for _, o := range lvl.Orders[it.orderIdx:] {
+		quantity = quantity.Add(o.Fillable)
+	}
+
+	return &exchangetypes.PriceLevel{
+		Price:    lvl.Price,
+		Quantity: quantity,
+	}
+}
+
+// Fill fills orders in time priority, from the level returned by Peek on.
+func (it *derivativeOrderbook) Fill(fillQuantity sdk.Dec) {
+	for fillQuantity.IsPositive() && len(it.levels) > 0 {
+		lvl := it.levels[0]
+		o := &lvl.Orders[it.orderIdx]
+
+		quantity := sdk.MinDec(fillQuantity, o.Fillable.Sub(it.orderFilled))
+		it.record(o, quantity)
+
+		fillQuantity = fillQuantity.Sub(quantity)
+		it.orderFilled = it.orderFilled.Add(quantity)
+
+		if it.orderFilled.GTE(o.Fillable) {
+			it.orderIdx++
+			it.orderFilled = sdk.ZeroDec()
+		}
+
+		if it.orderIdx == len(lvl.Orders) {
+			it.levels = it.levels[1:]
+			it.orderIdx = 0
+		}
+	}
+}
+
+func (it *derivativeOrderbook) record(o *Order, quantity sdk.Dec) {
+	it.notional = it.notional.Add(quantity.Mul(o.Price))
+	it.filled = it.filled.Add(quantity)
+
+	if idx, ok := it.fillIdx[o.Hash]; ok {
+		it.fills.FillQuantities[idx] = it.fills.FillQuantities[idx].Add(quantity)
+		return
+	}
+
+	it.fillIdx[o.Hash] = len(it.fills.Orders)
+	it.fills.Orders = append(it.fills.Orders, &exchangetypes.DerivativeLimitOrder{
+		OrderInfo: exchangetypes.OrderInfo{
+			SubaccountId: o.SubaccountID,
+			FeeRecipient: o.FeeRecipient,
+			Price:        o.Price,
+			Quantity:     o.Quantity,
+		},
+		OrderType: o.OrderType,
+		Margin:    o.Margin,
+		Fillable:  o.Fillable,
+		OrderHash: common.FromHex(o.Hash),
+	})
+	it.fills.FillQuantities = append(it.fills.FillQuantities, quantity)
+}
+
+func (it *derivativeOrderbook) Close() {}
@@ -0,0 +1,213 @@
+package orderbook
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/xlab/suplog"
+
+	chainclient "github.com/InjectiveLabs/sdk-go/chain/client"
+	derivativeExchangePB "github.com/InjectiveLabs/sdk-go/exchange/derivative_exchange_rpc/pb"
+	spotExchangePB "github.com/InjectiveLabs/sdk-go/exchange/spot_exchange_rpc/pb"
+)
+
+const (
+	// updatesBufferSize is the amount of updates held while the snapshot is fetched
+	updatesBufferSize = 1024
+)
+
+type replicaOptions struct {
+	Observer       chainclient.Observer
+	MinResyncDelay time.Duration
+	MaxResyncDelay time.Duration
+}
+
+func defaultReplicaOptions() *replicaOptions {
+	return &replicaOptions{
+		Observer:       chainclient.NopObserver{},
+		MinResyncDelay: time.Second,
+		MaxResyncDelay: 30 * time.Second,
+	}
+}
+
+type replicaOption func(opts *replicaOptions) error
+
+// OptionObserver sets the observer notified on every resync of the replica.
+func OptionObserver(observer chainclient.Observer) replicaOption {
+	return func(opts *replicaOptions) error {
+		if observer == nil {
+			err := errors.New("observer is nil")
+			return err
+		}
+
+		opts.Observer = observer
+		return nil
+	}
+}
+
+// OptionResyncDelay sets the backoff between resyncs, doubled from min to max while resyncs fail.
+func OptionResyncDelay(min, max time.Duration) replicaOption {
+	return func(opts *replicaOptions) error {
+		if min <= 0 || max < min {
+			err := errors.Errorf("invalid resync delays %s to %s", min, max)
+			return err
+		}
+
+		opts.MinResyncDelay = min
+		opts.MaxResyncDelay = max
+		return nil
+	}
+}
+
+// Replica keeps the Book of a market in sync with the exchange API. The stream carries no sequence numbers,
+// so the book is resynced from a fresh snapshot whenever the stream breaks or an update
+// is out of order or refers to an order missing in the book.
+type Replica struct {
+	*Book
+
+	source orderSource
+	opts   *replicaOptions
+	logger log.Logger
+}
+
+// NewSpotReplica creates a replica of the spot market orderbook, call Run to sync it.
+func NewSpotReplica(
+	client spotExchangePB.InjectiveSpotExchangeRPCClient,
+	marketID string,
+	options ...replicaOption,
+) (*Replica, error) {
+	return newReplica(&spotSource{
+		client:   client,
+		marketID: marketID,
+	}, marketID, options...)
+}
+
+// NewDerivativeReplica creates a replica of the derivative market orderbook, call Run to sync it.
+func NewDerivativeReplica(
+	client derivativeExchangePB.InjectiveDerivativeExchangeRPCClient,
+	marketID string,
+	options ...replicaOption,
+) (*Replica, error) {
+	return newReplica(&derivativeSource{
+		client:   client,
+		marketID: marketID,
+	}, marketID, options...)
+}
+
+func newReplica(source orderSource, marketID string, options ...replicaOption) (*Replica, error) {
+	opts := defaultReplicaOptions()
+	for _, opt := range options {
+		if err := opt(opts); err != nil {
+			err = errors.Wrap(err, "error in a replica option")
+			return nil, err
+		}
+	}
+
+	return &Replica{
+		Book:   NewBook(marketID),
+		source: source,
+		opts:   opts,
+		logger: log.WithFields(log.Fields{
+			"module": "orderbook",
+			"stream": source.Name(),
+			"market": marketID,
+		}),
+	}, nil
+}
+
+// Run syncs the book until ctx is done, resyncing it with backoff on failures.
+// The book isn't synced while Run isn't running.
+func (r *Replica) Run(ctx context.Context) error {
+	defer r.Book.Invalidate()
+
+	delay := r.opts.MinResyncDelay
+	for {
+		synced, err := r.sync(ctx)
+		r.Book.Invalidate()
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		} else if synced {
+			delay = r.opts.MinResyncDelay
+		}
+
+		r.logger.WithError(err).Warningf("orderbook out of sync, resyncing in %s", delay)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		if delay *= 2; delay > r.opts.MaxResyncDelay {
+			delay = r.opts.MaxResyncDelay
+		}
+
+		r.opts.Observer.ObserveStreamReconnect(r.source.Name())
+	}
+}
+
+// sync opens the stream before fetching the snapshot, so no update is missed in between,
+// then applies updates until the stream breaks or the book goes out of sync.
+func (r *Replica) sync(ctx context.Context) (synced bool, err error) {
+	streamCtx, cancelFn := context.WithCancel(ctx)
+	defer cancelFn()
+
+	recv, err := r.source.Stream(streamCtx)
+	if err != nil {
+		return false, err
+	}
+
+	updatesC := make(chan *OrderUpdate, updatesBufferSize)
+	errC := make(chan error, 1)
+	go func() {
+		for {
+			u, err := recv()
+			if err != nil {
+				errC <- err
+				return
+			}
+
+			select {
+			case <-streamCtx.Done():
+				return
+			case updatesC <- u:
+			}
+		}
+	}()
+
+	orders, err := r.source.Snapshot(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	r.Book.Reset(orders)
+	r.logger.Debugf("orderbook synced with %d orders", len(orders))
+
+	for {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case u := <-updatesC:
+			if err := r.Book.Apply(u); err != nil {
+				return true, err
+			}
+		case err := <-errC:
+			// updates received before the stream broke go first
+			for drained := false; !drained; {
+				select {
+				case u := <-updatesC:
+					if err := r.Book.Apply(u); err != nil {
+						return true, err
+					}
+				default:
+					drained = true
+				}
+			}
+
+			err = errors.Wrap(err, "order stream interrupted")
+			return true, err
+		}
+	}
+}
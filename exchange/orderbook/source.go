@@ -0,0 +1,225 @@
+package orderbook
+
+import (
+	"context"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+
+	exchangetypes "github.com/InjectiveLabs/sdk-go/chain/exchange/types"
+	derivativeExchangePB "github.com/InjectiveLabs/sdk-go/exchange/derivative_exchange_rpc/pb"
+	spotExchangePB "github.com/InjectiveLabs/sdk-go/exchange/spot_exchange_rpc/pb"
+)
+
+// orderSource fetches resting orders of a market and streams their updates.
+type orderSource interface {
+	// Name identifies the stream, e.g. in observer events.
+	Name() string
+	Snapshot(ctx context.Context) ([]*Order, error)
+	// Stream opens the update stream, recv blocks until the next update.
+	Stream(ctx context.Context) (recv func() (*OrderUpdate, error), err error)
+}
+
+type spotSource struct {
+	client   spotExchangePB.InjectiveSpotExchangeRPCClient
+	marketID string
+}
+
+func (s *spotSource) Name() string {
+	return "spot_orders"
+}
+
+func (s *spotSource) Snapshot(ctx context.Context) ([]*Order, error) {
+	res, err := s.client.Orders(ctx, &spotExchangePB.OrdersRequest{
+		MarketId: s.marketID,
+	})
+	if err != nil {
+		err = errors.Wrap(err, "failed to fetch spot orders")
+		return nil, err
+	}
+
+	orders := make([]*Order, 0, len(res.Orders))
+	for _, o := range res.Orders {
+		order, err := spotOrder(o)
+		if err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
+
+func (s *spotSource) Stream(ctx context.Context) (func() (*OrderUpdate, error), error) {
+	stream, err := s.client.StreamOrders(ctx, &spotExchangePB.StreamOrdersRequest{
+		MarketId: s.marketID,
+	})
+	if err != nil {
+		err = errors.Wrap(err, "failed to stream spot orders")
+		return nil, err
+	}
+
+	return func() (*OrderUpdate, error) {
+		res, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+
+		order, err := spotOrder(res.Order)
+		if err != nil {
+			return nil, err
+		}
+
+		return orderUpdate(order, res.Order.State, res.OperationType, res.Timestamp), nil
+	}, nil
+}
+
+func spotOrder(o *spotExchangePB.SpotLimitOrder) (*Order, error) {
+	if o == nil {
+		err := errors.New("order is missing")
+		return nil, err
+	}
+
+	order := &Order{
+		Hash:         o.OrderHash,
+		MarketID:     o.MarketId,
+		SubaccountID: o.SubaccountId,
+		FeeRecipient: o.FeeRecipient,
+		Margin:       sdk.ZeroDec(),
+		CreatedAt:    o.CreatedAt,
+		UpdatedAt:    o.UpdatedAt,
+	}
+
+	if err := parseOrder(order, o.OrderType, o.Price, o.Quantity, o.UnfilledQuantity); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+type derivativeSource struct {
+	client   derivativeExchangePB.InjectiveDerivativeExchangeRPCClient
+	marketID string
+}
+
+func (s *derivativeSource) Name() string {
+	return "derivative_orders"
+}
+
+func (s *derivativeSource) Snapshot(ctx context.Context) ([]*Order, error) {
+	res, err := s.client.Orders(ctx, &derivativeExchangePB.OrdersRequest{
+		MarketId: s.marketID,
+	})
+	if err != nil {
+		err = errors.Wrap(err, "failed to fetch derivative orders")
+		return nil, err
+	}
+
+	orders := make([]*Order, 0, len(res.Orders))
+	for _, o := range res.Orders {
+		order, err := derivativeOrder(o)
+		if err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
+
+func (s *derivativeSource) Stream(ctx context.Context) (func() (*OrderUpdate, error), error) {
+	stream, err := s.client.StreamOrders(ctx, &derivativeExchangePB.StreamOrdersRequest{
+		MarketId: s.marketID,
+	})
+	if err != nil {
+		err = errors.Wrap(err, "failed to stream derivative orders")
+		return nil, err
+	}
+
+	return func() (*OrderUpdate, error) {
+		res, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+
+		order, err := derivativeOrder(res.Order)
+		if err != nil {
+			return nil, err
+		}
+
+		return orderUpdate(order, res.Order.State, res.OperationType, res.Timestamp), nil
+	}, nil
+}
+
+func derivativeOrder(o *derivativeExchangePB.DerivativeLimitOrder) (*Order, error) {
+	if o == nil {
+		err := errors.New("order is missing")
+		return nil, err
+	}
+
+	order := &Order{
+		Hash:         o.OrderHash,
+		MarketID:     o.MarketId,
+		SubaccountID: o.SubaccountId,
+		FeeRecipient: o.FeeRecipient,
+		Margin:       sdk.ZeroDec(),
+		CreatedAt:    o.CreatedAt,
+		UpdatedAt:    o.UpdatedAt,
+	}
+
+	if err := parseOrder(order, o.OrderType, o.Price, o.Quantity, o.UnfilledQuantity); err != nil {
+		return nil, err
+	}
+
+	if !o.IsReduceOnly && len(o.Margin) > 0 {
+		margin, err := sdk.NewDecFromStr(o.Margin)
+		if err != nil {
+			err = errors.Wrapf(err, "invalid margin of order %s", o.OrderHash)
+			return nil, err
+		}
+
+		order.Margin = margin
+	}
+
+	return order, nil
+}
+
+func parseOrder(order *Order, orderType, price, quantity, unfilledQuantity string) error {
+	typ, ok := exchangetypes.OrderType_value[strings.ToUpper(orderType)]
+	if !ok {
+		err := errors.Errorf("unknown type %s of order %s", orderType, order.Hash)
+		return err
+	}
+	order.OrderType = exchangetypes.OrderType(typ)
+
+	for _, v := range []struct {
+		name  string
+		value string
+		dst   *sdk.Dec
+	}{
+		{"price", price, &order.Price},
+		{"quantity", quantity, &order.Quantity},
+		{"unfilled quantity", unfilledQuantity, &order.Fillable},
+	} {
+		dec, err := sdk.NewDecFromStr(v.value)
+		if err != nil {
+			err = errors.Wrapf(err, "invalid %s of order %s", v.name, order.Hash)
+			return err
+		}
+
+		*v.dst = dec
+	}
+
+	return nil
+}
+
+func orderUpdate(order *Order, state, operationType string, timestamp int64) *OrderUpdate {
+	return &OrderUpdate{
+		Order:     order,
+		Removed:   operationType == "delete" || state == "filled" || state == "canceled",
+		Timestamp: timestamp,
+	}
+}
@@ -113,6 +113,17 @@ func SignTextValidator(validatorData ValidatorData) (hexutil.Bytes, string) {
 
 // ComputeTypedDataHash computes keccak hash of typed data for signing.
 func ComputeTypedDataHash(typedData TypedData) ([]byte, error) {
+	rawData, err := ComputeTypedDataSignBytes(typedData)
+	if err != nil {
+		return nil, err
+	}
+
+	return crypto.Keccak256(rawData), nil
+}
+
+// ComputeTypedDataSignBytes encodes typed data as "\x19\x01" ‖ domainSeparator ‖ hashStruct(message),
+// the keccak hash of these bytes is what gets signed. Useful for signers that hash the message themselves.
+func ComputeTypedDataSignBytes(typedData TypedData) ([]byte, error) {
 	domainSeparator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
 	if err != nil {
 		err = errors.Wrap(err, "failed to pack and hash typedData EIP712Domain")
@@ -126,7 +137,7 @@ func ComputeTypedDataHash(typedData TypedData) ([]byte, error) {
 	}
 
 	rawData := []byte(fmt.Sprintf("\x19\x01%s%s", string(domainSeparator), string(typedDataHash)))
-	return crypto.Keccak256(rawData), nil
+	return rawData, nil
 }
 
 // cliqueHeaderHashAndRlp returns the hash which is used as input for the proof-of-authority